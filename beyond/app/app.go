@@ -3,8 +3,13 @@ package app
 import (
 	"encoding/json"
 	"os"
+
 	"github.com/vincepg13/bp-sdk/beyond/types"
+	"github.com/vincepg13/bp-sdk/beyond/x/beacon"
+	dev "github.com/vincepg13/bp-sdk/beyond/x/device"
 	mob "github.com/vincepg13/bp-sdk/beyond/x/mobility"
+	rev "github.com/vincepg13/bp-sdk/beyond/x/revocation"
+	dc "github.com/vincepg13/bp-sdk/deepcover-client"
 
 	bam "github.com/cosmos/cosmos-sdk/baseapp"
 	"github.com/cosmos/cosmos-sdk/codec"
@@ -38,16 +43,22 @@ type BeyondApp struct {
 	cdc *codec.Codec
 
 	// keys to access the multistore
-	keyMain    *sdk.KVStoreKey
-	keyAccount *sdk.KVStoreKey
-	keyIBC     *sdk.KVStoreKey
-	keyOrder   *sdk.KVStoreKey
+	keyMain       *sdk.KVStoreKey
+	keyAccount    *sdk.KVStoreKey
+	keyIBC        *sdk.KVStoreKey
+	keyOrder      *sdk.KVStoreKey
+	keyDevice     *sdk.KVStoreKey
+	keyRevocation *sdk.KVStoreKey
+	keyBeacon     *sdk.KVStoreKey
 
 	// manage getting and setting accounts
 	accountKeeper       auth.AccountKeeper
 	feeCollectionKeeper auth.FeeCollectionKeeper
 	bankKeeper          bank.Keeper
 	orderKeeper         mob.Keeper
+	deviceKeeper        dev.Keeper
+	revocationKeeper    rev.Keeper
+	beaconKeeper        beacon.Keeper
 	ibcMapper           ibc.Mapper
 }
 
@@ -62,12 +73,15 @@ func NewBeyondApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam.Base
 
 	// create your application type
 	var app = &BeyondApp{
-		cdc:        cdc,
-		BaseApp:    bam.NewBaseApp(appName, logger, db, auth.DefaultTxDecoder(cdc), baseAppOptions...),
-		keyMain:    sdk.NewKVStoreKey("main"),
-		keyAccount: sdk.NewKVStoreKey("acc"),
-		keyIBC:     sdk.NewKVStoreKey("ibc"),
-		keyOrder:   sdk.NewKVStoreKey("order"),
+		cdc:           cdc,
+		BaseApp:       bam.NewBaseApp(appName, logger, db, auth.DefaultTxDecoder(cdc), baseAppOptions...),
+		keyMain:       sdk.NewKVStoreKey("main"),
+		keyAccount:    sdk.NewKVStoreKey("acc"),
+		keyIBC:        sdk.NewKVStoreKey("ibc"),
+		keyOrder:      sdk.NewKVStoreKey("order"),
+		keyDevice:     sdk.NewKVStoreKey("device"),
+		keyRevocation: sdk.NewKVStoreKey("revocation"),
+		keyBeacon:     sdk.NewKVStoreKey("beacon"),
 	}
 
 	// define and attach the mappers and keepers
@@ -80,22 +94,35 @@ func NewBeyondApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam.Base
 	)
 	app.bankKeeper = bank.NewBaseKeeper(app.accountKeeper)
 	app.ibcMapper = ibc.NewMapper(app.cdc, app.keyIBC, app.RegisterCodespace(ibc.DefaultCodespace))
-	app.orderKeeper = mob.NewKeeper(app.keyOrder, app.bankKeeper, app.RegisterCodespace(mob.DefaultCodespace))
+	app.revocationKeeper = rev.NewKeeper(app.keyRevocation, app.accountKeeper, app.RegisterCodespace(rev.DefaultCodespace))
+	app.deviceKeeper = dev.NewKeeper(app.keyDevice, app.accountKeeper, app.RegisterCodespace(dev.DefaultCodespace))
+	app.orderKeeper = mob.NewKeeper(app.keyOrder, app.bankKeeper, app.revocationKeeper, app.deviceKeeper, app.RegisterCodespace(mob.DefaultCodespace))
+	app.beaconKeeper = beacon.NewKeeper(app.keyBeacon, app.deviceKeeper, app.RegisterCodespace(beacon.DefaultCodespace))
 
 	// register message routes
 	app.Router().
 		AddRoute("bank", bank.NewHandler(app.bankKeeper)).
 		AddRoute("ibc", ibc.NewHandler(app.ibcMapper, app.bankKeeper)).
-		AddRoute("order", mob.NewHandler(app.orderKeeper))
+		AddRoute("order", mob.NewHandler(app.orderKeeper)).
+		AddRoute("device", dev.NewHandler(app.deviceKeeper)).
+		AddRoute("revocation", rev.NewHandler(app.revocationKeeper)).
+		AddRoute("beacon", beacon.NewHandler(app.beaconKeeper))
+
+	// register query routes
+	app.QueryRouter().
+		AddRoute("revocation", rev.NewQuerier(app.revocationKeeper)).
+		AddRoute("device", dev.NewQuerier(app.deviceKeeper)).
+		AddRoute("beacon", beacon.NewQuerier(app.beaconKeeper))
 
 	// perform initialization logic
 	app.SetInitChainer(app.initChainer)
 	app.SetBeginBlocker(app.BeginBlocker)
 	app.SetEndBlocker(app.EndBlocker)
-	app.SetAnteHandler(auth.NewAnteHandler(app.accountKeeper, app.feeCollectionKeeper))
+	app.SetAnteHandler(rev.NewAnteHandler(app.revocationKeeper,
+		NewAnteHandler(app.accountKeeper, app.feeCollectionKeeper)))
 
 	// mount the multistore and load the latest state
-	app.MountStoresIAVL(app.keyMain, app.keyAccount, app.keyIBC, app.keyOrder)
+	app.MountStoresIAVL(app.keyMain, app.keyAccount, app.keyIBC, app.keyOrder, app.keyDevice, app.keyRevocation, app.keyBeacon)
 	err := app.LoadLatestVersion(app.keyMain)
 	if err != nil {
 		cmn.Exit(err.Error())
@@ -117,9 +144,13 @@ func MakeCodec() *codec.Codec {
 	ibc.RegisterCodec(cdc)
 	auth.RegisterCodec(cdc)
 	mob.RegisterCodec(cdc)
+	dev.RegisterCodec(cdc)
+	rev.RegisterCodec(cdc)
+	beacon.RegisterCodec(cdc)
 
 	// register custom type
 	cdc.RegisterConcrete(&types.AppAccount{}, "beyond/Account", nil)
+	cdc.RegisterConcrete(types.PubKeyDeepCover{}, types.PubKeyDeepCoverAminoRoute, nil)
 
 	cdc.Seal()
 
@@ -128,14 +159,21 @@ func MakeCodec() *codec.Codec {
 
 // BeginBlocker reflects logic to run before any TXs application are processed
 // by the application.
-func (app *BeyondApp) BeginBlocker(_ sdk.Context, _ abci.RequestBeginBlock) abci.ResponseBeginBlock {
+func (app *BeyondApp) BeginBlocker(ctx sdk.Context, req abci.RequestBeginBlock) abci.ResponseBeginBlock {
+	dev.BeginBlocker(ctx, req, app.deviceKeeper)
+	beacon.BeginBlocker(ctx, req, app.beaconKeeper)
+
 	return abci.ResponseBeginBlock{}
 }
 
 // EndBlocker reflects logic to run after all TXs are processed by the
 // application.
-func (app *BeyondApp) EndBlocker(_ sdk.Context, _ abci.RequestEndBlock) abci.ResponseEndBlock {
-	return abci.ResponseEndBlock{}
+func (app *BeyondApp) EndBlocker(ctx sdk.Context, _ abci.RequestEndBlock) abci.ResponseEndBlock {
+	tags := beacon.EndBlocker(ctx, app.beaconKeeper)
+
+	return abci.ResponseEndBlock{
+		Tags: tags,
+	}
 }
 
 // initChainer implements the custom application logic that the BaseApp will
@@ -151,6 +189,9 @@ func (app *BeyondApp) initChainer(ctx sdk.Context, req abci.RequestInitChain) ab
 		// TODO: https://github.com/cosmos/cosmos-sdk/issues/468
 		panic(err)
 	}
+	if err := genesisState.Validate(); err != nil {
+		panic(err)
+	}
 
 	for _, gacc := range genesisState.Accounts {
 		acc, err := gacc.ToAppAccount()
@@ -163,6 +204,21 @@ func (app *BeyondApp) initChainer(ctx sdk.Context, req abci.RequestInitChain) ab
 		app.accountKeeper.SetAccount(ctx, acc)
 	}
 
+	for _, authority := range genesisState.Authorities {
+		if _, err := dc.ParseAuthorityKey(authority.PubKey); err != nil {
+			panic(err)
+		}
+		app.deviceKeeper.SetAuthority(ctx, authority)
+	}
+
+	for _, revoker := range genesisState.Revokers {
+		app.revocationKeeper.SetRevoker(ctx, revoker)
+	}
+
+	for _, revocation := range genesisState.Revocations {
+		app.revocationKeeper.AddRevocation(ctx, revocation)
+	}
+
 	return abci.ResponseInitChain{}
 }
 
@@ -178,6 +234,9 @@ func (app *BeyondApp) ExportAppStateAndValidators() (appState json.RawMessage, v
 			Address: acc.GetAddress(),
 			Coins:   acc.GetCoins(),
 		}
+		if appAcc, ok := acc.(*types.AppAccount); ok {
+			account = types.NewGenesisAccount(appAcc)
+		}
 
 		accounts = append(accounts, account)
 		return false
@@ -185,7 +244,12 @@ func (app *BeyondApp) ExportAppStateAndValidators() (appState json.RawMessage, v
 
 	app.accountKeeper.IterateAccounts(ctx, appendAccountsFn)
 
-	genState := types.GenesisState{Accounts: accounts}
+	genState := types.GenesisState{
+		Accounts:    accounts,
+		Authorities: app.deviceKeeper.GetAuthorities(ctx),
+		Revokers:    app.revocationKeeper.GetRevokers(ctx),
+		Revocations: app.revocationKeeper.GetRevocations(ctx),
+	}
 	appState, err = codec.MarshalJSONIndent(app.cdc, genState)
 	if err != nil {
 		return nil, nil, err
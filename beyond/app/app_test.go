@@ -1,14 +1,26 @@
 package app
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"testing"
 
-	"beyond/types"
+	"github.com/vincepg13/bp-sdk/beyond/client/hsm"
+	"github.com/vincepg13/bp-sdk/beyond/types"
+	"github.com/vincepg13/bp-sdk/beyond/x/beacon"
+	dev "github.com/vincepg13/bp-sdk/beyond/x/device"
+	mob "github.com/vincepg13/bp-sdk/beyond/x/mobility"
+	dc "github.com/vincepg13/bp-sdk/deepcover-client"
 
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/auth"
+	authtxb "github.com/cosmos/cosmos-sdk/x/auth/client/txbuilder"
 	"github.com/stretchr/testify/require"
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/crypto/ed25519"
@@ -56,7 +68,7 @@ func TestGenesis(t *testing.T) {
 	require.Nil(t, err)
 
 	// create a new test AppAccount with the given auth.BaseAccount
-	appAcct := types.NewAppAccount("foobar", baseAcct)
+	appAcct := types.NewAppAccount("foobar", "00-05-9A-3C-7A-00", "2", types.HsmInfo{}, baseAcct)
 	genState, err := setGenesis(baseApp, appAcct)
 	require.Nil(t, err)
 
@@ -80,3 +92,470 @@ func TestGenesis(t *testing.T) {
 	res = baseApp.accountKeeper.GetAccount(ctx, baseAcct.Address)
 	require.Equal(t, appAcct, res)
 }
+
+func TestGenesisValidation(t *testing.T) {
+	baseApp := NewBeyondApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	addr := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	coins := sdk.Coins{sdk.NewInt64Coin("byndcoin", 100)}
+	genesisState := types.GenesisState{Accounts: []*types.GenesisAccount{
+		{Name: "station", MacAddress: "60-67-20-C0-E4-59", Price: "2", Address: addr, Coins: coins},
+		{Name: "car", MacAddress: "00-05-9A-3C-7A-00", Price: "1", Address: addr, Coins: coins},
+	}}
+	stateBytes, err := codec.MarshalJSONIndent(baseApp.cdc, genesisState)
+	require.Nil(t, err)
+
+	// an address can not have two genesis accounts
+	require.Panics(t, func() {
+		baseApp.InitChain(abci.RequestInitChain{
+			Validators: []abci.Validator{}, AppStateBytes: stateBytes,
+		})
+	})
+}
+
+func TestExportRevocations(t *testing.T) {
+	logger := log.NewNopLogger()
+	baseApp := NewBeyondApp(logger, dbm.NewMemDB())
+
+	revoker := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	romID, _ := hex.DecodeString("4c123456789a0053")
+	genesisState := types.GenesisState{
+		Revokers: []sdk.AccAddress{revoker},
+		Revocations: []types.Revocation{
+			{RomID: romID, Reason: "stolen", Revoker: revoker, Height: 12},
+			{PubKey: make([]byte, 64), Reason: "cloned", Revoker: revoker, Height: 40},
+		},
+	}
+	stateBytes, err := codec.MarshalJSONIndent(baseApp.cdc, genesisState)
+	require.Nil(t, err)
+	baseApp.InitChain(abci.RequestInitChain{
+		Validators: []abci.Validator{}, AppStateBytes: stateBytes,
+	})
+	baseApp.Commit()
+
+	// an exported chain restarts with the same revocation list
+	appState, _, err := baseApp.ExportAppStateAndValidators()
+	require.Nil(t, err)
+	var exported types.GenesisState
+	require.Nil(t, baseApp.cdc.UnmarshalJSON(appState, &exported))
+	require.Equal(t, genesisState.Revocations, exported.Revocations)
+
+	restarted := NewBeyondApp(logger, dbm.NewMemDB())
+	restarted.InitChain(abci.RequestInitChain{
+		Validators: []abci.Validator{}, AppStateBytes: appState,
+	})
+	restarted.Commit()
+	ctx := restarted.BaseApp.NewContext(true, abci.Header{})
+	require.True(t, restarted.revocationKeeper.IsRevoked(ctx, romID, nil))
+	require.True(t, restarted.revocationKeeper.IsRevoked(ctx, nil, make([]byte, 64)))
+	require.Equal(t, genesisState.Revocations, restarted.revocationKeeper.GetRevocations(ctx))
+}
+
+func pad32(b []byte) []byte {
+	out := make([]byte, 32)
+	copy(out[32-len(b):], b)
+	return out
+}
+
+func signedCertificate(t *testing.T, authority *ecdsa.PrivateKey) (dc.DeviceCertificate, *ecdsa.PrivateKey) {
+	device, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+
+	romID, _ := hex.DecodeString("4c123456789a0053")
+	cert := dc.DeviceCertificate{
+		PubKeyA: append(pad32(device.X.Bytes()), pad32(device.Y.Bytes())...),
+		RomID:   romID,
+		ManID:   []byte{0, 0},
+	}
+	body, err := cert.Body()
+	require.Nil(t, err)
+	digest := sha256.Sum256(body)
+	r, s, err := ecdsa.Sign(rand.Reader, authority, digest[:])
+	require.Nil(t, err)
+	cert.R, cert.S = pad32(r.Bytes()), pad32(s.Bytes())
+	return cert, device
+}
+
+// onboardDevice returns the onboardDevice msg of sender, signed by the
+// device key with the given page
+func onboardDevice(t *testing.T, ctx sdk.Context, sender sdk.AccAddress, cert dc.DeviceCertificate, deviceKey *ecdsa.PrivateKey, page int, pageData []byte) dev.MsgOnboardDevice {
+	msg := dev.NewMsgOnboardDevice(sender, cert, page, pageData, nil)
+	layout := types.HsmInfo{RomID: cert.RomID, ManID: cert.ManID, Page: page, PageData: pageData}.DigestLayout()
+	digest, err := dc.CalcucateMessageDigest(dev.OnboardChallenge(ctx.ChainID(), sender, msg.DeviceAddress()), layout)
+	require.Nil(t, err)
+	r, s, err := ecdsa.Sign(rand.Reader, deviceKey, digest)
+	require.Nil(t, err)
+	msg.Signature, err = dc.NormalizeSignature(dc.RawSignature(r, s))
+	require.Nil(t, err)
+	return msg
+}
+
+func setAuthorityGenesis(t *testing.T, baseApp *BeyondApp) *ecdsa.PrivateKey {
+	authority, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	authorityKey := hex.EncodeToString(append(pad32(authority.X.Bytes()), pad32(authority.Y.Bytes())...))
+
+	genesisState := types.GenesisState{
+		Authorities: []types.AuthorityKey{{Name: "beyond", PubKey: authorityKey}},
+	}
+	stateBytes, err := codec.MarshalJSONIndent(baseApp.cdc, genesisState)
+	require.Nil(t, err)
+	baseApp.InitChain(abci.RequestInitChain{
+		Validators: []abci.Validator{}, AppStateBytes: stateBytes,
+	})
+	baseApp.Commit()
+
+	return authority
+}
+
+func TestOnboardDevice(t *testing.T) {
+	logger := log.NewNopLogger()
+	baseApp := NewBeyondApp(logger, dbm.NewMemDB())
+	authority := setAuthorityGenesis(t, baseApp)
+
+	sender := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	handler := dev.NewHandler(baseApp.deviceKeeper)
+	ctx := baseApp.BaseApp.NewContext(false, abci.Header{})
+
+	// certificate signed by an unknown authority is rejected
+	rogue, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	rogueCert, rogueKey := signedCertificate(t, rogue)
+	res := handler(ctx, onboardDevice(t, ctx, sender, rogueCert, rogueKey, 0, nil))
+	require.Equal(t, sdk.ToABCICode(dev.DefaultCodespace, dev.CodeInvalidCertificate), res.Code)
+
+	cert, deviceKey := signedCertificate(t, authority)
+	msg := onboardDevice(t, ctx, sender, cert, deviceKey, 0, nil)
+	require.Nil(t, msg.ValidateBasic())
+
+	// the certificate alone does not onboard the device, it must sign the
+	// sender, the page and the chain
+	frontRunner := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	stolen := msg
+	stolen.Sender = frontRunner
+	res = handler(ctx, stolen)
+	require.Equal(t, sdk.ToABCICode(dev.DefaultCodespace, dev.CodeInvalidOnboarding), res.Code)
+	bogusPage := sha256.Sum256([]byte("bogus page"))
+	stolen = msg
+	stolen.Page, stolen.PageData = 3, bogusPage[:]
+	res = handler(ctx, stolen)
+	require.Equal(t, sdk.ToABCICode(dev.DefaultCodespace, dev.CodeInvalidOnboarding), res.Code)
+	clone, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	res = handler(ctx, onboardDevice(t, ctx, frontRunner, cert, clone, 3, bogusPage[:]))
+	require.Equal(t, sdk.ToABCICode(dev.DefaultCodespace, dev.CodeInvalidOnboarding), res.Code)
+	res = handler(ctx.WithChainID("other-chain"), msg)
+	require.Equal(t, sdk.ToABCICode(dev.DefaultCodespace, dev.CodeInvalidOnboarding), res.Code)
+	require.Nil(t, baseApp.accountKeeper.GetAccount(ctx, msg.DeviceAddress()))
+
+	// valid certificate creates a verified device account
+	res = handler(ctx, msg)
+	require.True(t, res.IsOK(), res.Log)
+
+	acc, ok := baseApp.accountKeeper.GetAccount(ctx, msg.DeviceAddress()).(*types.AppAccount)
+	require.True(t, ok)
+	require.True(t, acc.HsmInfo.Verified)
+	require.Equal(t, "beyond", acc.HsmInfo.Authority)
+	require.Equal(t, msg.DeviceAddress(), baseApp.deviceKeeper.GetDeviceAddress(ctx, msg.RomID))
+
+	// the same device cannot be onboarded twice
+	res = handler(ctx, msg)
+	require.Equal(t, sdk.ToABCICode(dev.DefaultCodespace, dev.CodeDeviceAlreadyOnboarded), res.Code)
+}
+
+func TestAttestDevice(t *testing.T) {
+	logger := log.NewNopLogger()
+	baseApp := NewBeyondApp(logger, dbm.NewMemDB())
+	authority := setAuthorityGenesis(t, baseApp)
+
+	sender := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	handler := dev.NewHandler(baseApp.deviceKeeper)
+	ctx := baseApp.BaseApp.NewContext(false, abci.Header{Height: 10})
+
+	// the device signs with page 5 instead of the erased page 0
+	cert, deviceKey := signedCertificate(t, authority)
+	pageData := sha256.Sum256([]byte("page 5"))
+	onboard := onboardDevice(t, ctx, sender, cert, deviceKey, 5, pageData[:])
+	require.True(t, handler(ctx, onboard).IsOK())
+	device := onboard.DeviceAddress()
+
+	blockHash := sha256.Sum256([]byte("block 9"))
+	dev.BeginBlocker(ctx, abci.RequestBeginBlock{
+		Header: abci.Header{Height: 10, LastBlockId: abci.BlockID{Hash: blockHash[:]}},
+	}, baseApp.deviceKeeper)
+
+	layout := dc.DigestLayout{RomID: cert.RomID, ManID: cert.ManID, Page: 5, PageData: pageData[:]}
+	signLayout := func(key *ecdsa.PrivateKey, layout dc.DigestLayout) []byte {
+		digest, err := dc.CalcucateMessageDigest(dev.Challenge(blockHash[:], device), layout)
+		require.Nil(t, err)
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+		require.Nil(t, err)
+		sig, err := dc.NormalizeSignature(dc.RawSignature(r, s))
+		require.Nil(t, err)
+		return sig
+	}
+	sign := func(key *ecdsa.PrivateKey) []byte { return signLayout(key, layout) }
+
+	// challenge outside the window is rejected
+	res := handler(ctx, dev.NewMsgAttest(sender, device, 8, sign(deviceKey)))
+	require.Equal(t, sdk.ToABCICode(dev.DefaultCodespace, dev.CodeStaleChallenge), res.Code)
+
+	// signature by another key is rejected
+	clone, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	res = handler(ctx, dev.NewMsgAttest(sender, device, 9, sign(clone)))
+	require.Equal(t, sdk.ToABCICode(dev.DefaultCodespace, dev.CodeInvalidAttestation), res.Code)
+
+	// signature over the default page 0 layout is rejected
+	defaultLayout := dc.DefaultDigestLayout(cert.RomID, cert.ManID)
+	res = handler(ctx, dev.NewMsgAttest(sender, device, 9, signLayout(deviceKey, defaultLayout)))
+	require.Equal(t, sdk.ToABCICode(dev.DefaultCodespace, dev.CodeInvalidAttestation), res.Code)
+
+	res = handler(ctx, dev.NewMsgAttest(sender, device, 9, sign(deviceKey)))
+	require.True(t, res.IsOK(), res.Log)
+	require.True(t, baseApp.deviceKeeper.HasFreshAttestation(ctx, device, 1))
+
+	// attestation expires once the chain moves on
+	ctx = ctx.WithBlockHeight(20)
+	require.False(t, baseApp.deviceKeeper.HasFreshAttestation(ctx, device, 10))
+	require.True(t, baseApp.deviceKeeper.HasFreshAttestation(ctx, device, 11))
+}
+
+func TestRandomnessBeacon(t *testing.T) {
+	logger := log.NewNopLogger()
+	baseApp := NewBeyondApp(logger, dbm.NewMemDB())
+	authority := setAuthorityGenesis(t, baseApp)
+
+	sender := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	handler := beacon.NewHandler(baseApp.beaconKeeper)
+	ctx := baseApp.BaseApp.NewContext(false, abci.Header{Height: 5})
+
+	cert, deviceKey := signedCertificate(t, authority)
+	onboard := onboardDevice(t, ctx, sender, cert, deviceKey, 0, nil)
+	require.True(t, dev.NewHandler(baseApp.deviceKeeper)(ctx, onboard).IsOK())
+	device := onboard.DeviceAddress()
+
+	value := sha256.Sum256([]byte("rng output"))
+	commit := beacon.NewMsgCommitRandomness(device, 0, beacon.Commitment(value[:], device))
+	require.Nil(t, commit.ValidateBasic())
+
+	// only devices with a fresh attestation take part
+	res := handler(ctx, commit)
+	require.Equal(t, sdk.ToABCICode(beacon.DefaultCodespace, beacon.CodeNotParticipant), res.Code)
+	res = handler(ctx, beacon.NewMsgCommitRandomness(sender, 0, commit.Commitment))
+	require.Equal(t, sdk.ToABCICode(beacon.DefaultCodespace, beacon.CodeNotParticipant), res.Code)
+
+	baseApp.deviceKeeper.SetAttestation(ctx, device, dev.Attestation{Height: 4, RecordedAt: 5})
+	res = handler(ctx, commit)
+	require.True(t, res.IsOK(), res.Log)
+	res = handler(ctx, commit)
+	require.Equal(t, sdk.ToABCICode(beacon.DefaultCodespace, beacon.CodeAlreadyCommitted), res.Code)
+
+	// reveals are only accepted once the commit phase is over
+	reveal := beacon.NewMsgRevealRandomness(device, 0, value[:])
+	res = handler(ctx, reveal)
+	require.Equal(t, sdk.ToABCICode(beacon.DefaultCodespace, beacon.CodeWrongPhase), res.Code)
+
+	ctx = ctx.WithBlockHeight(15)
+	res = handler(ctx, commit)
+	require.Equal(t, sdk.ToABCICode(beacon.DefaultCodespace, beacon.CodeWrongPhase), res.Code)
+
+	other := sha256.Sum256([]byte("other value"))
+	res = handler(ctx, beacon.NewMsgRevealRandomness(device, 0, other[:]))
+	require.Equal(t, sdk.ToABCICode(beacon.DefaultCodespace, beacon.CodeInvalidReveal), res.Code)
+
+	res = handler(ctx, reveal)
+	require.True(t, res.IsOK(), res.Log)
+
+	// the last block of the epoch computes the beacon
+	ctx = ctx.WithBlockHeight(19)
+	require.Nil(t, beacon.EndBlocker(ctx, baseApp.beaconKeeper))
+	ctx = ctx.WithBlockHeight(20)
+	require.NotNil(t, beacon.EndBlocker(ctx, baseApp.beaconKeeper))
+
+	latest, found := baseApp.beaconKeeper.GetLatestBeacon(ctx)
+	require.True(t, found)
+	require.Equal(t, int64(0), latest.Epoch)
+	require.Equal(t, int64(1), latest.Commitments)
+	require.Equal(t, int64(1), latest.Contributors)
+	require.Empty(t, latest.Withholders)
+	require.Len(t, latest.Value, beacon.ValueSize)
+	require.Nil(t, baseApp.beaconKeeper.GetCommitment(ctx, 0, device))
+}
+
+func TestValidatorRandomness(t *testing.T) {
+	baseApp := NewBeyondApp(log.NewNopLogger(), dbm.NewMemDB())
+	_, err := setGenesis(baseApp)
+	require.Nil(t, err)
+
+	// a validator takes part with the operator account signed by its
+	// consensus key
+	validatorKey, otherKey, unregisteredKey := ed25519.GenPrivKey(), ed25519.GenPrivKey(), ed25519.GenPrivKey()
+	validator := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	other := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	vote := func(key ed25519.PrivKeyEd25519) abci.VoteInfo {
+		return abci.VoteInfo{Validator: abci.Validator{Address: key.PubKey().Address(), Power: 10}, SignedLastBlock: true}
+	}
+	register := func(ctx sdk.Context, key ed25519.PrivKeyEd25519, operator sdk.AccAddress) beacon.MsgRegisterValidator {
+		sig, err := key.Sign(beacon.RegistrationBytes(ctx.ChainID(), operator))
+		require.Nil(t, err)
+		return beacon.NewMsgRegisterValidator(operator, key.PubKey(), sig)
+	}
+	beginBlock := func(height int64, votes ...abci.VoteInfo) sdk.Context {
+		baseApp.BeginBlock(abci.RequestBeginBlock{
+			Header:         abci.Header{Height: height},
+			LastCommitInfo: abci.LastCommitInfo{Votes: votes},
+		})
+		return baseApp.BaseApp.NewContext(false, abci.Header{Height: height})
+	}
+	endBlock := func(height int64) {
+		baseApp.EndBlock(abci.RequestEndBlock{Height: height})
+		baseApp.Commit()
+	}
+	handler := beacon.NewHandler(baseApp.beaconKeeper)
+	value := sha256.Sum256([]byte("validator value"))
+	commit := beacon.NewMsgCommitRandomness(validator, 0, beacon.Commitment(value[:], validator))
+
+	// the first block has no last commit
+	ctx := beginBlock(1)
+	require.False(t, baseApp.beaconKeeper.IsParticipant(ctx, validator))
+
+	// only the consensus key registers its validator, for the chain it signed
+	msg := register(ctx, validatorKey, validator)
+	require.Nil(t, msg.ValidateBasic())
+	stolen := msg
+	stolen.Operator = other
+	res := handler(ctx, stolen)
+	require.Equal(t, sdk.ToABCICode(beacon.DefaultCodespace, beacon.CodeInvalidRegistration), res.Code)
+	res = handler(ctx.WithChainID("other-chain"), msg)
+	require.Equal(t, sdk.ToABCICode(beacon.DefaultCodespace, beacon.CodeInvalidRegistration), res.Code)
+	res = handler(ctx, msg)
+	require.True(t, res.IsOK(), res.Log)
+	res = handler(ctx, register(ctx, otherKey, other))
+	require.True(t, res.IsOK(), res.Log)
+	endBlock(1)
+
+	// the consensus address itself does not take part, neither does a
+	// validator without an operator
+	ctx = beginBlock(2, vote(validatorKey), vote(unregisteredKey))
+	require.True(t, baseApp.beaconKeeper.IsParticipant(ctx, validator))
+	require.False(t, baseApp.beaconKeeper.IsParticipant(ctx, sdk.AccAddress(validatorKey.PubKey().Address())))
+	require.False(t, baseApp.beaconKeeper.IsParticipant(ctx, other))
+	require.Equal(t, []sdk.AccAddress{validator}, baseApp.beaconKeeper.GetValidators(ctx))
+	res = handler(ctx, commit)
+	require.True(t, res.IsOK(), res.Log)
+	res = handler(ctx, beacon.NewMsgCommitRandomness(other, 0, commit.Commitment))
+	require.Equal(t, sdk.ToABCICode(beacon.DefaultCodespace, beacon.CodeNotParticipant), res.Code)
+	endBlock(2)
+
+	// a validator that does not reveal is excluded for the next epochs
+	ctx = beginBlock(3, vote(validatorKey))
+	latest := baseApp.beaconKeeper.ComputeBeacon(ctx.WithBlockHeight(beacon.EpochLength), 0)
+	require.Equal(t, int64(1), latest.Commitments)
+	require.Equal(t, int64(0), latest.Contributors)
+	require.Equal(t, []sdk.AccAddress{validator}, latest.Withholders)
+	commit = beacon.NewMsgCommitRandomness(validator, 1, commit.Commitment)
+	res = handler(ctx.WithBlockHeight(beacon.EpochStart(1)), commit)
+	require.Equal(t, sdk.ToABCICode(beacon.DefaultCodespace, beacon.CodeExcluded), res.Code)
+	epoch := int64(1 + beacon.WithholdingPenalty)
+	commit = beacon.NewMsgCommitRandomness(validator, epoch-1, commit.Commitment)
+	res = handler(ctx.WithBlockHeight(beacon.EpochStart(epoch-1)), commit)
+	require.Equal(t, sdk.ToABCICode(beacon.DefaultCodespace, beacon.CodeExcluded), res.Code)
+	commit = beacon.NewMsgCommitRandomness(validator, epoch, commit.Commitment)
+	res = handler(ctx.WithBlockHeight(beacon.EpochStart(epoch)), commit)
+	require.True(t, res.IsOK(), res.Log)
+	endBlock(3)
+
+	// a validator leaving the set no longer takes part
+	ctx = beginBlock(4, vote(otherKey))
+	require.False(t, baseApp.beaconKeeper.IsParticipant(ctx, validator))
+	require.True(t, baseApp.beaconKeeper.IsParticipant(ctx, other))
+	require.Equal(t, []sdk.AccAddress{other}, baseApp.beaconKeeper.GetValidators(ctx))
+}
+
+func TestHSMSignedTx(t *testing.T) {
+	logger := log.NewNopLogger()
+	baseApp := NewBeyondApp(logger, dbm.NewMemDB())
+
+	// the software backend stands in for the secure element of the car,
+	// provisioned to sign with page 7
+	romID, _ := hex.DecodeString("4c123456789a0053")
+	backend, err := dc.NewSoftwareBackend(nil, romID, []byte{0x12, 0x34})
+	require.Nil(t, err)
+	pageData := sha256.Sum256([]byte("page 7"))
+	require.Nil(t, backend.WritePage(7, pageData[:]))
+	session := dc.NewSession(backend)
+	pubKey, err := session.PubKeyA(context.Background())
+	require.Nil(t, err)
+	layout, err := session.DigestLayout(context.Background(), 7)
+	require.Nil(t, err)
+	key := hsm.Key{
+		Name:     "car",
+		RomID:    layout.RomID,
+		PubKey:   pubKey,
+		ManID:    layout.ManID,
+		Page:     layout.Page,
+		PageData: layout.PageData,
+	}
+
+	coins, err := sdk.ParseCoins("10byndcoin")
+	require.Nil(t, err)
+	baseAcct := auth.NewBaseAccountWithAddress(key.GetAddress())
+	err = baseAcct.SetCoins(coins)
+	require.Nil(t, err)
+	_, err = setGenesis(baseApp, types.NewAppAccount("car", "", "2", types.HsmInfo{}, baseAcct))
+	require.Nil(t, err)
+
+	station := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	signMsg := authtxb.StdSignMsg{
+		Fee:  auth.NewStdFee(100000),
+		Msgs: []sdk.Msg{mob.NewMsgInitOrder(key.GetAddress(), station, 4, 2)},
+	}
+	sig, err := hsm.Sign(session, key, signMsg)
+	require.Nil(t, err)
+
+	baseApp.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 1}})
+
+	// a signature over different sign bytes is rejected
+	forged := sig
+	forged.Signature = append([]byte(nil), sig.Signature...)
+	forged.Signature[63] ^= 1
+	res := baseApp.Deliver(auth.NewStdTx(signMsg.Msgs, signMsg.Fee, []auth.StdSignature{forged}, signMsg.Memo))
+	require.Equal(t, sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodeUnauthorized), res.Code)
+
+	res = baseApp.Deliver(auth.NewStdTx(signMsg.Msgs, signMsg.Fee, []auth.StdSignature{sig}, signMsg.Memo))
+	require.True(t, res.IsOK(), res.Log)
+
+	ctx := baseApp.BaseApp.NewContext(false, abci.Header{})
+	acc := baseApp.accountKeeper.GetAccount(ctx, key.GetAddress())
+	require.Equal(t, key.GetPubKey(), acc.GetPubKey())
+	require.Equal(t, int64(1), acc.GetSequence())
+}
+
+// unknownPubKey is a key type the ante handler has no gas cost for
+type unknownPubKey struct {
+	ed25519.PubKeyEd25519
+}
+
+func TestAnteUnknownPubKey(t *testing.T) {
+	baseApp := NewBeyondApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	privKey := ed25519.GenPrivKey()
+	pubKey := unknownPubKey{privKey.PubKey().(ed25519.PubKeyEd25519)}
+	addr := sdk.AccAddress(pubKey.Address())
+	baseAcct := auth.NewBaseAccountWithAddress(addr)
+	_, err := setGenesis(baseApp, types.NewAppAccount("station", "", "2", types.HsmInfo{}, baseAcct))
+	require.Nil(t, err)
+
+	signMsg := authtxb.StdSignMsg{
+		Fee:  auth.NewStdFee(100000),
+		Msgs: []sdk.Msg{mob.NewMsgInitOrder(addr, sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address()), 4, 2)},
+	}
+	sig, err := privKey.Sign(signMsg.Bytes())
+	require.Nil(t, err)
+
+	// the tx is rejected instead of aborting the ante handler
+	baseApp.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 1}})
+	res := baseApp.Deliver(auth.NewStdTx(signMsg.Msgs, signMsg.Fee, []auth.StdSignature{{PubKey: pubKey, Signature: sig}}, signMsg.Memo))
+	require.Equal(t, sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodeInvalidPubKey), res.Code)
+}
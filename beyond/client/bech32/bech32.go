@@ -0,0 +1,199 @@
+// Package bech32 encodes, decodes and converts the bech32 addresses and
+// public keys of the app.
+package bech32
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+	tmbech32 "github.com/tendermint/tendermint/libs/bech32"
+)
+
+// Prefix names of the addresses and public keys, Hex stands for hex bytes
+const (
+	AccAddr  = "acc"
+	AccPub   = "accpub"
+	ValAddr  = "val"
+	ValPub   = "valpub"
+	ConsAddr = "cons"
+	ConsPub  = "conspub"
+	Hex      = "hex"
+)
+
+const flagPrefix = "prefix"
+
+// Prefixes returns the bech32 prefix of each prefix name, as configured in
+// the SDK
+func Prefixes() map[string]string {
+	config := sdk.GetConfig()
+	return map[string]string{
+		AccAddr:  config.GetBech32AccountAddrPrefix(),
+		AccPub:   config.GetBech32AccountPubPrefix(),
+		ValAddr:  config.GetBech32ValidatorAddrPrefix(),
+		ValPub:   config.GetBech32ValidatorPubPrefix(),
+		ConsAddr: config.GetBech32ConsensusAddrPrefix(),
+		ConsPub:  config.GetBech32ConsensusPubPrefix(),
+	}
+}
+
+// hrp returns the bech32 prefix of a prefix name, other prefixes are used
+// as they are
+func hrp(prefix string) string {
+	if p, ok := Prefixes()[prefix]; ok {
+		return p
+	}
+	return prefix
+}
+
+// Encode returns the bech32 string of bz with a prefix name or prefix
+func Encode(prefix string, bz []byte) (string, error) {
+	if prefix == "" || prefix == Hex {
+		return "", fmt.Errorf("invalid bech32 prefix %q", prefix)
+	}
+	return tmbech32.ConvertAndEncode(hrp(prefix), bz)
+}
+
+// Decode returns the bytes of a bech32 string after checking its checksum.
+// If prefix is not empty the string must have this prefix name or prefix.
+func Decode(prefix, s string) ([]byte, error) {
+	p, bz, err := tmbech32.DecodeAndConvert(s)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "" && p != hrp(prefix) {
+		return nil, fmt.Errorf("prefix is %s, expected %s", p, hrp(prefix))
+	}
+	return bz, nil
+}
+
+// Convert re-encodes a bech32 string or hex bytes with a prefix name or
+// prefix, Hex returns the bytes as hex
+func Convert(prefix, s string) (string, error) {
+	bz, err := Decode("", s)
+	if err != nil {
+		var hexErr error
+		if bz, hexErr = hex.DecodeString(s); hexErr != nil {
+			return "", fmt.Errorf("neither bech32 nor hex: %v", err)
+		}
+	}
+	if prefix == Hex {
+		return fmt.Sprintf("%X", bz), nil
+	}
+	return Encode(prefix, bz)
+}
+
+// Commands returns the bech32 command and its subcommands
+func Commands() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bech32",
+		Short: "Encode, decode and convert bech32 addresses and public keys",
+		Long: fmt.Sprintf(`Encode, decode and convert bech32 addresses and public keys.
+
+--prefix takes a prefix name, %s, or a bech32 prefix.
+Without arguments the inputs are read from stdin, one per line, and the
+results are printed one per line. Processing stops at the first invalid
+input.`, prefixNames()),
+	}
+	cmd.AddCommand(encodeCmd(), decodeCmd(), convertCmd())
+	return cmd
+}
+
+func prefixNames() string {
+	var names []string
+	prefixes := Prefixes()
+	for _, name := range []string{AccAddr, AccPub, ValAddr, ValPub, ConsAddr, ConsPub} {
+		names = append(names, fmt.Sprintf("%s (%s)", name, prefixes[name]))
+	}
+	return strings.Join(names, ", ")
+}
+
+func encodeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "encode [hex]...",
+		Short: "Encode hex bytes as bech32",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			prefix, _ := cmd.Flags().GetString(flagPrefix)
+			return batch(os.Stdin, os.Stdout, args, func(s string) (string, error) {
+				bz, err := hex.DecodeString(s)
+				if err != nil {
+					return "", err
+				}
+				return Encode(prefix, bz)
+			})
+		},
+	}
+	cmd.Flags().String(flagPrefix, AccAddr, "Prefix of the bech32 strings")
+	return cmd
+}
+
+func decodeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "decode [bech32]...",
+		Short: "Check bech32 strings and print their bytes as hex",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			prefix, _ := cmd.Flags().GetString(flagPrefix)
+			return batch(os.Stdin, os.Stdout, args, func(s string) (string, error) {
+				bz, err := Decode(prefix, s)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%X", bz), nil
+			})
+		},
+	}
+	cmd.Flags().String(flagPrefix, "", "Prefix the bech32 strings must have, any if empty")
+	return cmd
+}
+
+func convertCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "convert [bech32|hex]...",
+		Short: "Convert bech32 strings or hex bytes to another prefix or to hex",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			prefix, _ := cmd.Flags().GetString(flagPrefix)
+			return batch(os.Stdin, os.Stdout, args, func(s string) (string, error) {
+				return Convert(prefix, s)
+			})
+		},
+	}
+	cmd.Flags().String(flagPrefix, AccAddr, "Prefix to convert to, hex for hex bytes")
+	return cmd
+}
+
+// batch prints the result of fn for each argument, or for each non empty
+// line of in if there are none
+func batch(in io.Reader, out io.Writer, args []string, fn func(string) (string, error)) error {
+	run := func(name, s string) error {
+		res, err := fn(strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		_, err = fmt.Fprintln(out, res)
+		return err
+	}
+	if len(args) > 0 {
+		for _, arg := range args {
+			if err := run(arg, arg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(in)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		if err := run(fmt.Sprintf("line %d", line), scanner.Text()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,78 @@
+package bech32
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+func TestConvert(t *testing.T) {
+	pub := ed25519.GenPrivKey().PubKey()
+	addr := sdk.AccAddress(pub.Address())
+
+	acc, err := Encode(AccAddr, addr)
+	require.Nil(t, err)
+	require.Equal(t, addr.String(), acc)
+	bz, err := Decode(AccAddr, acc)
+	require.Nil(t, err)
+	require.Equal(t, []byte(addr), bz)
+
+	// the same bytes under the other prefixes
+	val, err := Convert(ValAddr, acc)
+	require.Nil(t, err)
+	require.Equal(t, sdk.ValAddress(addr).String(), val)
+	cons, err := Convert(ConsAddr, fmt.Sprintf("%X", []byte(addr)))
+	require.Nil(t, err)
+	require.Equal(t, sdk.ConsAddress(addr).String(), cons)
+	accPub, err := Encode(AccPub, pub.Bytes())
+	require.Nil(t, err)
+	expected, err := sdk.Bech32ifyAccPub(pub)
+	require.Nil(t, err)
+	require.Equal(t, expected, accPub)
+	hexAddr, err := Convert(Hex, val)
+	require.Nil(t, err)
+	require.Equal(t, fmt.Sprintf("%X", []byte(addr)), hexAddr)
+	legacy, err := Convert("cosmosaccaddr", acc)
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(legacy, "cosmosaccaddr1"))
+
+	// prefix and checksum are checked
+	_, err = Decode(ValAddr, acc)
+	require.NotNil(t, err)
+	last := acc[len(acc)-1:]
+	corrupted := acc[:len(acc)-1] + map[bool]string{true: "q", false: "p"}[last == "p"]
+	_, err = Decode("", corrupted)
+	require.NotNil(t, err)
+	_, err = Convert(ValAddr, corrupted)
+	require.NotNil(t, err)
+	_, err = Encode(Hex, addr)
+	require.NotNil(t, err)
+}
+
+func TestBatch(t *testing.T) {
+	upper := func(s string) (string, error) {
+		if s == "bad" {
+			return "", fmt.Errorf("invalid")
+		}
+		return strings.ToUpper(s), nil
+	}
+
+	var out bytes.Buffer
+	require.Nil(t, batch(strings.NewReader("a\n\n  b \nc"), &out, nil, upper))
+	require.Equal(t, "A\nB\nC\n", out.String())
+
+	// arguments take precedence over stdin
+	out.Reset()
+	require.Nil(t, batch(strings.NewReader("a"), &out, []string{"x", "y"}, upper))
+	require.Equal(t, "X\nY\n", out.String())
+
+	out.Reset()
+	err := batch(strings.NewReader("a\nbad\nc"), &out, nil, upper)
+	require.EqualError(t, err, "line 2: invalid")
+	require.Equal(t, "A\n", out.String())
+}
@@ -0,0 +1,212 @@
+package hsm
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+
+	dc "github.com/vincepg13/bp-sdk/deepcover-client"
+
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/libs/cli"
+)
+
+const (
+	flagHSM     = "hsm"
+	flagHSMPage = "hsm-page"
+)
+
+// KeysCommands returns the SDK keys commands extended with DeepCover keys.
+// `keys add --hsm` registers the secure element of the light node, show,
+// list and delete work on both kinds of keys.
+func KeysCommands() *cobra.Command {
+	cmd := keys.Commands()
+	for _, sub := range cmd.Commands() {
+		switch sub.Name() {
+		case "add":
+			sub.Flags().Bool(flagHSM, false, "Store a reference to the key of the DeepCover secure element")
+			sub.Flags().Int(flagHSMPage, 0, "Page the secure element signs with, the I2C driver only supports page 0")
+			sub.RunE = withAddHSM(sub.RunE)
+		case "show":
+			sub.RunE = withShowHSM(sub.RunE)
+		case "list":
+			sub.RunE = withListHSM(sub.RunE)
+		case "delete":
+			sub.RunE = withDeleteHSM(sub.RunE)
+		}
+	}
+	return cmd
+}
+
+func withAddHSM(runE func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			if _, err := DefaultKeystore().Get(args[0]); err == nil {
+				return fmt.Errorf("hsm key %s already exists", args[0])
+			}
+		}
+		if !viper.GetBool(flagHSM) {
+			return runE(cmd, args)
+		}
+		if len(args) != 1 {
+			return fmt.Errorf("you must provide a name for the key")
+		}
+
+		kb, err := keys.GetKeyBase()
+		if err != nil {
+			return err
+		}
+		if _, err := kb.Get(args[0]); err == nil {
+			return fmt.Errorf("key %s already exists in the local keybase", args[0])
+		}
+
+		key, err := readKey(session(), args[0], viper.GetInt(flagHSMPage))
+		if err != nil {
+			return err
+		}
+		if err := DefaultKeystore().Add(key); err != nil {
+			return err
+		}
+		return printKeys(key)
+	}
+}
+
+// readKey reads the public key of the secure element and the device data
+// hashed when it signs with the given page. A test signature is verified
+// against them, so a page the backend can not sign with is rejected here
+// rather than at the first transaction.
+func readKey(s *dc.Session, name string, page int) (Key, error) {
+	ctx := gocontext.Background()
+
+	layout, err := s.DigestLayout(ctx, page)
+	if err != nil {
+		return Key{}, err
+	}
+	pubKey, err := s.PubKeyA(ctx)
+	if err != nil {
+		return Key{}, err
+	}
+	// fails on a chip whose key pair has not been generated yet
+	if _, err := dc.PublicKeyFromBytes(pubKey); err != nil {
+		return Key{}, err
+	}
+
+	key := Key{
+		Name:     name,
+		RomID:    layout.RomID,
+		PubKey:   pubKey,
+		ManID:    layout.ManID,
+		Page:     layout.Page,
+		PageData: layout.PageData,
+	}
+	probe := []byte("beyondcli keys add " + name)
+	sig, err := s.SignPageData(ctx, page, probe)
+	if err == dc.ErrParameter {
+		return Key{}, fmt.Errorf("the secure element can not sign with page %d", page)
+	}
+	if err != nil {
+		return Key{}, err
+	}
+	if !key.GetPubKey().VerifyBytes(probe, sig) {
+		return Key{}, fmt.Errorf("the signature of the secure element does not verify with page %d", page)
+	}
+	return key, nil
+}
+
+func withShowHSM(runE func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return runE(cmd, args)
+		}
+		key, err := DefaultKeystore().Get(args[0])
+		if err == ErrKeyNotFound {
+			return runE(cmd, args)
+		}
+		if err != nil {
+			return err
+		}
+
+		ko, err := keyOutput(key)
+		if err != nil {
+			return err
+		}
+		switch {
+		case viper.GetBool(keys.FlagAddress):
+			fmt.Println(ko.Address)
+		case viper.GetBool(keys.FlagPublicKey):
+			fmt.Println(ko.PubKey)
+		default:
+			return printKeys(key)
+		}
+		return nil
+	}
+}
+
+func withListHSM(runE func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := runE(cmd, args); err != nil {
+			return err
+		}
+		hsmKeys, err := DefaultKeystore().List()
+		if err != nil || len(hsmKeys) == 0 {
+			return err
+		}
+		return printKeys(hsmKeys...)
+	}
+}
+
+func withDeleteHSM(runE func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		err := DefaultKeystore().Delete(args[0])
+		if err == ErrKeyNotFound {
+			return runE(cmd, args)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println("HSM key reference deleted, the secure element is unchanged")
+		return nil
+	}
+}
+
+func keyOutput(key Key) (keys.KeyOutput, error) {
+	bechPubKey, err := sdk.Bech32ifyAccPub(key.GetPubKey())
+	if err != nil {
+		return keys.KeyOutput{}, err
+	}
+	return keys.KeyOutput{
+		Name:    key.Name,
+		Type:    flagHSM,
+		Address: key.GetAddress().String(),
+		PubKey:  bechPubKey,
+	}, nil
+}
+
+func printKeys(hsmKeys ...Key) error {
+	kos := make([]keys.KeyOutput, len(hsmKeys))
+	for i, key := range hsmKeys {
+		ko, err := keyOutput(key)
+		if err != nil {
+			return err
+		}
+		kos[i] = ko
+	}
+
+	if viper.GetString(cli.OutputFlag) == "json" {
+		out, err := json.MarshalIndent(kos, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Printf("NAME:\tTYPE:\tADDRESS:\t\t\t\t\t\tPUBKEY:\n")
+	for _, ko := range kos {
+		fmt.Printf("%s\t%s\t%s\t%s\n", ko.Name, ko.Type, ko.Address, ko.PubKey)
+	}
+	return nil
+}
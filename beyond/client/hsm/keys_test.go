@@ -0,0 +1,45 @@
+package hsm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	dc "github.com/vincepg13/bp-sdk/deepcover-client"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pageZeroBackend signs with page 0 only, like the I2C driver
+type pageZeroBackend struct {
+	*dc.SoftwareBackend
+}
+
+func (b pageZeroBackend) ComputeSignature(page int, challenge []byte) ([]byte, error) {
+	if page != 0 {
+		return nil, dc.ErrParameter
+	}
+	return b.SoftwareBackend.ComputeSignature(page, challenge)
+}
+
+func TestReadKey(t *testing.T) {
+	romID, _ := hex.DecodeString("4c123456789a0053")
+	backend, err := dc.NewSoftwareBackend(nil, romID, []byte{0x12, 0x34})
+	require.Nil(t, err)
+	pageData := sha256.Sum256([]byte("page 7"))
+	require.Nil(t, backend.WritePage(7, pageData[:]))
+
+	key, err := readKey(dc.NewSession(backend), "car", 7)
+	require.Nil(t, err)
+	require.Equal(t, 7, key.Page)
+	require.Equal(t, pageData[:], []byte(key.PageData))
+	require.Equal(t, romID, []byte(key.RomID))
+
+	session := dc.NewSession(pageZeroBackend{backend})
+	key, err = readKey(session, "car", 0)
+	require.Nil(t, err)
+	require.Equal(t, 0, key.Page)
+	_, err = readKey(session, "car", 7)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "page 7")
+}
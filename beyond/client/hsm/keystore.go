@@ -0,0 +1,161 @@
+package hsm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vincepg13/bp-sdk/beyond/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/libs/cli"
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+// ErrKeyNotFound is returned when no HSM key has the requested name or address
+var ErrKeyNotFound = errors.New("hsm key not found")
+
+// Key is a key held by a DeepCover secure element. Only the ROM ID and the
+// public key are stored, the private key can not leave the chip.
+type Key struct {
+	Name     string       `json:"name"`
+	RomID    cmn.HexBytes `json:"romId"`
+	PubKey   cmn.HexBytes `json:"pubKey"` // PubKeyAX followed by PubKeyAY
+	ManID    cmn.HexBytes `json:"manId"`
+	Page     int          `json:"page"` // page the chip signs with
+	PageData cmn.HexBytes `json:"pageData"`
+}
+
+// GetPubKey returns the key as used in transaction signatures
+func (k Key) GetPubKey() types.PubKeyDeepCover {
+	return types.PubKeyDeepCover{
+		RomID:    k.RomID,
+		Key:      k.PubKey,
+		ManID:    k.ManID,
+		Page:     k.Page,
+		PageData: k.PageData,
+	}
+}
+
+// GetAddress returns the account address of the key
+func (k Key) GetAddress() sdk.AccAddress {
+	return types.DeviceAddress(k.PubKey)
+}
+
+// Keystore keeps HSM keys as one JSON file per key next to the local
+// keybase.
+type Keystore struct {
+	dir string
+}
+
+// NewKeystore returns a keystore in the given directory
+func NewKeystore(dir string) Keystore {
+	return Keystore{dir: dir}
+}
+
+// DefaultKeystore returns the keystore of the client home directory
+func DefaultKeystore() Keystore {
+	return NewKeystore(filepath.Join(viper.GetString(cli.HomeFlag), "keys", "hsm"))
+}
+
+func (ks Keystore) path(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid key name %q", name)
+	}
+	return filepath.Join(ks.dir, name+".json"), nil
+}
+
+// Add stores a new key, names must be unique
+func (ks Keystore) Add(key Key) error {
+	path, err := ks.path(key.Name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("hsm key %s already exists", key.Name)
+	}
+	if err := os.MkdirAll(ks.dir, 0700); err != nil {
+		return err
+	}
+	bz, err := json.MarshalIndent(key, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, bz, 0600)
+}
+
+// Get returns the key with the given name
+func (ks Keystore) Get(name string) (key Key, err error) {
+	path, err := ks.path(name)
+	if err != nil {
+		return key, err
+	}
+	bz, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return key, ErrKeyNotFound
+	}
+	if err != nil {
+		return key, err
+	}
+	err = json.Unmarshal(bz, &key)
+	return key, err
+}
+
+// GetByAddress returns the key owning the given account
+func (ks Keystore) GetByAddress(addr sdk.AccAddress) (Key, error) {
+	keys, err := ks.List()
+	if err != nil {
+		return Key{}, err
+	}
+	for _, key := range keys {
+		if key.GetAddress().Equals(addr) {
+			return key, nil
+		}
+	}
+	return Key{}, ErrKeyNotFound
+}
+
+// Lookup resolves a --from value, a key name or a bech32 address
+func (ks Keystore) Lookup(from string) (Key, error) {
+	if from == "" {
+		return Key{}, ErrKeyNotFound
+	}
+	if addr, err := sdk.AccAddressFromBech32(from); err == nil {
+		return ks.GetByAddress(addr)
+	}
+	return ks.Get(from)
+}
+
+// List returns all keys sorted by name
+func (ks Keystore) List() (keys []Key, err error) {
+	matches, err := filepath.Glob(filepath.Join(ks.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range matches {
+		key, err := ks.Get(strings.TrimSuffix(filepath.Base(match), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Delete removes the key, the secure element itself is left untouched
+func (ks Keystore) Delete(name string) error {
+	path, err := ks.path(name)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return ErrKeyNotFound
+	}
+	return err
+}
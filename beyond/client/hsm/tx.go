@@ -0,0 +1,223 @@
+package hsm
+
+import (
+	"bytes"
+	gocontext "context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	dc "github.com/vincepg13/bp-sdk/deepcover-client"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/utils"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	authtxb "github.com/cosmos/cosmos-sdk/x/auth/client/txbuilder"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/libs/cli"
+	"github.com/tendermint/tendermint/libs/log"
+	tmlite "github.com/tendermint/tendermint/lite"
+	tmliteProxy "github.com/tendermint/tendermint/lite/proxy"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+)
+
+var session = dc.DefaultSession
+
+// SetSession replaces the secure element used for signing, e.g. with a
+// software backend in tests and simulators
+func SetSession(s *dc.Session) {
+	session = func() *dc.Session { return s }
+}
+
+// NewCLIContext returns a CLIContext like context.NewCLIContext. The SDK
+// context resolves --from against the local keybase and exits if the key
+// is not there, so for an HSM key the context is built from the same flags
+// here and only From is set. Use GetFromAddress and EnsureAccountExists from
+// this package instead of the CLIContext methods.
+func NewCLIContext() context.CLIContext {
+	from := viper.GetString(client.FlagFrom)
+	if _, err := DefaultKeystore().Lookup(from); err != nil {
+		return context.NewCLIContext()
+	}
+
+	var rpc rpcclient.Client
+	nodeURI := viper.GetString(client.FlagNode)
+	if nodeURI != "" {
+		rpc = rpcclient.NewHTTP(nodeURI, "/websocket")
+	}
+	if verifier == nil {
+		verifier = createVerifier()
+	}
+
+	return context.CLIContext{
+		Client:        rpc,
+		Output:        os.Stdout,
+		NodeURI:       nodeURI,
+		AccountStore:  "acc",
+		From:          from,
+		Height:        viper.GetInt64(client.FlagHeight),
+		TrustNode:     viper.GetBool(client.FlagTrustNode),
+		UseLedger:     viper.GetBool(client.FlagUseLedger),
+		Async:         viper.GetBool(client.FlagAsync),
+		JSON:          viper.GetBool(client.FlagJson),
+		PrintResponse: viper.GetBool(client.FlagPrintResponse),
+		Verifier:      verifier,
+		DryRun:        viper.GetBool(client.FlagDryRun),
+		GenerateOnly:  viper.GetBool(client.FlagGenerateOnly),
+		Indent:        viper.GetBool(client.FlagIndentResponse),
+	}
+}
+
+// verifier is shared by the HSM contexts like the SDK shares its own
+var verifier tmlite.Verifier
+
+// createVerifier returns the light client verifier of the SDK context, nil
+// if the node is trusted
+func createVerifier() tmlite.Verifier {
+	if !viper.IsSet(client.FlagTrustNode) || viper.GetBool(client.FlagTrustNode) {
+		return nil
+	}
+
+	chainID := viper.GetString(client.FlagChainID)
+	home := viper.GetString(cli.HomeFlag)
+	nodeURI := viper.GetString(client.FlagNode)
+	if chainID == "" || home == "" || nodeURI == "" {
+		fmt.Println("Must specify --chain-id, --home and --node when --trust-node is false")
+		os.Exit(1)
+	}
+
+	node := rpcclient.NewHTTP(nodeURI, "/websocket")
+	v, err := tmliteProxy.NewVerifier(chainID, filepath.Join(home, ".gaialite"), node, log.NewNopLogger(), 10)
+	if err != nil {
+		fmt.Printf("Create verifier failed: %s\n", err.Error())
+		os.Exit(1)
+	}
+	return v
+}
+
+// GetFromAddress returns the address of the --from key, local or HSM
+func GetFromAddress(cliCtx context.CLIContext) (sdk.AccAddress, error) {
+	if key, err := DefaultKeystore().Lookup(cliCtx.From); err == nil {
+		return key.GetAddress(), nil
+	}
+	return cliCtx.GetFromAddress()
+}
+
+// EnsureAccountExists ensures that the --from account exists on chain
+func EnsureAccountExists(cliCtx context.CLIContext) error {
+	from, err := GetFromAddress(cliCtx)
+	if err != nil {
+		return err
+	}
+	return cliCtx.EnsureAccountExistsFromAddr(from)
+}
+
+// Sign signs msg on the secure element holding key
+func Sign(s *dc.Session, key Key, msg authtxb.StdSignMsg) (sig auth.StdSignature, err error) {
+	ctx := gocontext.Background()
+
+	romID, err := s.RomID(ctx)
+	if err != nil {
+		return sig, err
+	}
+	if !bytes.Equal(romID, key.RomID) {
+		return sig, fmt.Errorf("secure element %X does not hold key %s", romID, key.Name)
+	}
+
+	signature, err := s.SignPageData(ctx, key.Page, msg.Bytes())
+	if err != nil {
+		return sig, err
+	}
+	return auth.StdSignature{
+		AccountNumber: msg.AccountNumber,
+		Sequence:      msg.Sequence,
+		PubKey:        key.GetPubKey(),
+		Signature:     signature,
+	}, nil
+}
+
+// CompleteAndBroadcastTxCli works like utils.CompleteAndBroadcastTxCli and
+// is used in its place by the Beyond commands. Transactions from an HSM key
+// are signed by the secure element, all others are handed to the SDK.
+func CompleteAndBroadcastTxCli(txBldr authtxb.TxBuilder, cliCtx context.CLIContext, msgs []sdk.Msg) error {
+	key, err := DefaultKeystore().Lookup(cliCtx.From)
+	if err == ErrKeyNotFound {
+		return utils.CompleteAndBroadcastTxCli(txBldr, cliCtx, msgs)
+	}
+	if err != nil {
+		return err
+	}
+
+	from := key.GetAddress()
+	if err := cliCtx.EnsureAccountExistsFromAddr(from); err != nil {
+		return err
+	}
+	if txBldr.AccountNumber == 0 {
+		accNum, err := cliCtx.GetAccountNumber(from)
+		if err != nil {
+			return err
+		}
+		txBldr = txBldr.WithAccountNumber(accNum)
+	}
+	if txBldr.Sequence == 0 {
+		accSeq, err := cliCtx.GetAccountSequence(from)
+		if err != nil {
+			return err
+		}
+		txBldr = txBldr.WithSequence(accSeq)
+	}
+
+	if txBldr.SimulateGas || cliCtx.DryRun {
+		txBldr, err = enrichWithGas(txBldr, cliCtx, key, msgs)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "estimated gas = %v\n", txBldr.Gas)
+	}
+	if cliCtx.DryRun {
+		return nil
+	}
+
+	msg, err := txBldr.Build(msgs)
+	if err != nil {
+		return err
+	}
+	sig, err := Sign(session(), key, msg)
+	if err != nil {
+		return err
+	}
+	txBytes, err := txBldr.Codec.MarshalBinaryLengthPrefixed(auth.NewStdTx(msg.Msgs, msg.Fee, []auth.StdSignature{sig}, msg.Memo))
+	if err != nil {
+		return err
+	}
+
+	_, err = cliCtx.BroadcastTx(txBytes)
+	return err
+}
+
+// enrichWithGas simulates the transaction with the public key of the HSM
+// key, the counterpart of txBldr.BuildWithPubKey for keys the keybase
+// does not know
+func enrichWithGas(txBldr authtxb.TxBuilder, cliCtx context.CLIContext, key Key, msgs []sdk.Msg) (authtxb.TxBuilder, error) {
+	msg, err := txBldr.Build(msgs)
+	if err != nil {
+		return txBldr, err
+	}
+	sigs := []auth.StdSignature{{
+		AccountNumber: msg.AccountNumber,
+		Sequence:      msg.Sequence,
+		PubKey:        key.GetPubKey(),
+	}}
+	txBytes, err := txBldr.Codec.MarshalBinaryLengthPrefixed(auth.NewStdTx(msg.Msgs, msg.Fee, sigs, msg.Memo))
+	if err != nil {
+		return txBldr, err
+	}
+
+	_, adjusted, err := utils.CalculateGas(cliCtx.Query, cliCtx.Codec, txBytes, txBldr.GasAdjustment)
+	if err != nil {
+		return txBldr, err
+	}
+	return txBldr.WithGas(adjusted), nil
+}
@@ -0,0 +1,43 @@
+package hsm
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/cli"
+)
+
+func TestNewCLIContext(t *testing.T) {
+	home, err := ioutil.TempDir("", "hsm")
+	require.Nil(t, err)
+	defer os.RemoveAll(home)
+	viper.Set(cli.HomeFlag, home)
+
+	key := Key{
+		Name:   "car",
+		RomID:  bytes.Repeat([]byte{1}, 8),
+		PubKey: bytes.Repeat([]byte{2}, 64),
+		ManID:  []byte{0, 0},
+	}
+	require.Nil(t, DefaultKeystore().Add(key))
+
+	// an HSM key is not looked up in the local keybase and the flags are
+	// left alone
+	viper.Set(client.FlagFrom, "car")
+	viper.Set(client.FlagNode, "tcp://localhost:26657")
+	viper.Set(client.FlagTrustNode, true)
+	cliCtx := NewCLIContext()
+	require.Equal(t, "car", viper.GetString(client.FlagFrom))
+	require.Equal(t, "car", cliCtx.From)
+	require.Equal(t, "tcp://localhost:26657", cliCtx.NodeURI)
+	require.True(t, cliCtx.TrustNode)
+
+	from, err := GetFromAddress(cliCtx)
+	require.Nil(t, err)
+	require.Equal(t, key.GetAddress(), from)
+}
@@ -13,10 +13,13 @@ import (
 	"os"
 	"path/filepath"
 
+	beyond "github.com/vincepg13/bp-sdk/beyond/types"
+
 	"github.com/cosmos/cosmos-sdk/types"
 )
 
 func main() {
+	beyond.SetBech32Prefixes()
 	if len(os.Args) != 2 {
 		file := filepath.Base(os.Args[0])
 		fmt.Println("Bech32 byte encoder")
@@ -25,7 +28,7 @@ func main() {
 		fmt.Println("\t\twhere <bytes> is a hex-string-encoded byte array")
 		fmt.Println("Example:")
 		fmt.Println("\t" + file + " 48656c6c6f20476f7068657221")
-		fmt.Println("Remarks: resulting bech32 string will be prefixed with the following string: '" + types.Bech32PrefixAccAddr + "'.")
+		fmt.Println("Remarks: resulting bech32 string will be prefixed with the following string: '" + types.GetConfig().GetBech32AccountAddrPrefix() + "'.")
 		return
 	}
 	argsWithoutProg := os.Args[1:]
@@ -12,18 +12,21 @@ import (
 	"os"
 	"path/filepath"
 
+	beyond "github.com/vincepg13/bp-sdk/beyond/types"
+
 	"github.com/cosmos/cosmos-sdk/types"
 )
 
 func main() {
+	beyond.SetBech32Prefixes()
 	if len(os.Args) != 2 {
 		file := filepath.Base(os.Args[0])
 		fmt.Println("Bech32 string decoder")
 		fmt.Println("Usage:")
 		fmt.Println("\t" + file + " <bech32 encoded string>")
 		fmt.Println("Example:")
-		fmt.Println("\t" + file + " cosmosaccaddr1qgps2m09t2")
-		fmt.Println("Remarks: it is assumed that the bech32 string uses the following prefix: '" + types.Bech32PrefixAccAddr + "'.")
+		fmt.Println("\t" + file + " byndaddr1j6j0f6kvs92zazh3tmjvvu8ga8x4h9hft762pg")
+		fmt.Println("Remarks: it is assumed that the bech32 string uses the following prefix: '" + types.GetConfig().GetBech32AccountAddrPrefix() + "'.")
 		return
 	}
 	argsWithoutProg := os.Args[1:][0]
@@ -4,11 +4,15 @@ import (
 	"os"
 
 	"github.com/vincepg13/bp-sdk/beyond/app"
+	"github.com/vincepg13/bp-sdk/beyond/client/bech32"
+	"github.com/vincepg13/bp-sdk/beyond/client/hsm"
 	"github.com/vincepg13/bp-sdk/beyond/types"
+	beaconcmd "github.com/vincepg13/bp-sdk/beyond/x/beacon/client/cli"
+	devcmd "github.com/vincepg13/bp-sdk/beyond/x/device/client/cli"
 	mobcmd "github.com/vincepg13/bp-sdk/beyond/x/mobility/client/cli"
+	revcmd "github.com/vincepg13/bp-sdk/beyond/x/revocation/client/cli"
 
 	"github.com/cosmos/cosmos-sdk/client"
-	"github.com/cosmos/cosmos-sdk/client/keys"
 	"github.com/cosmos/cosmos-sdk/client/lcd"
 	_ "github.com/cosmos/cosmos-sdk/client/lcd/statik"
 	"github.com/cosmos/cosmos-sdk/client/rpc"
@@ -35,6 +39,8 @@ func main() {
 	// disable sorting
 	cobra.EnableCommandSorting = false
 
+	types.SetBech32Prefixes()
+
 	// get the codec
 	cdc := app.MakeCodec()
 
@@ -65,6 +71,13 @@ func main() {
 			stakecmd.GetCmdQueryRedelegations("stake", cdc),
 			slashingcmd.GetCmdQuerySigningInfo("slashing", cdc),
 			authcmd.GetAccountCmd("acc", cdc, types.GetAccountDecoder(cdc)),
+			revcmd.GetCmdQueryRevocations("revocation", cdc),
+			revcmd.GetCmdQueryRevocation("revocation", cdc),
+			revcmd.GetCmdQueryRevokers("revocation", cdc),
+			devcmd.GetCmdQueryAttestation("device", cdc),
+			devcmd.GetCmdQueryAttestationPolicy("device", cdc),
+			beaconcmd.GetCmdQueryBeacon("beacon", cdc),
+			beaconcmd.GetCmdQueryBeaconEpoch("beacon", cdc),
 		)...)
 
 	rootCmd.AddCommand(
@@ -72,6 +85,13 @@ func main() {
 			bankcmd.SendTxCmd(cdc),
 			mobcmd.SendInitOrderTxCmd(cdc),
 			mobcmd.SendFinalizeOrderTxCmd(cdc),
+			devcmd.OnboardDeviceTxCmd(cdc),
+			devcmd.AttestTxCmd(cdc),
+			devcmd.SetAttestationPolicyTxCmd(cdc),
+			revcmd.RevokeDeviceTxCmd(cdc),
+			beaconcmd.CommitRandomnessTxCmd(cdc),
+			beaconcmd.RevealRandomnessTxCmd(cdc),
+			beaconcmd.RegisterValidatorTxCmd(cdc),
 			ibccmd.IBCTransferCmd(cdc),
 			ibccmd.IBCRelayCmd(cdc),
 			stakecmd.GetCmdCreateValidator(cdc),
@@ -80,13 +100,16 @@ func main() {
 			stakecmd.GetCmdUnbond("stake", cdc),
 			stakecmd.GetCmdRedelegate("stake", cdc),
 			slashingcmd.GetCmdUnjail(cdc),
+			authcmd.GetSignCommand(cdc, authcmd.GetAccountDecoder(cdc)),
+			bankcmd.GetBroadcastCommand(cdc),
 		)...)
 
 	// add proxy, version and key info
 	rootCmd.AddCommand(
 		client.LineBreak,
 		lcd.ServeCommand(cdc),
-		keys.Commands(),
+		hsm.KeysCommands(),
+		bech32.Commands(),
 		client.LineBreak,
 		version.VersionCmd,
 	)
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/vincepg13/bp-sdk/beyond/app"
+	"github.com/vincepg13/bp-sdk/beyond/types"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/server"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/libs/cli"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+const (
+	flagMacAddress = "mac"
+	flagPrice      = "price"
+)
+
+// AddGenesisAccountCmd adds an account to the app state of the genesis file
+func AddGenesisAccountCmd(ctx *server.Context, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-genesis-account [address_or_key_name] [coins]",
+		Short: "Add a genesis account to genesis.json",
+		Long: `Add an account with its coins to the app state of genesis.json. The
+account is a bech32 address or the name of a key of --home-client. --mac and
+--price are optional, accounts without a charging device leave them out. The
+MAC address must be 48 bits long, e.g. 60-67-20-C0-E4-59, and the price a non
+negative decimal. An address already in the genesis is rejected.
+
+Example:
+	beyondd add-genesis-account station 100000000byndcoin --name=station --mac=60-67-20-C0-E4-59 --price=2
+	`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			config := ctx.Config
+			config.SetRoot(viper.GetString(cli.HomeFlag))
+
+			addr, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				kb, err := keys.GetKeyBaseFromDir(viper.GetString(flagClientHome))
+				if err != nil {
+					return err
+				}
+				info, err := kb.Get(args[0])
+				if err != nil {
+					return fmt.Errorf("%s is neither an address nor a key: %v", args[0], err)
+				}
+				addr = info.GetAddress()
+			}
+			coins, err := sdk.ParseCoins(args[1])
+			if err != nil {
+				return err
+			}
+			acc := &types.GenesisAccount{
+				Name:       viper.GetString(client.FlagName),
+				MacAddress: viper.GetString(flagMacAddress),
+				Price:      viper.GetString(flagPrice),
+				Address:    addr,
+				Coins:      coins.Sort(),
+			}
+			if err := acc.Validate(); err != nil {
+				return err
+			}
+
+			genFile := config.GenesisFile()
+			genDoc, err := tmtypes.GenesisDocFromFile(genFile)
+			if err != nil {
+				return err
+			}
+			var state types.GenesisState
+			if len(genDoc.AppState) > 0 {
+				if err := cdc.UnmarshalJSON(genDoc.AppState, &state); err != nil {
+					return fmt.Errorf("invalid app state: %v", err)
+				}
+			}
+			state.Accounts = append(state.Accounts, acc)
+			if err := state.Validate(); err != nil {
+				return err
+			}
+
+			genDoc.AppState, err = codec.MarshalJSONIndent(cdc, state)
+			if err != nil {
+				return err
+			}
+			return genDoc.SaveAs(genFile)
+		},
+	}
+
+	cmd.Flags().String(cli.HomeFlag, app.DefaultNodeHome, "node's home directory")
+	cmd.Flags().String(flagClientHome, app.DefaultCLIHome, "client's home directory")
+	cmd.Flags().String(client.FlagName, "", "Name of the account")
+	cmd.Flags().String(flagMacAddress, "", "MAC address of the account's device, optional")
+	cmd.Flags().String(flagPrice, "", "Price of the electricity the account sells, optional")
+	return cmd
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/tendermint/tendermint/p2p"
 
 	"github.com/vincepg13/bp-sdk/beyond/app"
+	"github.com/vincepg13/bp-sdk/beyond/types"
 
 	"github.com/cosmos/cosmos-sdk/server"
 	"github.com/spf13/cobra"
@@ -30,6 +31,7 @@ const (
 )
 
 func main() {
+	types.SetBech32Prefixes()
 	cdc := app.MakeCodec()
 	ctx := server.NewDefaultContext()
 
@@ -41,6 +43,9 @@ func main() {
 
 	appInit := server.DefaultAppInit
 	rootCmd.AddCommand(InitCmd(ctx, cdc, appInit))
+	rootCmd.AddCommand(TestnetFilesCmd(ctx, cdc))
+	rootCmd.AddCommand(AddGenesisAccountCmd(ctx, cdc))
+	rootCmd.AddCommand(MigrateGenesisCmd(cdc))
 
 	server.AddCommands(ctx, cdc, rootCmd, appInit,
 		newApp, exportAppStateAndTMValidators)
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/vincepg13/bp-sdk/beyond/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/spf13/cobra"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+const flagOutput = "output"
+
+// MigrateGenesisCmd rewrites the legacy bech32 addresses of a genesis file
+// with the Beyond prefixes and resets the hsmInfo of its accounts that have
+// the layout of earlier releases
+func MigrateGenesisCmd(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate-genesis [genesis-file]",
+		Short: "Migrate a genesis file of an earlier release",
+		Long: `Rewrite the bech32 addresses and public keys of a genesis file that use
+the prefixes of earlier releases (cosmosaccaddr, cosmosaccpub, ...) or of
+the SDK (cosmos, cosmospub, ...) with the Beyond prefixes, and reset the
+hsmInfo of the accounts that have the layout of earlier releases. Their
+devices onboard again with an onboardDevice tx. The app state of the
+migrated genesis is validated as by InitChain, then the genesis is printed,
+or written to --output.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bz, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			migrated, count, err := types.MigrateBech32(bz)
+			if err != nil {
+				return err
+			}
+			migrated, reset, err := types.MigrateHsmInfo(cdc, migrated)
+			if err != nil {
+				return err
+			}
+
+			doc, err := tmtypes.GenesisDocFromJSON(migrated)
+			if err != nil {
+				return err
+			}
+			var state types.GenesisState
+			if len(doc.AppState) > 0 {
+				if err := cdc.UnmarshalJSON(doc.AppState, &state); err != nil {
+					return fmt.Errorf("invalid app state: %v", err)
+				}
+			}
+			if err := state.Validate(); err != nil {
+				return fmt.Errorf("invalid app state: %v", err)
+			}
+
+			fmt.Fprintf(os.Stderr, "Rewrote %d addresses, reset the hsmInfo of %d accounts\n", count, reset)
+			output, _ := cmd.Flags().GetString(flagOutput)
+			if output == "" {
+				_, err = os.Stdout.Write(migrated)
+				return err
+			}
+			return ioutil.WriteFile(output, migrated, 0644)
+		},
+	}
+	cmd.Flags().String(flagOutput, "", "File to write the migrated genesis to, stdout if empty")
+	return cmd
+}
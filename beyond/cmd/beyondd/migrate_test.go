@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vincepg13/bp-sdk/beyond/app"
+	"github.com/vincepg13/bp-sdk/beyond/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+func TestMain(m *testing.M) {
+	types.SetBech32Prefixes()
+	os.Exit(m.Run())
+}
+
+func TestMigrateGenesis(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cdc := app.MakeCodec()
+	account := func(name string, addr sdk.AccAddress) *types.GenesisAccount {
+		return &types.GenesisAccount{Name: name, Address: addr, Coins: sdk.Coins{sdk.NewInt64Coin("byndcoin", 100)}}
+	}
+	migrate := func(accounts ...*types.GenesisAccount) (string, error) {
+		appState, err := cdc.MarshalJSON(types.GenesisState{Accounts: accounts})
+		require.Nil(t, err)
+		genesis := filepath.Join(dir, "genesis.json")
+		require.Nil(t, (&tmtypes.GenesisDoc{ChainID: "beyond-chain", AppState: appState}).SaveAs(genesis))
+
+		output := filepath.Join(dir, "migrated.json")
+		os.Remove(output)
+		cmd := MigrateGenesisCmd(cdc)
+		cmd.SetArgs([]string{genesis, "--" + flagOutput, output})
+		return output, cmd.Execute()
+	}
+
+	station := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	car := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	output, err := migrate(account("station", station), account("car", car))
+	require.Nil(t, err)
+	doc, err := tmtypes.GenesisDocFromFile(output)
+	require.Nil(t, err)
+	var state types.GenesisState
+	require.Nil(t, cdc.UnmarshalJSON(doc.AppState, &state))
+	require.Len(t, state.Accounts, 2)
+
+	// a genesis InitChain would reject is not written
+	output, err = migrate(account("station", station), account("car", station))
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "already exists")
+	_, err = os.Stat(output)
+	require.True(t, os.IsNotExist(err))
+}
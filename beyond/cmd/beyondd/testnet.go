@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vincepg13/bp-sdk/beyond/types"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	clkeys "github.com/cosmos/cosmos-sdk/client/keys"
+	gaiaInit "github.com/cosmos/cosmos-sdk/cmd/gaia/init"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/keys"
+	"github.com/cosmos/cosmos-sdk/server"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	cfg "github.com/tendermint/tendermint/config"
+	cmn "github.com/tendermint/tendermint/libs/common"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	tmtypes "github.com/tendermint/tendermint/types"
+	tmtime "github.com/tendermint/tendermint/types/time"
+)
+
+const (
+	flagNumValidators     = "v"
+	flagOutputDir         = "output-dir"
+	flagNodeDirPrefix     = "node-dir-prefix"
+	flagNodeDaemonHome    = "node-daemon-home"
+	flagNodeCliHome       = "node-cli-home"
+	flagStartingIPAddress = "starting-ip-address"
+	flagKeyPass           = "key-pass"
+)
+
+const (
+	nodeDirPerm      = 0755
+	validatorPower   = 10
+	defaultKeyPass   = "12345678"
+	testnetCoinDenom = "byndcoin"
+)
+
+// testnetAccount is a funded account of each node of a testnet, with a key
+// of the same name in the node's beyondcli home
+type testnetAccount struct {
+	name  string
+	price string
+	coins int64
+}
+
+var testnetAccounts = []testnetAccount{
+	{name: "station", price: "2", coins: 100000000},
+	{name: "car", price: "1", coins: 1000000},
+}
+
+// TestnetFilesCmd initializes the files of a testnet of validators
+func TestnetFilesCmd(ctx *server.Context, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "testnet",
+		Short: "Initialize files for a Beyond testnet",
+		Long: `testnet creates "v" node directories, each holding the home of a validator
+(private validator, node key, config.toml) and a beyondcli home with the keys
+of a funded station and car account. All nodes share the genesis, which lists
+every validator and account, and are listed in each other's persistent peers.
+
+Note, strict routability for addresses is turned off in the config files.
+
+Example:
+	beyondd testnet --v 4 --output-dir ./mytestnet --starting-ip-address 192.168.10.2
+	`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return initTestnet(ctx.Config, cdc)
+		},
+	}
+
+	cmd.Flags().Int(flagNumValidators, 4, "Number of validators to initialize the testnet with")
+	cmd.Flags().StringP(flagOutputDir, "o", "./mytestnet", "Directory to store initialization data for the testnet")
+	cmd.Flags().String(flagNodeDirPrefix, "node", "Prefix the directory name for each node with (node results in node0, node1, ...)")
+	cmd.Flags().String(flagNodeDaemonHome, "beyondd", "Home directory of the node's daemon configuration")
+	cmd.Flags().String(flagNodeCliHome, "beyondcli", "Home directory of the node's cli configuration")
+	cmd.Flags().String(flagStartingIPAddress, "192.168.0.1", "Starting IP address (192.168.0.1 results in persistent peers list ID0@192.168.0.1:26656, ID1@192.168.0.2:26656, ...)")
+	cmd.Flags().String(client.FlagChainID, "", "genesis file chain-id, if left blank will be randomly created")
+	cmd.Flags().String(flagKeyPass, defaultKeyPass, "Password of the generated keys")
+	return cmd
+}
+
+func initTestnet(config *cfg.Config, cdc *codec.Codec) (err error) {
+	outDir := viper.GetString(flagOutputDir)
+	numValidators := viper.GetInt(flagNumValidators)
+	if numValidators < 1 {
+		return fmt.Errorf("at least one validator is required")
+	}
+	if cmn.FileExists(outDir) {
+		return fmt.Errorf("output directory %s already exists", outDir)
+	}
+	chainID := viper.GetString(client.FlagChainID)
+	if chainID == "" {
+		chainID = "chain-" + cmn.RandStr(6)
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(outDir)
+		}
+	}()
+
+	var (
+		nodeDirs   []string
+		peers      []string
+		validators []tmtypes.GenesisValidator
+		accounts   []*types.GenesisAccount
+	)
+
+	// generate the validator and node keys and the keys of the accounts
+	for i := 0; i < numValidators; i++ {
+		nodeDirName := fmt.Sprintf("%s%d", viper.GetString(flagNodeDirPrefix), i)
+		nodeDir := filepath.Join(outDir, nodeDirName, viper.GetString(flagNodeDaemonHome))
+		clientDir := filepath.Join(outDir, nodeDirName, viper.GetString(flagNodeCliHome))
+
+		if err := os.MkdirAll(filepath.Join(nodeDir, "config"), nodeDirPerm); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(clientDir, nodeDirPerm); err != nil {
+			return err
+		}
+		config.SetRoot(nodeDir)
+		config.Moniker = nodeDirName
+
+		ip, err := calculateIP(viper.GetString(flagStartingIPAddress), i)
+		if err != nil {
+			return err
+		}
+		nodeID, valPubKey, err := gaiaInit.InitializeNodeValidatorFiles(config)
+		if err != nil {
+			return err
+		}
+		nodeDirs = append(nodeDirs, nodeDir)
+		peers = append(peers, fmt.Sprintf("%s@%s:26656", nodeID, ip))
+		validators = append(validators, tmtypes.GenesisValidator{
+			PubKey: valPubKey,
+			Power:  validatorPower,
+			Name:   nodeDirName,
+		})
+
+		addrs, secrets, err := generateTestnetKeys(clientDir, viper.GetString(flagKeyPass))
+		if err != nil {
+			return err
+		}
+		for j, acc := range testnetAccounts {
+			accounts = append(accounts, &types.GenesisAccount{
+				Name:       acc.name,
+				MacAddress: testnetMacAddress(i, j),
+				Price:      acc.price,
+				Address:    addrs[j],
+				Coins:      sdk.Coins{sdk.NewInt64Coin(testnetCoinDenom, acc.coins)},
+			})
+		}
+
+		// save the recovery phrases of the keys
+		bz, err := json.MarshalIndent(secrets, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := cmn.WriteFile(filepath.Join(clientDir, "key_seed.json"), bz, 0600); err != nil {
+			return err
+		}
+	}
+
+	appState, err := codec.MarshalJSONIndent(cdc, types.GenesisState{Accounts: accounts})
+	if err != nil {
+		return err
+	}
+
+	// write the shared genesis and wire every node to the others
+	genTime := tmtime.Now()
+	for i, nodeDir := range nodeDirs {
+		config.SetRoot(nodeDir)
+		config.Moniker = validators[i].Name
+		config.P2P.AddrBookStrict = false
+		config.P2P.PersistentPeers = strings.Join(append(append([]string{}, peers[:i]...), peers[i+1:]...), ",")
+		cfg.WriteConfigFile(filepath.Join(nodeDir, "config", "config.toml"), config)
+
+		err := gaiaInit.ExportGenesisFileWithTime(config.GenesisFile(), chainID, validators, appState, genTime)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Successfully initialized %d node directories for chain %s\n", numValidators, chainID)
+	return nil
+}
+
+// generateTestnetKeys creates the keys of the testnet accounts in the key
+// store of a beyondcli home and returns their addresses and recovery phrases.
+// The store is opened here, client/keys keeps the first store it opens for
+// the whole process and would put the keys of every node in the first one.
+func generateTestnetKeys(clientDir, pass string) (addrs []sdk.AccAddress, secrets map[string]string, err error) {
+	db, err := dbm.NewGoLevelDB(clkeys.KeyDBName, filepath.Join(clientDir, "keys"))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer db.Close()
+	kb := client.GetKeyBase(db)
+
+	secrets = map[string]string{}
+	for _, acc := range testnetAccounts {
+		info, secret, err := kb.CreateMnemonic(acc.name, keys.English, pass, keys.Secp256k1)
+		if err != nil {
+			return nil, nil, err
+		}
+		addrs = append(addrs, sdk.AccAddress(info.GetPubKey().Address()))
+		secrets[acc.name] = secret
+	}
+	return addrs, secrets, nil
+}
+
+// testnetMacAddress returns a locally administered MAC address, unique per
+// node and account
+func testnetMacAddress(node, account int) string {
+	return fmt.Sprintf("02-00-00-%02X-%02X-%02X", node>>8&0xff, node&0xff, account)
+}
+
+// calculateIP returns the ip address i addresses after ip, carrying into
+// the upper bytes of the address
+func calculateIP(ip string, i int) (string, error) {
+	ipv4 := net.ParseIP(ip).To4()
+	if ipv4 == nil {
+		return "", fmt.Errorf("%v: non ipv4 address", ip)
+	}
+
+	n := uint64(binary.BigEndian.Uint32(ipv4)) + uint64(i)
+	if i < 0 || n > math.MaxUint32 {
+		return "", fmt.Errorf("%v: no ipv4 address %d addresses after it", ip, i)
+	}
+	binary.BigEndian.PutUint32(ipv4, uint32(n))
+
+	return ipv4.String(), nil
+}
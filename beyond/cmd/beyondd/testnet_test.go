@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vincepg13/bp-sdk/beyond/app"
+	"github.com/vincepg13/bp-sdk/beyond/types"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	clkeys "github.com/cosmos/cosmos-sdk/client/keys"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	cfg "github.com/tendermint/tendermint/config"
+	cmn "github.com/tendermint/tendermint/libs/common"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/p2p"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+func TestInitTestnet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testnet")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	outDir := filepath.Join(dir, "mytestnet")
+	viper.Set(flagOutputDir, outDir)
+	viper.Set(flagNumValidators, 2)
+	viper.Set(flagNodeDirPrefix, "node")
+	viper.Set(flagNodeDaemonHome, "beyondd")
+	viper.Set(flagNodeCliHome, "beyondcli")
+	// the second node is past the end of the last byte of the address
+	viper.Set(flagStartingIPAddress, "192.168.0.255")
+	viper.Set(flagKeyPass, defaultKeyPass)
+	viper.Set(client.FlagChainID, "beyond-testnet")
+	cdc := app.MakeCodec()
+	require.Nil(t, initTestnet(cfg.DefaultConfig(), cdc))
+
+	nodeDir := func(i int) string { return filepath.Join(outDir, fmt.Sprintf("node%d", i), "beyondd") }
+	clientDir := func(i int) string { return filepath.Join(outDir, fmt.Sprintf("node%d", i), "beyondcli") }
+
+	// every node has the same genesis
+	genesis, err := ioutil.ReadFile(filepath.Join(nodeDir(0), "config", "genesis.json"))
+	require.Nil(t, err)
+	other, err := ioutil.ReadFile(filepath.Join(nodeDir(1), "config", "genesis.json"))
+	require.Nil(t, err)
+	require.Equal(t, string(genesis), string(other))
+	genDoc, err := tmtypes.GenesisDocFromJSON(genesis)
+	require.Nil(t, err)
+	require.Equal(t, "beyond-testnet", genDoc.ChainID)
+	require.Len(t, genDoc.Validators, 2)
+	var state types.GenesisState
+	require.Nil(t, cdc.UnmarshalJSON(genDoc.AppState, &state))
+	require.Nil(t, state.Validate())
+	require.Len(t, state.Accounts, 2*len(testnetAccounts))
+
+	// each node has the other as its persistent peer
+	ips := []string{"192.168.0.255", "192.168.1.0"}
+	for i := 0; i < 2; i++ {
+		peer := 1 - i
+		nodeKey, err := p2p.LoadNodeKey(filepath.Join(nodeDir(peer), "config", "node_key.json"))
+		require.Nil(t, err)
+
+		config := viper.New()
+		config.SetConfigFile(filepath.Join(nodeDir(i), "config", "config.toml"))
+		require.Nil(t, config.ReadInConfig())
+		require.Equal(t, fmt.Sprintf("%s@%s:26656", nodeKey.ID(), ips[peer]), config.GetString("p2p.persistent_peers"))
+		require.False(t, config.GetBool("p2p.addr_book_strict"))
+	}
+
+	// each node has its own station and car keys, funded in the genesis
+	for i := 0; i < 2; i++ {
+		db, err := dbm.NewGoLevelDB(clkeys.KeyDBName, filepath.Join(clientDir(i), "keys"))
+		require.Nil(t, err)
+		kb := client.GetKeyBase(db)
+		for j, acc := range testnetAccounts {
+			info, err := kb.Get(acc.name)
+			require.Nil(t, err, acc.name)
+			genAcc := state.Accounts[i*len(testnetAccounts)+j]
+			require.Equal(t, acc.name, genAcc.Name)
+			require.Equal(t, info.GetAddress(), genAcc.Address)
+			require.Equal(t, acc.price, genAcc.Price)
+			require.Equal(t, testnetMacAddress(i, j), genAcc.MacAddress)
+			require.Equal(t, acc.coins, genAcc.Coins.AmountOf(testnetCoinDenom).Int64())
+		}
+		db.Close()
+		require.True(t, cmn.FileExists(filepath.Join(clientDir(i), "key_seed.json")))
+	}
+
+	// an existing output directory is left alone
+	require.NotNil(t, initTestnet(cfg.DefaultConfig(), cdc))
+	require.True(t, cmn.FileExists(nodeDir(0)))
+}
+
+func TestCalculateIP(t *testing.T) {
+	for _, tc := range []struct {
+		ip   string
+		i    int
+		want string
+	}{
+		{"192.168.0.1", 0, "192.168.0.1"},
+		{"192.168.0.1", 3, "192.168.0.4"},
+		{"192.168.0.254", 2, "192.168.1.0"},
+		{"192.168.0.1", 300, "192.168.1.45"},
+		{"10.255.255.255", 1, "11.0.0.0"},
+	} {
+		ip, err := calculateIP(tc.ip, tc.i)
+		require.Nil(t, err, tc.ip)
+		require.Equal(t, tc.want, ip, tc.ip)
+	}
+
+	_, err := calculateIP("255.255.255.254", 2)
+	require.NotNil(t, err)
+	_, err = calculateIP("::1", 1)
+	require.NotNil(t, err)
+	_, err = calculateIP("node0", 1)
+	require.NotNil(t, err)
+}
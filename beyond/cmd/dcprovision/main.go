@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/vincepg13/bp-sdk/beyond/app"
+	"github.com/vincepg13/bp-sdk/beyond/types"
+	dc "github.com/vincepg13/bp-sdk/deepcover-client"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagCertR     = "cert-r"
+	flagCertS     = "cert-s"
+	flagAuthority = "authority"
+	flagFrom      = "from"
+	flagChainID   = "chain-id"
+	flagPage      = "page"
+	flagOutput    = "output"
+)
+
+// session is the secure element being provisioned
+var session = dc.DefaultSession
+
+var rootCmd = &cobra.Command{
+	Use:   "dcprovision",
+	Short: "Provision DeepCover DS28C36 secure elements for Beyond",
+	Long: `Provision a DeepCover DS28C36 secure element attached to the I2C bus:
+
+  1. dcprovision keygen     generates Key A on the chip and prints the
+                            certificate body for the Verify Authority
+  2. dcprovision certify    writes and locks the certificate signed by the
+                            authority and emits the onboarding tx
+  3. beyondcli sign/broadcast the onboarding tx
+
+dcprovision show prints the device and the protection of its pages.`,
+	SilenceUsage: true,
+}
+
+func main() {
+	types.SetBech32Prefixes()
+	rootCmd.AddCommand(keygenCmd(), certifyCmd(), showCmd())
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func keygenCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "keygen",
+		Short: "Generate Key A on the secure element and write protect it",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info, err := generateKey(context.Background(), session())
+			if err != nil {
+				return err
+			}
+			return printJSON(info)
+		},
+	}
+}
+
+func certifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "certify",
+		Short: "Write and lock the device certificate and emit the onboarding tx",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			s := session()
+
+			r, err := hexFlag(cmd, flagCertR)
+			if err != nil {
+				return err
+			}
+			sig, err := hexFlag(cmd, flagCertS)
+			if err != nil {
+				return err
+			}
+			var authority *ecdsa.PublicKey
+			if hexKey, _ := cmd.Flags().GetString(flagAuthority); hexKey != "" {
+				if authority, err = dc.ParseAuthorityKey(hexKey); err != nil {
+					return err
+				}
+			}
+			fromBech32, _ := cmd.Flags().GetString(flagFrom)
+			from, err := sdk.AccAddressFromBech32(fromBech32)
+			if err != nil {
+				return err
+			}
+
+			cert, err := writeCertificate(ctx, s, r, sig, authority)
+			if err != nil {
+				return err
+			}
+
+			page, _ := cmd.Flags().GetInt(flagPage)
+			chainID, _ := cmd.Flags().GetString(flagChainID)
+			tx, err := onboardingTx(ctx, s, app.MakeCodec(), chainID, from, cert, page)
+			if err != nil {
+				return err
+			}
+
+			info, _, err := readDevice(ctx, s)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Device address: %s\n", info.Address)
+
+			output, _ := cmd.Flags().GetString(flagOutput)
+			if output == "" {
+				fmt.Println(string(tx))
+				return nil
+			}
+			if err := ioutil.WriteFile(output, append(tx, '\n'), 0644); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Onboarding tx written to %s, sign and send it with\n", output)
+			fmt.Fprintf(os.Stderr, "  beyondcli sign %s --name=<key of %s> --chain-id=%s > signed.json\n", output, from, chainID)
+			fmt.Fprintf(os.Stderr, "  beyondcli broadcast signed.json\n")
+			return nil
+		},
+	}
+	cmd.Flags().String(flagCertR, "", "Certificate r signed by the Verify Authority (hex), stored in page 14")
+	cmd.Flags().String(flagCertS, "", "Certificate s signed by the Verify Authority (hex), stored in page 15")
+	cmd.Flags().String(flagAuthority, "", "Verify Authority public key, hex of X followed by Y, checked before writing")
+	cmd.Flags().String(flagFrom, "", "Bech32 address of the account sending the onboarding tx")
+	cmd.Flags().String(flagChainID, "", "Chain the device is onboarded on, the device signs it into the onboarding tx")
+	cmd.Flags().Int(flagPage, 0, "Page the device signs attestations with")
+	cmd.Flags().String(flagOutput, "", "Write the onboarding tx to this file instead of stdout")
+	cmd.MarkFlagRequired(flagCertR)
+	cmd.MarkFlagRequired(flagCertS)
+	cmd.MarkFlagRequired(flagFrom)
+	cmd.MarkFlagRequired(flagChainID)
+
+	return cmd
+}
+
+func showCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Show the secure element and the protection of its key and certificate pages",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			s := session()
+
+			info, _, err := readDevice(ctx, s)
+			if err != nil {
+				return err
+			}
+			if err := printJSON(info); err != nil {
+				return err
+			}
+			for _, page := range append(lockedPages, dc.PagePrivKeyA) {
+				prot, err := s.PageProtection(ctx, page)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("page %2d protection %02X\n", page, prot)
+			}
+			if err := checkProtection(ctx, s); err != nil {
+				fmt.Printf("not provisioned: %v\n", err)
+			}
+			return nil
+		},
+	}
+}
+
+func hexFlag(cmd *cobra.Command, name string) ([]byte, error) {
+	value, _ := cmd.Flags().GetString(name)
+	bz, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("--%s: %v", name, err)
+	}
+	return bz, nil
+}
+
+func printJSON(v interface{}) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
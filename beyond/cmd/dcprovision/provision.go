@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/vincepg13/bp-sdk/beyond/types"
+	dev "github.com/vincepg13/bp-sdk/beyond/x/device"
+	dc "github.com/vincepg13/bp-sdk/deepcover-client"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+// onboardingGas is the gas limit of the generated onboarding tx
+const onboardingGas = 200000
+
+// lockedPages must be write protected on a provisioned device, the key
+// pages by key generation and the certificate pages by writeCertificate
+var lockedPages = []int{dc.PageCertificateR, dc.PageCertificateS, dc.PagePubKeyAX, dc.PagePubKeyAY}
+
+// deviceInfo is what the Verify Authority needs to certify a device
+type deviceInfo struct {
+	RomID   cmn.HexBytes `json:"romId"`
+	ManID   cmn.HexBytes `json:"manId"`
+	PubKey  cmn.HexBytes `json:"pubKey"` // PubKeyAX followed by PubKeyAY
+	Address string       `json:"address"`
+
+	// CertificateBody is the data the authority signs, see dc.DeviceCertificate
+	CertificateBody cmn.HexBytes `json:"certificateBody"`
+}
+
+// readDevice reads the certified fields and the certificate pages
+func readDevice(ctx context.Context, s *dc.Session) (deviceInfo, dc.DeviceCertificate, error) {
+	cert, err := s.ReadCertificate(ctx)
+	if err != nil {
+		return deviceInfo{}, cert, err
+	}
+	if err := dc.ValidateROMID(cert.RomID); err != nil {
+		return deviceInfo{}, cert, err
+	}
+	if _, err := dc.PublicKeyFromBytes(cert.PubKeyA); err != nil {
+		return deviceInfo{}, cert, err
+	}
+	body, err := cert.Body()
+	if err != nil {
+		return deviceInfo{}, cert, err
+	}
+
+	return deviceInfo{
+		RomID:           cert.RomID,
+		ManID:           cert.ManID,
+		PubKey:          cert.PubKeyA,
+		Address:         types.DeviceAddress(cert.PubKeyA).String(),
+		CertificateBody: body,
+	}, cert, nil
+}
+
+// generateKey generates Key A on the device and write protects it
+func generateKey(ctx context.Context, s *dc.Session) (deviceInfo, error) {
+	if err := s.GenerateKeyA(ctx, true); err != nil {
+		return deviceInfo{}, fmt.Errorf("generating key A: %v", err)
+	}
+	info, _, err := readDevice(ctx, s)
+	return info, err
+}
+
+// writeCertificate stores the certificate signed by the Verify Authority
+// and write protects its pages. A certificate not matching authority is
+// rejected before anything is written, authority may be nil.
+func writeCertificate(ctx context.Context, s *dc.Session, r, sig []byte, authority *ecdsa.PublicKey) (dc.DeviceCertificate, error) {
+	_, cert, err := readDevice(ctx, s)
+	if err != nil {
+		return cert, err
+	}
+	if len(r) != dc.PageSize || len(sig) != dc.PageSize {
+		return cert, fmt.Errorf("certificate r and s must be %d bytes each", dc.PageSize)
+	}
+	cert.R, cert.S = r, sig
+	if authority != nil {
+		if _, err := dc.VerifyCertificate(cert, authority); err != nil {
+			return cert, err
+		}
+	}
+
+	pages := []struct {
+		page int
+		data []byte
+	}{
+		{dc.PageCertificateR, r},
+		{dc.PageCertificateS, sig},
+	}
+	for _, p := range pages {
+		if err := s.WritePage(ctx, p.page, p.data); err != nil {
+			return cert, fmt.Errorf("writing page %d: %v", p.page, err)
+		}
+		stored, err := s.ReadPage(ctx, p.page)
+		if err != nil {
+			return cert, err
+		}
+		if !bytes.Equal(stored, p.data) {
+			return cert, fmt.Errorf("page %d reads back %X", p.page, stored)
+		}
+		if err := s.SetPageProtection(ctx, p.page, dc.ProtWP); err != nil {
+			return cert, fmt.Errorf("locking page %d: %v", p.page, err)
+		}
+	}
+
+	return cert, checkProtection(ctx, s)
+}
+
+// checkProtection verifies with getPageProtection that the key and
+// certificate pages are write protected and Private Key A can not be read
+func checkProtection(ctx context.Context, s *dc.Session) error {
+	for _, page := range lockedPages {
+		prot, err := s.PageProtection(ctx, page)
+		if err != nil {
+			return err
+		}
+		if prot&dc.ProtWP == 0 {
+			return fmt.Errorf("page %d is not write protected (protection %02X)", page, prot)
+		}
+	}
+	prot, err := s.PageProtection(ctx, dc.PagePrivKeyA)
+	if err != nil {
+		return err
+	}
+	if prot&dc.ProtRP == 0 {
+		return fmt.Errorf("private key page %d is not read protected (protection %02X)", dc.PagePrivKeyA, prot)
+	}
+	return nil
+}
+
+// onboardingTx returns the unsigned onboardDevice tx sent by from on the
+// chain chainID, ready for `beyondcli sign`. The device signs the
+// onboarding with the given page.
+func onboardingTx(ctx context.Context, s *dc.Session, cdc *codec.Codec, chainID string, from sdk.AccAddress, cert dc.DeviceCertificate, page int) ([]byte, error) {
+	layout, err := s.DigestLayout(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+	challenge := dev.OnboardChallenge(chainID, from, types.DeviceAddress(cert.PubKeyA))
+	signature, err := s.ComputePageSignature(ctx, page, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dev.NewMsgOnboardDevice(from, cert, page, layout.PageData, signature)
+	if err := msg.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	return cdc.MarshalJSON(auth.NewStdTx([]sdk.Msg{msg}, auth.NewStdFee(onboardingGas), nil, ""))
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/vincepg13/bp-sdk/beyond/app"
+	"github.com/vincepg13/bp-sdk/beyond/types"
+	dev "github.com/vincepg13/bp-sdk/beyond/x/device"
+	dc "github.com/vincepg13/bp-sdk/deepcover-client"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+func signBody(t *testing.T, authority *ecdsa.PrivateKey, body []byte) []byte {
+	digest := sha256.Sum256(body)
+	r, s, err := ecdsa.Sign(rand.Reader, authority, digest[:])
+	require.Nil(t, err)
+	return dc.RawSignature(r, s)
+}
+
+func TestProvision(t *testing.T) {
+	ctx := context.Background()
+	romID, _ := hex.DecodeString("4c123456789a0053")
+	backend, err := dc.NewSoftwareBackend(nil, romID, []byte{0, 0})
+	require.Nil(t, err)
+	s := dc.NewSession(backend)
+
+	require.NotNil(t, checkProtection(ctx, s))
+
+	info, err := generateKey(ctx, s)
+	require.Nil(t, err)
+	require.Equal(t, romID, []byte(info.RomID))
+
+	// the locked key can not be replaced
+	require.Equal(t, dc.ErrProtection, s.GenerateKeyA(ctx, true))
+
+	authority, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+
+	// a certificate of another authority is rejected before it is written
+	rogue, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	sig := signBody(t, rogue, info.CertificateBody)
+	_, err = writeCertificate(ctx, s, sig[:32], sig[32:], &authority.PublicKey)
+	require.NotNil(t, err)
+	prot, err := s.PageProtection(ctx, dc.PageCertificateR)
+	require.Nil(t, err)
+	require.Zero(t, prot)
+
+	sig = signBody(t, authority, info.CertificateBody)
+	cert, err := writeCertificate(ctx, s, sig[:32], sig[32:], &authority.PublicKey)
+	require.Nil(t, err)
+	require.Nil(t, checkProtection(ctx, s))
+	require.Equal(t, dc.ErrProtection, s.WritePage(ctx, dc.PageCertificateR, dc.ErasedPage))
+
+	_, err = s.VerifyDevice(ctx, &authority.PublicKey)
+	require.Nil(t, err)
+
+	// the onboarding tx carries the certificate read back from the chip and
+	// is signed by the device for the sender
+	cdc := app.MakeCodec()
+	from := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	bz, err := onboardingTx(ctx, s, cdc, "beyond-chain", from, cert, 0)
+	require.Nil(t, err)
+	var tx auth.StdTx
+	require.Nil(t, cdc.UnmarshalJSON(bz, &tx))
+	msg, ok := tx.GetMsgs()[0].(dev.MsgOnboardDevice)
+	require.True(t, ok)
+	require.Equal(t, from, msg.Sender)
+	require.Equal(t, info.Address, msg.DeviceAddress().String())
+	_, err = dc.VerifyCertificate(msg.Certificate(), &authority.PublicKey)
+	require.Nil(t, err)
+
+	hsmInfo := types.HsmInfo{RomID: msg.RomID, ManID: msg.ManID, Page: msg.Page, PageData: msg.PageData}
+	digest, err := dc.CalcucateMessageDigest(dev.OnboardChallenge("beyond-chain", from, msg.DeviceAddress()), hsmInfo.DigestLayout())
+	require.Nil(t, err)
+	pub, err := dc.PublicKeyFromBytes(msg.PubKey)
+	require.Nil(t, err)
+	require.True(t, dc.VerifySignature(pub, digest, msg.Signature))
+	digest, err = dc.CalcucateMessageDigest(dev.OnboardChallenge("other-chain", from, msg.DeviceAddress()), hsmInfo.DigestLayout())
+	require.Nil(t, err)
+	require.False(t, dc.VerifySignature(pub, digest, msg.Signature))
+}
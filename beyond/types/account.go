@@ -1,8 +1,10 @@
 package types
 
 import (
+	"fmt"
+	"net"
+
 	"github.com/cosmos/cosmos-sdk/codec"
-	ccrypto "github.com/cosmos/cosmos-sdk/crypto/keys"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/auth"
 )
@@ -22,8 +24,8 @@ type AppAccount struct {
 	MacAddress string `json:"macAddress"`
 	/* TODO: use float32 to not loose decimal precision during calculation */
 	/* TODO: Use struct to represent additional fields (unit ...) */
-	ElectricityPrice string          `json:"price"`
-	HsmInfo          ccrypto.HsmInfo `json:"hsmInfo"`
+	ElectricityPrice string  `json:"price"`
+	HsmInfo          HsmInfo `json:"hsmInfo"`
 }
 
 // nolint
@@ -36,12 +38,12 @@ func (acc *AppAccount) SetMacAddress(macAddress string) { acc.MacAddress = macAd
 func (acc AppAccount) GetElectricityPrice() string       { return acc.ElectricityPrice }
 func (acc *AppAccount) SetElectricityPrice(price string) { acc.ElectricityPrice = price }
 
-func (acc AppAccount) GetHsmInfo() ccrypto.HsmInfo         { return acc.HsmInfo }
-func (acc *AppAccount) SetHsmInfo(hsmInfo ccrypto.HsmInfo) { acc.HsmInfo = hsmInfo }
+func (acc AppAccount) GetHsmInfo() HsmInfo         { return acc.HsmInfo }
+func (acc *AppAccount) SetHsmInfo(hsmInfo HsmInfo) { acc.HsmInfo = hsmInfo }
 
 // NewAppAccount returns a reference to a new AppAccount given a name and an
 // auth.BaseAccount.
-func NewAppAccount(name string, macAddress string, electricityPrice string, hsmInfo ccrypto.HsmInfo, baseAcct auth.BaseAccount) *AppAccount {
+func NewAppAccount(name string, macAddress string, electricityPrice string, hsmInfo HsmInfo, baseAcct auth.BaseAccount) *AppAccount {
 	return &AppAccount{BaseAccount: baseAcct, Name: name, MacAddress: macAddress, ElectricityPrice: electricityPrice, HsmInfo: hsmInfo}
 }
 
@@ -65,7 +67,25 @@ func GetAccountDecoder(cdc *codec.Codec) auth.AccountDecoder {
 
 // GenesisState reflects the genesis state of the application.
 type GenesisState struct {
-	Accounts []*GenesisAccount `json:"accounts"`
+	Accounts    []*GenesisAccount `json:"accounts"`
+	Authorities []AuthorityKey    `json:"authorities"`
+	Revokers    []sdk.AccAddress  `json:"revokers"`
+	Revocations []Revocation      `json:"revocations"`
+}
+
+// Validate checks every genesis account and that no address has two of them
+func (gs GenesisState) Validate() error {
+	addresses := map[string]bool{}
+	for _, acc := range gs.Accounts {
+		if err := acc.Validate(); err != nil {
+			return fmt.Errorf("invalid genesis account %s: %v", acc.Address, err)
+		}
+		if addresses[acc.Address.String()] {
+			return fmt.Errorf("genesis account %s already exists", acc.Address)
+		}
+		addresses[acc.Address.String()] = true
+	}
+	return nil
 }
 
 // GenesisAccount reflects a genesis account the application expects in it's
@@ -77,6 +97,7 @@ type GenesisAccount struct {
 
 	Address sdk.AccAddress `json:"address"`
 	Coins   sdk.Coins      `json:"coins"`
+	HsmInfo HsmInfo        `json:"hsmInfo"`
 }
 
 // NewGenesisAccount returns a reference to a new GenesisAccount given an
@@ -89,7 +110,40 @@ func NewGenesisAccount(aa *AppAccount) *GenesisAccount {
 
 		Address: aa.Address,
 		Coins:   aa.Coins.Sort(),
+		HsmInfo: aa.HsmInfo,
+	}
+}
+
+// Validate checks the address and coins of a genesis account, and its MAC
+// address and price when they are set. Both are optional, accounts without
+// a charging device have none. The MAC address is 48 bits long and the price
+// a non negative decimal.
+func (ga *GenesisAccount) Validate() error {
+	if ga.Address.Empty() {
+		return fmt.Errorf("empty address")
+	}
+	if !ga.Coins.IsValid() {
+		return fmt.Errorf("invalid coins %s", ga.Coins)
+	}
+	if ga.MacAddress != "" {
+		mac, err := net.ParseMAC(ga.MacAddress)
+		if err != nil {
+			return err
+		}
+		if len(mac) != 6 {
+			return fmt.Errorf("MAC address %s is not 48 bits long", ga.MacAddress)
+		}
+	}
+	if ga.Price != "" {
+		price, err := sdk.NewDecFromStr(ga.Price)
+		if err != nil {
+			return fmt.Errorf("invalid price %s: %v", ga.Price, err)
+		}
+		if price.LT(sdk.ZeroDec()) {
+			return fmt.Errorf("negative price %s", ga.Price)
+		}
 	}
+	return nil
 }
 
 // ToAppAccount converts a GenesisAccount to an AppAccount.
@@ -98,6 +152,7 @@ func (ga *GenesisAccount) ToAppAccount() (acc *AppAccount, err error) {
 		Name:             ga.Name,
 		MacAddress:       ga.MacAddress,
 		ElectricityPrice: ga.Price,
+		HsmInfo:          ga.HsmInfo,
 
 		BaseAccount: auth.BaseAccount{
 			Address: ga.Address,
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+func genesisAccount(macAddress, price string) *GenesisAccount {
+	return &GenesisAccount{
+		Name:       "station",
+		MacAddress: macAddress,
+		Price:      price,
+		Address:    sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address()),
+		Coins:      sdk.Coins{sdk.NewInt64Coin("byndcoin", 100)},
+	}
+}
+
+func TestGenesisAccountValidate(t *testing.T) {
+	// MAC address and price are optional
+	require.Nil(t, genesisAccount("", "").Validate())
+	require.Nil(t, genesisAccount("60-67-20-C0-E4-59", "2").Validate())
+	require.Nil(t, genesisAccount("60:67:20:c0:e4:59", "0.25").Validate())
+	require.Nil(t, genesisAccount("", "0").Validate())
+
+	for _, mac := range []string{
+		"60-67-20-C0-E4",
+		"60-67-20-C0-E4-59-00-01", // EUI-64
+		"6067.20c0.e459.0001",     // EUI-64
+		"station",
+	} {
+		require.NotNil(t, genesisAccount(mac, "2").Validate(), mac)
+	}
+	for _, price := range []string{"-1", "-0.5", "two", "0x10", "1e3", "1.", "1,5"} {
+		require.NotNil(t, genesisAccount("60-67-20-C0-E4-59", price).Validate(), price)
+	}
+
+	acc := genesisAccount("", "")
+	acc.Address = nil
+	require.NotNil(t, acc.Validate())
+	acc = genesisAccount("", "")
+	acc.Coins = sdk.Coins{sdk.NewInt64Coin("byndcoin", 0)}
+	require.NotNil(t, acc.Validate())
+}
+
+func TestGenesisStateValidate(t *testing.T) {
+	station := genesisAccount("60-67-20-C0-E4-59", "2")
+	car := genesisAccount("00-05-9A-3C-7A-00", "1")
+	require.Nil(t, GenesisState{}.Validate())
+	require.Nil(t, GenesisState{Accounts: []*GenesisAccount{station, car}}.Validate())
+
+	// an address has a single genesis account
+	duplicate := genesisAccount("", "")
+	duplicate.Address = station.Address
+	err := GenesisState{Accounts: []*GenesisAccount{station, car, duplicate}}.Validate()
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "already exists")
+
+	invalid := genesisAccount("00-05-9A-3C-7A-00-00-01", "1")
+	require.NotNil(t, GenesisState{Accounts: []*GenesisAccount{station, invalid}}.Validate())
+}
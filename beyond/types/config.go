@@ -0,0 +1,88 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/tendermint/tendermint/libs/bech32"
+)
+
+// Bech32 prefixes of the Beyond addresses and public keys
+const (
+	Bech32PrefixAccAddr  = "byndaddr"
+	Bech32PrefixAccPub   = "byndpub"
+	Bech32PrefixValAddr  = "byndvaloper"
+	Bech32PrefixValPub   = "byndvaloperpub"
+	Bech32PrefixConsAddr = "byndvalcons"
+	Bech32PrefixConsPub  = "byndvalconspub"
+)
+
+// LegacyBech32Prefixes maps the prefixes of earlier releases and of the
+// SDK defaults to the Beyond prefixes. The validator public key of earlier
+// releases is the consensus key.
+var LegacyBech32Prefixes = map[string]string{
+	"cosmosaccaddr":    Bech32PrefixAccAddr,
+	"cosmosaccpub":     Bech32PrefixAccPub,
+	"cosmosvaladdr":    Bech32PrefixValAddr,
+	"cosmosvalpub":     Bech32PrefixConsPub,
+	"cosmos":           Bech32PrefixAccAddr,
+	"cosmospub":        Bech32PrefixAccPub,
+	"cosmosvaloper":    Bech32PrefixValAddr,
+	"cosmosvaloperpub": Bech32PrefixValPub,
+	"cosmosvalcons":    Bech32PrefixConsAddr,
+	"cosmosvalconspub": Bech32PrefixConsPub,
+}
+
+// SetBech32Prefixes sets the Beyond prefixes in the SDK config and seals
+// it. Binaries call it before they encode or decode any address.
+func SetBech32Prefixes() {
+	config := sdk.GetConfig()
+	config.SetBech32PrefixForAccount(Bech32PrefixAccAddr, Bech32PrefixAccPub)
+	config.SetBech32PrefixForValidator(Bech32PrefixValAddr, Bech32PrefixValPub)
+	config.SetBech32PrefixForConsensusNode(Bech32PrefixConsAddr, Bech32PrefixConsPub)
+	config.Seal()
+}
+
+// legacyBech32 matches the JSON strings holding a bech32 string with a
+// legacy prefix, data characters never include the separator 1
+var legacyBech32 = regexp.MustCompile(`"(` + legacyPrefixes() + `)1([qpzry9x8gf2tvdw0s3jn54khce6mua7l]+)"`)
+
+func legacyPrefixes() string {
+	var prefixes []string
+	for prefix := range LegacyBech32Prefixes {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	return strings.Join(prefixes, "|")
+}
+
+// MigrateBech32 rewrites the legacy bech32 strings of a JSON document, such
+// as a genesis file, with the Beyond prefixes and returns how many it
+// rewrote. The rest of the document is left as it is.
+func MigrateBech32(bz []byte) (migrated []byte, count int, err error) {
+	migrated = legacyBech32.ReplaceAllFunc(bz, func(match []byte) []byte {
+		if err != nil {
+			return match
+		}
+		legacy := string(match[1 : len(match)-1])
+		prefix, data, decodeErr := bech32.DecodeAndConvert(legacy)
+		if decodeErr != nil {
+			err = fmt.Errorf("%s: %v", legacy, decodeErr)
+			return match
+		}
+		s, encodeErr := bech32.ConvertAndEncode(LegacyBech32Prefixes[prefix], data)
+		if encodeErr != nil {
+			err = fmt.Errorf("%s: %v", legacy, encodeErr)
+			return match
+		}
+		count++
+		return []byte(`"` + s + `"`)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return migrated, count, nil
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/bech32"
+)
+
+func mustBech32(t *testing.T, prefix string, bz []byte) string {
+	s, err := bech32.ConvertAndEncode(prefix, bz)
+	require.Nil(t, err)
+	return s
+}
+
+func TestMigrateBech32(t *testing.T) {
+	bz := []byte("01234567890123456789")
+
+	for legacy, prefix := range LegacyBech32Prefixes {
+		doc := fmt.Sprintf(`{"address": %q}`, mustBech32(t, legacy, bz))
+		migrated, count, err := MigrateBech32([]byte(doc))
+		require.Nil(t, err, legacy)
+		require.Equal(t, 1, count, legacy)
+		require.Equal(t, fmt.Sprintf(`{"address": %q}`, mustBech32(t, prefix, bz)), string(migrated), legacy)
+	}
+
+	// cosmos is a prefix of cosmosaccaddr, each keeps its own mapping
+	doc := fmt.Sprintf(`[%q, %q, %q]`,
+		mustBech32(t, "cosmos", bz), mustBech32(t, "cosmosaccaddr", bz), mustBech32(t, "cosmosvalpub", bz))
+	migrated, count, err := MigrateBech32([]byte(doc))
+	require.Nil(t, err)
+	require.Equal(t, 3, count)
+	acc := mustBech32(t, Bech32PrefixAccAddr, bz)
+	require.Equal(t, fmt.Sprintf(`[%q, %q, %q]`, acc, acc, mustBech32(t, Bech32PrefixConsPub, bz)), string(migrated))
+}
+
+func TestMigrateBech32Untouched(t *testing.T) {
+	legacy := mustBech32(t, "cosmosaccaddr", []byte("01234567890123456789"))
+	doc := fmt.Sprintf(`{
+  "chain_id": "cosmos",
+  "name": "cosmos-hub",
+  "memo": "sent by %s",
+  "address": %q,
+  "other": %q
+}`, legacy, mustBech32(t, Bech32PrefixAccAddr, []byte("01234567890123456789")), mustBech32(t, "other", []byte("0123")))
+
+	migrated, count, err := MigrateBech32([]byte(doc))
+	require.Nil(t, err)
+	require.Equal(t, 0, count)
+	require.Equal(t, doc, string(migrated))
+}
+
+func TestMigrateBech32BadChecksum(t *testing.T) {
+	legacy := mustBech32(t, "cosmosaccaddr", []byte("01234567890123456789"))
+	last := legacy[len(legacy)-1]
+	replacement := byte('q')
+	if last == replacement {
+		replacement = 'p'
+	}
+	bad := legacy[:len(legacy)-1] + string(replacement)
+
+	migrated, count, err := MigrateBech32([]byte(fmt.Sprintf(`{"address": %q}`, bad)))
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), bad)
+	require.Nil(t, migrated)
+	require.Equal(t, 0, count)
+}
@@ -0,0 +1,146 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	dc "github.com/vincepg13/bp-sdk/deepcover-client"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// HsmInfo describes the DeepCover secure element holding the key of a
+// mobility account.
+type HsmInfo struct {
+	RomID  []byte `json:"romId"`
+	ManID  []byte `json:"manId"`
+	PubKey []byte `json:"pubKey"` // PubKeyAX followed by PubKeyAY
+
+	// Page and PageData are the page the device signs with and its
+	// content, empty PageData is an erased page
+	Page     int    `json:"page"`
+	PageData []byte `json:"pageData"`
+
+	// Authority is the name of the manufacturer authority whose
+	// certificate was verified when the device was onboarded.
+	Authority string `json:"authority"`
+	Verified  bool   `json:"verified"`
+}
+
+// IsDevice returns true if the account is backed by a verified secure element
+func (info HsmInfo) IsDevice() bool { return info.Verified }
+
+// DigestLayout returns the data the secure element hashes when it signs
+func (info HsmInfo) DigestLayout() dc.DigestLayout {
+	return digestLayout(info.RomID, info.ManID, info.Page, info.PageData)
+}
+
+// digestLayout fills in the defaults of devices registered before the
+// manufacturer ID and the signing page were recorded
+func digestLayout(romID, manID []byte, page int, pageData []byte) dc.DigestLayout {
+	if len(manID) == 0 {
+		manID = []byte{0, 0}
+	}
+	if len(pageData) == 0 {
+		pageData = dc.ErasedPage
+	}
+	return dc.DigestLayout{RomID: romID, ManID: manID, Page: page, PageData: pageData}
+}
+
+// MigrateHsmInfo resets the hsmInfo of the genesis accounts of a genesis
+// file that does not have the layout of HsmInfo, such as the one of the SDK
+// fork's crypto/keys.HsmInfo of earlier releases, and returns how many it
+// reset. None of those was verified against a manufacturer certificate, the
+// devices onboard again with an onboardDevice tx. The other fields of the
+// document are kept, a document that is changed is indented again.
+func MigrateHsmInfo(cdc *codec.Codec, bz []byte) (migrated []byte, count int, err error) {
+	var doc, appState map[string]json.RawMessage
+	if err := json.Unmarshal(bz, &doc); err != nil {
+		return nil, 0, err
+	}
+	if raw, ok := doc["app_state"]; ok {
+		if err := json.Unmarshal(raw, &appState); err != nil {
+			return nil, 0, err
+		}
+	}
+	var accounts []map[string]json.RawMessage
+	if raw, ok := appState["accounts"]; ok {
+		if err := json.Unmarshal(raw, &accounts); err != nil {
+			return nil, 0, err
+		}
+	}
+	empty, err := cdc.MarshalJSON(HsmInfo{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, account := range accounts {
+		info, ok := account["hsmInfo"]
+		if !ok || isHsmInfo(cdc, info) {
+			continue
+		}
+		account["hsmInfo"] = empty
+		count++
+	}
+	if count == 0 {
+		return bz, 0, nil
+	}
+
+	if appState["accounts"], err = json.Marshal(accounts); err != nil {
+		return nil, 0, err
+	}
+	if doc["app_state"], err = json.Marshal(appState); err != nil {
+		return nil, 0, err
+	}
+	if migrated, err = json.MarshalIndent(doc, "", "  "); err != nil {
+		return nil, 0, err
+	}
+	return migrated, count, nil
+}
+
+// isHsmInfo returns true if bz is null or a JSON HsmInfo without unknown
+// fields
+func isHsmInfo(cdc *codec.Codec, bz json.RawMessage) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(bz, &fields); err != nil {
+		return false
+	}
+	known := map[string]bool{}
+	t := reflect.TypeOf(HsmInfo{})
+	for i := 0; i < t.NumField(); i++ {
+		known[strings.Split(t.Field(i).Tag.Get("json"), ",")[0]] = true
+	}
+	for name := range fields {
+		if !known[name] {
+			return false
+		}
+	}
+	var info HsmInfo
+	return cdc.UnmarshalJSON(bz, &info) == nil
+}
+
+// DeviceAddress returns the account address of the secure element owning
+// the given 64 byte public key.
+func DeviceAddress(pubKey []byte) sdk.AccAddress {
+	hash := sha256.Sum256(pubKey)
+	return sdk.AccAddress(hash[:20])
+}
+
+// AuthorityKey is a manufacturer Verify Authority public key trusted to
+// certify secure elements.
+type AuthorityKey struct {
+	Name   string `json:"name"`
+	PubKey string `json:"pubKey"` // hex of X followed by Y
+}
+
+// Revocation is an entry of the device revocation list
+type Revocation struct {
+	RomID   []byte         `json:"romId,omitempty"`
+	PubKey  []byte         `json:"pubKey,omitempty"` // hex of X followed by Y
+	Reason  string         `json:"reason"`
+	Revoker sdk.AccAddress `json:"revoker"`
+	Height  int64          `json:"height"`
+}
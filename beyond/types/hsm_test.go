@@ -0,0 +1,83 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateHsmInfo(t *testing.T) {
+	cdc := codec.New()
+	device := HsmInfo{RomID: []byte{0x4c, 0x12}, PubKey: make([]byte, 64), Authority: "beyond", Verified: true}
+	current, err := cdc.MarshalJSON(device)
+	require.Nil(t, err)
+
+	// the fork's HsmInfo held the key of the secure element, two cars share
+	// it and the station has a field with the same value
+	legacy := `{"name": "deepcover", "pubKey": {"type": "tendermint/PubKeySecp256k1", "value": "A8BI3Wz/4LwYMJzFwXChCJPbExTqXd9W1ZK8K9J/y+Gq"}}`
+	doc := fmt.Sprintf(`{
+  "chain_id": "beyond-chain",
+  "validators": [{"name": "node0", "power": "10"}],
+  "app_state": {
+    "accounts": [
+      {"name": "station", "coins": null, "note": %s},
+      {"name": "device", "hsmInfo": %s},
+      {"name": "car", "hsmInfo": %s},
+      {"name": "car2", "hsmInfo": %s},
+      {"name": "empty", "hsmInfo": null}
+    ],
+    "authorities": []
+  }
+}`, legacy, current, legacy, legacy)
+
+	migrated, count, err := MigrateHsmInfo(cdc, []byte(doc))
+	require.Nil(t, err)
+	require.Equal(t, 2, count)
+
+	var out struct {
+		ChainID    string          `json:"chain_id"`
+		Validators json.RawMessage `json:"validators"`
+		AppState   struct {
+			Accounts    []map[string]json.RawMessage `json:"accounts"`
+			Authorities json.RawMessage              `json:"authorities"`
+		} `json:"app_state"`
+	}
+	require.Nil(t, json.Unmarshal(migrated, &out))
+	require.Equal(t, "beyond-chain", out.ChainID)
+	require.JSONEq(t, `[{"name": "node0", "power": "10"}]`, string(out.Validators))
+	require.JSONEq(t, `[]`, string(out.AppState.Authorities))
+	accounts := out.AppState.Accounts
+	require.Len(t, accounts, 5)
+	require.JSONEq(t, legacy, string(accounts[0]["note"]))
+	_, ok := accounts[0]["hsmInfo"]
+	require.False(t, ok)
+	hsmInfo := func(i int) (info HsmInfo) {
+		require.Nil(t, cdc.UnmarshalJSON(accounts[i]["hsmInfo"], &info))
+		return info
+	}
+	require.Equal(t, device, hsmInfo(1))
+	require.Equal(t, HsmInfo{}, hsmInfo(2))
+	require.Equal(t, HsmInfo{}, hsmInfo(3))
+	require.Equal(t, "null", string(accounts[4]["hsmInfo"]))
+
+	// the result does not depend on the formatting of the document
+	var compact bytes.Buffer
+	require.Nil(t, json.Compact(&compact, []byte(doc)))
+	other, count, err := MigrateHsmInfo(cdc, compact.Bytes())
+	require.Nil(t, err)
+	require.Equal(t, 2, count)
+	require.Equal(t, string(migrated), string(other))
+
+	// a migrated genesis is left as it is
+	again, count, err := MigrateHsmInfo(cdc, migrated)
+	require.Nil(t, err)
+	require.Equal(t, 0, count)
+	require.Equal(t, string(migrated), string(again))
+
+	_, _, err = MigrateHsmInfo(cdc, []byte(`{"app_state": {"accounts": [1]}}`))
+	require.NotNil(t, err)
+}
@@ -0,0 +1,81 @@
+package types
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	dc "github.com/vincepg13/bp-sdk/deepcover-client"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/tendermint/tendermint/crypto"
+)
+
+// PubKeyDeepCoverAminoRoute is the amino route of PubKeyDeepCover
+const PubKeyDeepCoverAminoRoute = "beyond/PubKeyDeepCover"
+
+var pubKeyCdc = codec.New()
+
+func init() {
+	pubKeyCdc.RegisterInterface((*crypto.PubKey)(nil), nil)
+	pubKeyCdc.RegisterConcrete(PubKeyDeepCover{}, PubKeyDeepCoverAminoRoute, nil)
+}
+
+// PubKeyDeepCover is the P-256 public key of a DeepCover secure element.
+// The private key never leaves the chip, transactions are signed with
+// computeReadPageAuthentication over the SHA256 of the sign bytes, so the
+// device data taking part in the message digest is part of the key.
+type PubKeyDeepCover struct {
+	RomID    []byte `json:"romId"`
+	Key      []byte `json:"key"` // PubKeyAX followed by PubKeyAY
+	ManID    []byte `json:"manId"`
+	Page     int    `json:"page"`
+	PageData []byte `json:"pageData"` // empty for an erased page
+}
+
+var _ crypto.PubKey = PubKeyDeepCover{}
+
+// Address is the device address, see DeviceAddress
+func (pubKey PubKeyDeepCover) Address() crypto.Address {
+	return crypto.Address(DeviceAddress(pubKey.Key))
+}
+
+// Bytes returns the amino encoding of the key
+func (pubKey PubKeyDeepCover) Bytes() []byte {
+	return pubKeyCdc.MustMarshalBinaryBare(pubKey)
+}
+
+// VerifyBytes checks a 64 byte R||S signature made by the secure element
+// over msg, S must be in low form
+func (pubKey PubKeyDeepCover) VerifyBytes(msg []byte, sig []byte) bool {
+	pub, err := dc.PublicKeyFromBytes(pubKey.Key)
+	if err != nil {
+		return false
+	}
+	challenge := sha256.Sum256(msg)
+	digest, err := dc.CalcucateMessageDigest(challenge[:], pubKey.DigestLayout())
+	if err != nil {
+		return false
+	}
+	return dc.VerifySignature(pub, digest, sig)
+}
+
+// DigestLayout returns the data the secure element hashes when it signs
+func (pubKey PubKeyDeepCover) DigestLayout() dc.DigestLayout {
+	return digestLayout(pubKey.RomID, pubKey.ManID, pubKey.Page, pubKey.PageData)
+}
+
+// Equals implements crypto.PubKey
+func (pubKey PubKeyDeepCover) Equals(other crypto.PubKey) bool {
+	otherDC, ok := other.(PubKeyDeepCover)
+	if !ok {
+		return false
+	}
+	return bytes.Equal(pubKey.RomID, otherDC.RomID) && bytes.Equal(pubKey.Key, otherDC.Key) &&
+		bytes.Equal(pubKey.ManID, otherDC.ManID) && pubKey.Page == otherDC.Page &&
+		bytes.Equal(pubKey.PageData, otherDC.PageData)
+}
+
+func (pubKey PubKeyDeepCover) String() string {
+	return fmt.Sprintf("PubKeyDeepCover{RomID: %X, Key: %X, Page: %d}", pubKey.RomID, pubKey.Key, pubKey.Page)
+}
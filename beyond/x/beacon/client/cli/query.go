@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/vincepg13/bp-sdk/beyond/x/beacon"
+
+	clictx "github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/codec"
+
+	"github.com/spf13/cobra"
+)
+
+// GetCmdQueryBeacon implements the query randomness beacon command.
+func GetCmdQueryBeacon(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "beacon [epoch]",
+		Short: "Query the randomness beacon of an epoch, the latest one by default",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := clictx.NewCLIContext().WithCodec(cdc)
+
+			params := beacon.QueryBeaconParams{Epoch: -1}
+			if len(args) == 1 {
+				epoch, err := strconv.ParseInt(args[0], 10, 64)
+				if err != nil {
+					return err
+				}
+				params.Epoch = epoch
+			}
+			bz, err := cdc.MarshalJSON(params)
+			if err != nil {
+				return err
+			}
+
+			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", queryRoute, beacon.QueryBeacon), bz)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(res))
+			return nil
+		},
+	}
+}
+
+// GetCmdQueryBeaconEpoch implements the query beacon epoch command.
+func GetCmdQueryBeaconEpoch(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "beaconEpoch",
+		Short: "Query the current beacon epoch and its commit and reveal deadlines",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := clictx.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", queryRoute, beacon.QueryEpoch), nil)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(res))
+			return nil
+		},
+	}
+}
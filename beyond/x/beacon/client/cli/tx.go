@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vincepg13/bp-sdk/beyond/client/hsm"
+	"github.com/vincepg13/bp-sdk/beyond/x/beacon"
+	dc "github.com/vincepg13/bp-sdk/deepcover-client"
+
+	clictx "github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authcmd "github.com/cosmos/cosmos-sdk/x/auth/client/cli"
+	authtxb "github.com/cosmos/cosmos-sdk/x/auth/client/txbuilder"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/libs/cli"
+	"github.com/tendermint/tendermint/privval"
+)
+
+const (
+	flagEpoch             = "epoch"
+	flagPrivValidatorFile = "priv-validator-file"
+)
+
+// CommitRandomnessTxCmd reads a random value from the hardware RNG of the
+// secure element, keeps it for the reveal and commits to it.
+func CommitRandomnessTxCmd(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "commitRandomness",
+		Short: "Commit to a value from the hardware RNG of the secure element for the current beacon epoch",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := authtxb.NewTxBuilderFromCLI().WithCodec(cdc)
+			cliCtx := hsm.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(authcmd.GetAccountDecoder(cdc))
+
+			if err := hsm.EnsureAccountExists(cliCtx); err != nil {
+				return err
+			}
+
+			from, err := hsm.GetFromAddress(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			epoch, err := queryEpoch(cliCtx, cdc)
+			if err != nil {
+				return err
+			}
+			if !epoch.CommitPhase {
+				return fmt.Errorf("epoch %d is in its reveal phase, commit after height %d", epoch.Epoch, epoch.RevealDeadline)
+			}
+
+			value, err := dc.DefaultSession().RandomBytes(context.Background(), beacon.ValueSize)
+			if err != nil {
+				return err
+			}
+			// the value is needed for the reveal, never overwrite a
+			// value that may have been committed already
+			path := valuePath(from, epoch.Epoch)
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+			if err != nil {
+				return err
+			}
+			_, err = f.WriteString(hex.EncodeToString(value))
+			if err2 := f.Close(); err == nil {
+				err = err2
+			}
+			if err != nil {
+				return err
+			}
+
+			msg := beacon.NewMsgCommitRandomness(from, epoch.Epoch, beacon.Commitment(value, from))
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return hsm.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
+
+// RevealRandomnessTxCmd reveals the value committed to by commitRandomness.
+func RevealRandomnessTxCmd(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revealRandomness",
+		Short: "Reveal the value committed to in the current beacon epoch",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := authtxb.NewTxBuilderFromCLI().WithCodec(cdc)
+			cliCtx := hsm.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(authcmd.GetAccountDecoder(cdc))
+
+			if err := hsm.EnsureAccountExists(cliCtx); err != nil {
+				return err
+			}
+
+			from, err := hsm.GetFromAddress(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			epoch := viper.GetInt64(flagEpoch)
+			if epoch < 0 {
+				current, err := queryEpoch(cliCtx, cdc)
+				if err != nil {
+					return err
+				}
+				if current.CommitPhase {
+					return fmt.Errorf("epoch %d is in its commit phase, reveal after height %d", current.Epoch, current.CommitDeadline)
+				}
+				epoch = current.Epoch
+			}
+
+			bz, err := ioutil.ReadFile(valuePath(from, epoch))
+			if err != nil {
+				return err
+			}
+			value, err := hex.DecodeString(strings.TrimSpace(string(bz)))
+			if err != nil {
+				return err
+			}
+
+			msg := beacon.NewMsgRevealRandomness(from, epoch, value)
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return hsm.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().Int64(flagEpoch, -1, "Epoch to reveal, defaults to the current one")
+
+	return cmd
+}
+
+// RegisterValidatorTxCmd signs the account given with --from with the
+// consensus key of a validator, which then takes part in the beacon with it.
+func RegisterValidatorTxCmd(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "registerValidator",
+		Short: "Take part in the beacon with the --from account while the validator of --priv-validator-file is bonded",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := authtxb.NewTxBuilderFromCLI().WithCodec(cdc)
+			cliCtx := hsm.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(authcmd.GetAccountDecoder(cdc))
+
+			if err := hsm.EnsureAccountExists(cliCtx); err != nil {
+				return err
+			}
+
+			from, err := hsm.GetFromAddress(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			pv := privval.LoadFilePV(viper.GetString(flagPrivValidatorFile))
+			signature, err := pv.PrivKey.Sign(beacon.RegistrationBytes(txBldr.ChainID, from))
+			if err != nil {
+				return err
+			}
+
+			msg := beacon.NewMsgRegisterValidator(from, pv.GetPubKey(), signature)
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return hsm.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().String(flagPrivValidatorFile, "", "priv_validator.json of the validator, in the config directory of beyondd")
+	cmd.MarkFlagRequired(flagPrivValidatorFile)
+
+	return cmd
+}
+
+// valuePath is where commitRandomness keeps the value until it is revealed
+func valuePath(from sdk.AccAddress, epoch int64) string {
+	return filepath.Join(viper.GetString(cli.HomeFlag), "beacon", fmt.Sprintf("%s-%d.hex", from, epoch))
+}
+
+func queryEpoch(cliCtx clictx.CLIContext, cdc *codec.Codec) (epoch beacon.QueryEpochResult, err error) {
+	res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/beacon/%s", beacon.QueryEpoch), nil)
+	if err != nil {
+		return epoch, err
+	}
+	err = cdc.UnmarshalJSON(res, &epoch)
+	return epoch, err
+}
@@ -0,0 +1,48 @@
+package beacon
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Beacon errors reserve 700 ~ 799.
+const (
+	DefaultCodespace        sdk.CodespaceType = 7
+	CodeNotParticipant      sdk.CodeType      = 700
+	CodeWrongPhase          sdk.CodeType      = 701
+	CodeAlreadyCommitted    sdk.CodeType      = 702
+	CodeNoCommitment        sdk.CodeType      = 703
+	CodeInvalidReveal       sdk.CodeType      = 704
+	CodeInvalidRegistration sdk.CodeType      = 705
+	CodeExcluded            sdk.CodeType      = 706
+)
+
+// ErrNotParticipant
+func ErrNotParticipant(codespace sdk.CodespaceType, addr sdk.AccAddress) sdk.Error {
+	return sdk.NewError(codespace, CodeNotParticipant, fmt.Sprintf("Account %s is neither a bonded validator nor an onboarded device with a recent attestation", addr))
+}
+
+func ErrWrongPhase(codespace sdk.CodespaceType, epoch int64, phase string) sdk.Error {
+	return sdk.NewError(codespace, CodeWrongPhase, fmt.Sprintf("Epoch %d is not in its %s phase", epoch, phase))
+}
+
+func ErrAlreadyCommitted(codespace sdk.CodespaceType, addr sdk.AccAddress, epoch int64) sdk.Error {
+	return sdk.NewError(codespace, CodeAlreadyCommitted, fmt.Sprintf("Account %s already committed in epoch %d", addr, epoch))
+}
+
+func ErrNoCommitment(codespace sdk.CodespaceType, addr sdk.AccAddress, epoch int64) sdk.Error {
+	return sdk.NewError(codespace, CodeNoCommitment, fmt.Sprintf("Account %s has no commitment in epoch %d", addr, epoch))
+}
+
+func ErrInvalidReveal(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidReveal, msg)
+}
+
+func ErrInvalidRegistration(codespace sdk.CodespaceType, validator sdk.ConsAddress) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidRegistration, fmt.Sprintf("Registration is not signed by the consensus key of validator %s", validator))
+}
+
+func ErrExcluded(codespace sdk.CodespaceType, addr sdk.AccAddress, epoch int64) sdk.Error {
+	return sdk.NewError(codespace, CodeExcluded, fmt.Sprintf("Account %s did not reveal its value and is excluded until epoch %d", addr, epoch))
+}
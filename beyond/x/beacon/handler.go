@@ -0,0 +1,147 @@
+package beacon
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/vincepg13/bp-sdk/beyond/x/beacon/tags"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// NewHandler
+func NewHandler(k Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case MsgCommitRandomness:
+			return handleMsgCommitRandomness(ctx, k, msg)
+		case MsgRevealRandomness:
+			return handleMsgRevealRandomness(ctx, k, msg)
+		case MsgRegisterValidator:
+			return handleMsgRegisterValidator(ctx, k, msg)
+		default:
+			errMsg := fmt.Sprintf("Unrecognized Msg type: %v", reflect.TypeOf(msg).Name())
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+func handleMsgCommitRandomness(ctx sdk.Context, k Keeper, msg MsgCommitRandomness) sdk.Result {
+
+	if !k.IsParticipant(ctx, msg.Participant) {
+		return ErrNotParticipant(k.codespace, msg.Participant).Result()
+	}
+	if msg.Epoch != Epoch(ctx.BlockHeight()) || !InCommitPhase(ctx.BlockHeight()) {
+		return ErrWrongPhase(k.codespace, msg.Epoch, "commit").Result()
+	}
+	if until := k.GetExcludedUntil(ctx, msg.Participant); msg.Epoch < until {
+		return ErrExcluded(k.codespace, msg.Participant, until).Result()
+	}
+	if k.GetCommitment(ctx, msg.Epoch, msg.Participant) != nil {
+		return ErrAlreadyCommitted(k.codespace, msg.Participant, msg.Epoch).Result()
+	}
+
+	k.SetCommitment(ctx, msg.Epoch, msg.Participant, msg.Commitment)
+
+	resTags := sdk.NewTags(
+		tags.Action, tags.ActionCommitRandomness,
+		tags.Participant, []byte(msg.Participant.String()),
+		tags.Epoch, []byte(strconv.FormatInt(msg.Epoch, 10)),
+	)
+
+	return sdk.Result{
+		Code: sdk.ABCICodeOK,
+		Tags: resTags,
+	}
+}
+
+func handleMsgRevealRandomness(ctx sdk.Context, k Keeper, msg MsgRevealRandomness) sdk.Result {
+
+	if msg.Epoch != Epoch(ctx.BlockHeight()) || InCommitPhase(ctx.BlockHeight()) {
+		return ErrWrongPhase(k.codespace, msg.Epoch, "reveal").Result()
+	}
+	commitment := k.GetCommitment(ctx, msg.Epoch, msg.Participant)
+	if commitment == nil {
+		return ErrNoCommitment(k.codespace, msg.Participant, msg.Epoch).Result()
+	}
+	if k.HasReveal(ctx, msg.Epoch, msg.Participant) {
+		return ErrInvalidReveal(k.codespace, "Value has already been revealed").Result()
+	}
+	if !bytes.Equal(Commitment(msg.Value, msg.Participant), commitment) {
+		return ErrInvalidReveal(k.codespace, "Value does not match the commitment").Result()
+	}
+
+	k.SetReveal(ctx, msg.Epoch, msg.Participant, msg.Value)
+
+	resTags := sdk.NewTags(
+		tags.Action, tags.ActionRevealRandomness,
+		tags.Participant, []byte(msg.Participant.String()),
+		tags.Epoch, []byte(strconv.FormatInt(msg.Epoch, 10)),
+	)
+
+	return sdk.Result{
+		Code: sdk.ABCICodeOK,
+		Tags: resTags,
+	}
+}
+
+func handleMsgRegisterValidator(ctx sdk.Context, k Keeper, msg MsgRegisterValidator) sdk.Result {
+
+	// only the validator holds its consensus key, registering a key that is
+	// not bonded does nothing until it is
+	validator := msg.Validator()
+	if !msg.PubKey.VerifyBytes(RegistrationBytes(ctx.ChainID(), msg.Operator), msg.Signature) {
+		return ErrInvalidRegistration(k.codespace, validator).Result()
+	}
+
+	k.SetOperator(ctx, validator, msg.Operator)
+
+	resTags := sdk.NewTags(
+		tags.Action, tags.ActionRegisterValidator,
+		tags.Operator, []byte(msg.Operator.String()),
+		tags.Validator, []byte(validator.String()),
+	)
+
+	return sdk.Result{
+		Code: sdk.ABCICodeOK,
+		Tags: resTags,
+	}
+}
+
+// BeginBlocker keeps the operator accounts of the validators of the last
+// commit as the bonded validators. Validators that did not register an
+// operator take no part. The first block has no last commit and keeps none.
+func BeginBlocker(ctx sdk.Context, req abci.RequestBeginBlock, k Keeper) {
+	votes := req.LastCommitInfo.Votes
+	if len(votes) == 0 {
+		return
+	}
+	validators := make([]sdk.AccAddress, 0, len(votes))
+	for _, vote := range votes {
+		if vote.Validator.Power <= 0 {
+			continue
+		}
+		if operator := k.GetOperator(ctx, sdk.ConsAddress(vote.Validator.Address)); operator != nil {
+			validators = append(validators, operator)
+		}
+	}
+	k.SetValidators(ctx, validators)
+}
+
+// EndBlocker computes the beacon in the last block of every epoch
+func EndBlocker(ctx sdk.Context, k Keeper) sdk.Tags {
+	height := ctx.BlockHeight()
+	if height < 1 || height%EpochLength != 0 {
+		return nil
+	}
+
+	beacon := k.ComputeBeacon(ctx, Epoch(height))
+	return sdk.NewTags(
+		tags.Action, tags.ActionBeacon,
+		tags.Epoch, []byte(strconv.FormatInt(beacon.Epoch, 10)),
+		tags.Beacon, []byte(fmt.Sprintf("%X", beacon.Value)),
+	)
+}
@@ -0,0 +1,255 @@
+package beacon
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	dev "github.com/vincepg13/bp-sdk/beyond/x/device"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// MaxAttestationAge is the age in blocks of the latest attestation a device
+// needs to commit randomness
+const MaxAttestationAge = dev.ChallengeWindow
+
+// Keeper
+type Keeper struct {
+	dk        dev.Keeper
+	storeKey  sdk.StoreKey // The (unexposed) key used to access the store from the Context.
+	cdc       *codec.Codec
+	codespace sdk.CodespaceType
+}
+
+func NewKeeper(key sdk.StoreKey, deviceKeeper dev.Keeper, codespace sdk.CodespaceType) Keeper {
+	cdc := codec.New()
+	codec.RegisterCrypto(cdc)
+	return Keeper{
+		storeKey:  key,
+		cdc:       cdc,
+		dk:        deviceKeeper,
+		codespace: codespace,
+	}
+}
+
+// IsParticipant returns true if the account is the operator of a bonded
+// validator, or an onboarded device that attested recently
+func (k Keeper) IsParticipant(ctx sdk.Context, addr sdk.AccAddress) bool {
+	if k.IsValidator(ctx, addr) {
+		return true
+	}
+	if _, ok := k.dk.GetHsmInfo(ctx, addr); !ok {
+		return false
+	}
+	return k.dk.HasFreshAttestation(ctx, addr, MaxAttestationAge)
+}
+
+// SetOperator stores the account a validator takes part in the beacon with
+func (k Keeper) SetOperator(ctx sdk.Context, validator sdk.ConsAddress, operator sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(KeyOperator(validator), operator)
+}
+
+// GetOperator returns the account registered by a validator, nil if it
+// did not register one
+func (k Keeper) GetOperator(ctx sdk.Context, validator sdk.ConsAddress) sdk.AccAddress {
+	store := ctx.KVStore(k.storeKey)
+	return store.Get(KeyOperator(validator))
+}
+
+// SetValidators stores the operator accounts of the bonded validators
+func (k Keeper) SetValidators(ctx sdk.Context, validators []sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	bz, err := k.cdc.MarshalBinaryLengthPrefixed(validators)
+	if err != nil {
+		panic(err)
+	}
+	store.Set(KeyValidators, bz)
+}
+
+// GetValidators returns the operator accounts of the bonded validators
+func (k Keeper) GetValidators(ctx sdk.Context) (validators []sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(KeyValidators)
+	if bz == nil {
+		return nil
+	}
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &validators)
+	return validators
+}
+
+// IsValidator returns true if the account is the operator of a bonded
+// validator
+func (k Keeper) IsValidator(ctx sdk.Context, addr sdk.AccAddress) bool {
+	for _, validator := range k.GetValidators(ctx) {
+		if validator.Equals(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetCommitment stores the commitment of a participant for an epoch
+func (k Keeper) SetCommitment(ctx sdk.Context, epoch int64, addr sdk.AccAddress, commitment []byte) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(KeyCommitment(epoch, addr), commitment)
+}
+
+// GetCommitment returns the commitment of a participant for an epoch
+func (k Keeper) GetCommitment(ctx sdk.Context, epoch int64, addr sdk.AccAddress) []byte {
+	store := ctx.KVStore(k.storeKey)
+	return store.Get(KeyCommitment(epoch, addr))
+}
+
+// SetReveal stores the value revealed by a participant for an epoch
+func (k Keeper) SetReveal(ctx sdk.Context, epoch int64, addr sdk.AccAddress, value []byte) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(KeyReveal(epoch, addr), value)
+}
+
+// HasReveal returns true if the participant revealed its value
+func (k Keeper) HasReveal(ctx sdk.Context, epoch int64, addr sdk.AccAddress) bool {
+	store := ctx.KVStore(k.storeKey)
+	return store.Has(KeyReveal(epoch, addr))
+}
+
+// SetExcludedUntil excludes a participant from committing before an epoch
+func (k Keeper) SetExcludedUntil(ctx sdk.Context, addr sdk.AccAddress, epoch int64) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(KeyExcluded(addr), epochBytes(epoch))
+}
+
+// GetExcludedUntil returns the first epoch a participant may commit in
+// again, 0 if it was never excluded
+func (k Keeper) GetExcludedUntil(ctx sdk.Context, addr sdk.AccAddress) int64 {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(KeyExcluded(addr))
+	if bz == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(bz))
+}
+
+// SetBeacon stores the beacon of an epoch and makes it the latest one
+func (k Keeper) SetBeacon(ctx sdk.Context, beacon Beacon) {
+	store := ctx.KVStore(k.storeKey)
+	bz, err := k.cdc.MarshalBinaryLengthPrefixed(beacon)
+	if err != nil {
+		panic(err)
+	}
+	store.Set(KeyBeacon(beacon.Epoch), bz)
+	store.Set(KeyLatestBeacon, epochBytes(beacon.Epoch))
+}
+
+// GetBeacon returns the beacon of an epoch
+func (k Keeper) GetBeacon(ctx sdk.Context, epoch int64) (beacon Beacon, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(KeyBeacon(epoch))
+	if bz == nil {
+		return beacon, false
+	}
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &beacon)
+	return beacon, true
+}
+
+// GetLatestBeacon returns the beacon of the last finished epoch
+func (k Keeper) GetLatestBeacon(ctx sdk.Context) (beacon Beacon, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(KeyLatestBeacon)
+	if bz == nil {
+		return beacon, false
+	}
+	return k.GetBeacon(ctx, int64(binary.BigEndian.Uint64(bz)))
+}
+
+// ComputeBeacon combines the values revealed in an epoch with the previous
+// beacon, stores the result and drops the commitments and reveals:
+//
+//	SHA256(previous beacon || epoch || address || value || ...)
+//
+// Reveals are taken in address order. Participants that committed but did
+// not reveal are listed in Withholders and excluded from the next
+// WithholdingPenalty epochs, see EpochLength.
+func (k Keeper) ComputeBeacon(ctx sdk.Context, epoch int64) Beacon {
+	store := ctx.KVStore(k.storeKey)
+	beacon := Beacon{Epoch: epoch, Height: ctx.BlockHeight()}
+
+	h := sha256.New()
+	if prev, found := k.GetLatestBeacon(ctx); found {
+		h.Write(prev.Value)
+	}
+	h.Write(epochBytes(epoch))
+
+	var done [][]byte
+	prefix := KeyReveal(epoch, nil)
+	iter := sdk.KVStorePrefixIterator(store, prefix)
+	for ; iter.Valid(); iter.Next() {
+		h.Write(iter.Key()[len(prefix):])
+		h.Write(iter.Value())
+		beacon.Contributors++
+		done = append(done, iter.Key())
+	}
+	iter.Close()
+
+	prefix = KeyCommitment(epoch, nil)
+	iter = sdk.KVStorePrefixIterator(store, prefix)
+	for ; iter.Valid(); iter.Next() {
+		beacon.Commitments++
+		addr := sdk.AccAddress(iter.Key()[len(prefix):])
+		if !store.Has(KeyReveal(epoch, addr)) {
+			beacon.Withholders = append(beacon.Withholders, addr)
+		}
+		done = append(done, iter.Key())
+	}
+	iter.Close()
+
+	for _, key := range done {
+		store.Delete(key)
+	}
+	for _, addr := range beacon.Withholders {
+		k.SetExcludedUntil(ctx, addr, epoch+1+WithholdingPenalty)
+	}
+
+	beacon.Value = h.Sum(nil)
+	k.SetBeacon(ctx, beacon)
+	return beacon
+}
+
+// Keeper keys
+
+var (
+	ByteKeyCommitment = []byte("commitment:")
+	ByteKeyReveal     = []byte("reveal:")
+	ByteKeyBeacon     = []byte("beacon:")
+	KeyLatestBeacon   = []byte("latestBeacon")
+	KeyValidators     = []byte("validators")
+	ByteKeyOperator   = []byte("operator:")
+	ByteKeyExcluded   = []byte("excluded:")
+)
+
+func epochBytes(epoch int64) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, uint64(epoch))
+	return bz
+}
+
+func KeyCommitment(epoch int64, addr sdk.AccAddress) []byte {
+	return append(append(append([]byte{}, ByteKeyCommitment...), epochBytes(epoch)...), addr...)
+}
+
+func KeyReveal(epoch int64, addr sdk.AccAddress) []byte {
+	return append(append(append([]byte{}, ByteKeyReveal...), epochBytes(epoch)...), addr...)
+}
+
+func KeyBeacon(epoch int64) []byte {
+	return append(append([]byte{}, ByteKeyBeacon...), epochBytes(epoch)...)
+}
+
+func KeyOperator(validator sdk.ConsAddress) []byte {
+	return append(append([]byte{}, ByteKeyOperator...), validator...)
+}
+
+func KeyExcluded(addr sdk.AccAddress) []byte {
+	return append(append([]byte{}, ByteKeyExcluded...), addr...)
+}
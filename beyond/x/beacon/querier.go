@@ -0,0 +1,84 @@
+package beacon
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// query endpoints supported by the beacon Querier
+const (
+	QueryBeacon = "beacon"
+	QueryEpoch  = "epoch"
+)
+
+func NewQuerier(keeper Keeper) sdk.Querier {
+	return func(ctx sdk.Context, path []string, req abci.RequestQuery) (res []byte, err sdk.Error) {
+		switch path[0] {
+		case QueryBeacon:
+			return queryBeacon(ctx, req, keeper)
+		case QueryEpoch:
+			return queryEpoch(ctx, keeper)
+		default:
+			return nil, sdk.ErrUnknownRequest("unknown beacon query endpoint")
+		}
+	}
+}
+
+// Params for query 'custom/beacon/beacon', a negative Epoch asks for the
+// latest beacon
+type QueryBeaconParams struct {
+	Epoch int64
+}
+
+// QueryEpochResult describes the epoch of the next block
+type QueryEpochResult struct {
+	Epoch          int64 `json:"epoch"`
+	CommitPhase    bool  `json:"commitPhase"`
+	CommitDeadline int64 `json:"commitDeadline"` // last height accepting commitments
+	RevealDeadline int64 `json:"revealDeadline"` // last height accepting reveals
+}
+
+func queryBeacon(ctx sdk.Context, req abci.RequestQuery, keeper Keeper) (res []byte, err sdk.Error) {
+	var params QueryBeaconParams
+	if err2 := keeper.cdc.UnmarshalJSON(req.Data, &params); err2 != nil {
+		return nil, sdk.ErrUnknownRequest(sdk.AppendMsgToErr("incorrectly formatted request data", err2.Error()))
+	}
+
+	var (
+		beacon Beacon
+		found  bool
+	)
+	if params.Epoch < 0 {
+		beacon, found = keeper.GetLatestBeacon(ctx)
+	} else {
+		beacon, found = keeper.GetBeacon(ctx, params.Epoch)
+	}
+	if !found {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("no beacon for epoch %d", params.Epoch))
+	}
+	return marshalJSON(keeper.cdc, beacon)
+}
+
+func queryEpoch(ctx sdk.Context, keeper Keeper) (res []byte, err sdk.Error) {
+	// queries run against the last committed block, transactions sent now
+	// are included in the next one
+	height := ctx.BlockHeight() + 1
+	epoch := Epoch(height)
+	return marshalJSON(keeper.cdc, QueryEpochResult{
+		Epoch:          epoch,
+		CommitPhase:    InCommitPhase(height),
+		CommitDeadline: EpochStart(epoch) + CommitPhaseLength - 1,
+		RevealDeadline: EpochStart(epoch) + EpochLength - 1,
+	})
+}
+
+func marshalJSON(cdc *codec.Codec, o interface{}) (res []byte, err sdk.Error) {
+	bz, err2 := codec.MarshalJSONIndent(cdc, o)
+	if err2 != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not marshal result to JSON", err2.Error()))
+	}
+	return bz, nil
+}
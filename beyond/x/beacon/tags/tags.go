@@ -0,0 +1,20 @@
+// nolint
+package tags
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var (
+	ActionCommitRandomness  = []byte("commitRandomness")
+	ActionRevealRandomness  = []byte("revealRandomness")
+	ActionRegisterValidator = []byte("registerValidator")
+	ActionBeacon            = []byte("beacon")
+
+	Action      = sdk.TagAction
+	Participant = "participant"
+	Epoch       = "epoch"
+	Beacon      = "beacon"
+	Operator    = "operator"
+	Validator   = "validator"
+)
@@ -0,0 +1,247 @@
+package beacon
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/tendermint/tendermint/crypto"
+)
+
+// Epochs are EpochLength blocks long. Commitments are accepted during the
+// first CommitPhaseLength blocks of an epoch, reveals during the rest, and
+// the beacon of the epoch is computed in the EndBlocker of its last block.
+//
+// The beacon mixes only the values that are revealed. A participant that
+// sees the other reveals before the end of the epoch can still choose
+// between the beacon with and without its own value, and k colluding
+// participants between 2^k outputs. A participant that commits but does not
+// reveal is therefore excluded from the next WithholdingPenalty epochs,
+// which makes each such choice cost it the epochs it would contribute to,
+// but the bias is not removed. Users of the beacon must not rely on it
+// against participants able to give up their place.
+const (
+	EpochLength       = 20
+	CommitPhaseLength = 10
+
+	// WithholdingPenalty is the number of epochs a participant that did not
+	// reveal its committed value may not commit in
+	WithholdingPenalty = 10
+
+	// ValueSize is the size of a random value and of its commitment
+	ValueSize = 32
+)
+
+// Epoch returns the epoch a block height belongs to, heights start at 1
+func Epoch(height int64) int64 {
+	return (height - 1) / EpochLength
+}
+
+// EpochStart returns the first height of an epoch
+func EpochStart(epoch int64) int64 {
+	return epoch*EpochLength + 1
+}
+
+// InCommitPhase returns true if commitments are accepted at the height
+func InCommitPhase(height int64) bool {
+	return height-EpochStart(Epoch(height)) < CommitPhaseLength
+}
+
+// Commitment binds a random value to the participant revealing it, so a
+// commitment can not be copied by another participant
+func Commitment(value []byte, participant sdk.AccAddress) []byte {
+	h := sha256.New()
+	h.Write(value)
+	h.Write(participant)
+	return h.Sum(nil)
+}
+
+// MsgCommitRandomness commits to a random value read from the hardware RNG
+// of the participant's secure element.
+type MsgCommitRandomness struct {
+	Participant sdk.AccAddress
+	Epoch       int64
+	Commitment  []byte // see Commitment
+}
+
+// Construct new NewMsgCommitRandomness.
+func NewMsgCommitRandomness(participant sdk.AccAddress, epoch int64, commitment []byte) MsgCommitRandomness {
+	return MsgCommitRandomness{
+		Participant: participant,
+		Epoch:       epoch,
+		Commitment:  commitment,
+	}
+}
+
+// enforce the msg type at compile time
+var _ sdk.Msg = MsgCommitRandomness{}
+
+//nolint
+func (msg MsgCommitRandomness) Type() string  { return "commitRandomness" }
+func (msg MsgCommitRandomness) Route() string { return "beacon" }
+func (msg MsgCommitRandomness) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Participant}
+}
+func (msg MsgCommitRandomness) String() string {
+	return fmt.Sprintf("MsgCommitRandomness{Participant: %v, Epoch: %v, Commitment: %X}", msg.Participant, msg.Epoch, msg.Commitment)
+}
+
+// validate MsgCommitRandomness
+func (msg MsgCommitRandomness) ValidateBasic() sdk.Error {
+	if len(msg.Participant) == 0 {
+		return sdk.ErrUnknownAddress(msg.Participant.String()).TraceSDK("")
+	}
+	if msg.Epoch < 0 {
+		return sdk.ErrUnknownRequest("Epoch must not be negative")
+	}
+	if len(msg.Commitment) != ValueSize {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("Commitment must be %d bytes", ValueSize))
+	}
+	return nil
+}
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg MsgCommitRandomness) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+//_______________________________________________________________________
+
+// MsgRevealRandomness reveals the value committed to earlier in the epoch.
+type MsgRevealRandomness struct {
+	Participant sdk.AccAddress
+	Epoch       int64
+	Value       []byte
+}
+
+// Construct new NewMsgRevealRandomness.
+func NewMsgRevealRandomness(participant sdk.AccAddress, epoch int64, value []byte) MsgRevealRandomness {
+	return MsgRevealRandomness{
+		Participant: participant,
+		Epoch:       epoch,
+		Value:       value,
+	}
+}
+
+var _ sdk.Msg = MsgRevealRandomness{}
+
+//nolint
+func (msg MsgRevealRandomness) Type() string  { return "revealRandomness" }
+func (msg MsgRevealRandomness) Route() string { return "beacon" }
+func (msg MsgRevealRandomness) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Participant}
+}
+func (msg MsgRevealRandomness) String() string {
+	return fmt.Sprintf("MsgRevealRandomness{Participant: %v, Epoch: %v}", msg.Participant, msg.Epoch)
+}
+
+// validate MsgRevealRandomness
+func (msg MsgRevealRandomness) ValidateBasic() sdk.Error {
+	if len(msg.Participant) == 0 {
+		return sdk.ErrUnknownAddress(msg.Participant.String()).TraceSDK("")
+	}
+	if msg.Epoch < 0 {
+		return sdk.ErrUnknownRequest("Epoch must not be negative")
+	}
+	if len(msg.Value) != ValueSize {
+		return ErrInvalidReveal(DefaultCodespace, fmt.Sprintf("Value must be %d bytes", ValueSize))
+	}
+	return nil
+}
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg MsgRevealRandomness) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// RegistrationBytes returns the bytes the consensus key of a validator signs
+// to take part in the beacon with the operator account on the chain chainID
+func RegistrationBytes(chainID string, operator sdk.AccAddress) []byte {
+	bz := append([]byte("beacon/registerValidator:"), chainID...)
+	return append(append(bz, 0), operator...)
+}
+
+// MsgRegisterValidator names the account a validator commits and reveals
+// with. Tendermint only reports the consensus address of the validators of
+// the last commit, so the consensus key signs the operator account.
+type MsgRegisterValidator struct {
+	Operator  sdk.AccAddress
+	PubKey    crypto.PubKey // consensus key of the validator
+	Signature []byte        // signature of the RegistrationBytes by PubKey
+}
+
+// Construct new NewMsgRegisterValidator.
+func NewMsgRegisterValidator(operator sdk.AccAddress, pubKey crypto.PubKey, signature []byte) MsgRegisterValidator {
+	return MsgRegisterValidator{
+		Operator:  operator,
+		PubKey:    pubKey,
+		Signature: signature,
+	}
+}
+
+var _ sdk.Msg = MsgRegisterValidator{}
+
+//nolint
+func (msg MsgRegisterValidator) Type() string  { return "registerValidator" }
+func (msg MsgRegisterValidator) Route() string { return "beacon" }
+func (msg MsgRegisterValidator) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Operator}
+}
+func (msg MsgRegisterValidator) String() string {
+	return fmt.Sprintf("MsgRegisterValidator{Operator: %v, Validator: %v}", msg.Operator, msg.Validator())
+}
+
+// Validator returns the consensus address of the registered validator
+func (msg MsgRegisterValidator) Validator() sdk.ConsAddress {
+	if msg.PubKey == nil {
+		return nil
+	}
+	return sdk.ConsAddress(msg.PubKey.Address())
+}
+
+// validate MsgRegisterValidator
+func (msg MsgRegisterValidator) ValidateBasic() sdk.Error {
+	if len(msg.Operator) == 0 {
+		return sdk.ErrUnknownAddress(msg.Operator.String()).TraceSDK("")
+	}
+	if msg.PubKey == nil {
+		return sdk.ErrInvalidPubKey("Missing consensus key")
+	}
+	if len(msg.Signature) == 0 {
+		return ErrInvalidRegistration(DefaultCodespace, msg.Validator())
+	}
+	return nil
+}
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg MsgRegisterValidator) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+//_______________________________________________________________________
+
+// Beacon is the random output of an epoch
+type Beacon struct {
+	Epoch        int64  `json:"epoch"`
+	Value        []byte `json:"value"`
+	Height       int64  `json:"height"`       // height of the block computing it
+	Commitments  int64  `json:"commitments"`  // participants that committed
+	Contributors int64  `json:"contributors"` // participants that revealed
+
+	// participants that committed but did not reveal, excluded for the next
+	// WithholdingPenalty epochs
+	Withholders []sdk.AccAddress `json:"withholders"`
+}
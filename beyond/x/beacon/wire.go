@@ -0,0 +1,10 @@
+package beacon
+
+import "github.com/cosmos/cosmos-sdk/codec"
+
+// Register concrete types on wire codec
+func RegisterCodec(cdc *codec.Codec) {
+	cdc.RegisterConcrete(MsgCommitRandomness{}, "beacon/CommitRandomness", nil)
+	cdc.RegisterConcrete(MsgRevealRandomness{}, "beacon/RevealRandomness", nil)
+	cdc.RegisterConcrete(MsgRegisterValidator{}, "beacon/RegisterValidator", nil)
+}
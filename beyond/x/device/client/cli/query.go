@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+
+	dev "github.com/vincepg13/bp-sdk/beyond/x/device"
+
+	clictx "github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/spf13/cobra"
+)
+
+// GetCmdQueryAttestation implements the query device attestation command.
+func GetCmdQueryAttestation(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "attestation [address]",
+		Short: "Query the latest attestation of a device account",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return queryByAddress(cdc, fmt.Sprintf("custom/%s/%s", queryRoute, dev.QueryAttestation), args[0])
+		},
+	}
+}
+
+// GetCmdQueryAttestationPolicy implements the query station attestation policy command.
+func GetCmdQueryAttestationPolicy(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "attestationPolicy [address]",
+		Short: "Query the attestation age a station requires from buyers",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return queryByAddress(cdc, fmt.Sprintf("custom/%s/%s", queryRoute, dev.QueryAttestationPolicy), args[0])
+		},
+	}
+}
+
+func queryByAddress(cdc *codec.Codec, path string, address string) error {
+	cliCtx := clictx.NewCLIContext().WithCodec(cdc)
+
+	addr, err := sdk.AccAddressFromBech32(address)
+	if err != nil {
+		return err
+	}
+	bz, err := cdc.MarshalJSON(dev.QueryAddressParams{Address: addr})
+	if err != nil {
+		return err
+	}
+
+	res, err := cliCtx.QueryWithData(path, bz)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(res))
+	return nil
+}
@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/vincepg13/bp-sdk/beyond/client/hsm"
+	"github.com/vincepg13/bp-sdk/beyond/types"
+	dev "github.com/vincepg13/bp-sdk/beyond/x/device"
+	dc "github.com/vincepg13/bp-sdk/deepcover-client"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authcmd "github.com/cosmos/cosmos-sdk/x/auth/client/cli"
+	authtxb "github.com/cosmos/cosmos-sdk/x/auth/client/txbuilder"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	flagPubKey    = "pubkey"
+	flagRomID     = "romid"
+	flagManID     = "manid"
+	flagCertR     = "cert-r"
+	flagCertS     = "cert-s"
+	flagPage      = "page"
+	flagPageData  = "page-data"
+	flagSignature = "signature"
+	flagHeight    = "height"
+	flagMaxAge    = "max-age"
+)
+
+// OnboardDeviceTxCmd will create an onboardDevice tx and sign it with the given key.
+func OnboardDeviceTxCmd(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "onboardDevice",
+		Short: "Create and sign an onboardDevice tx for a DeepCover secure element",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := authtxb.NewTxBuilderFromCLI().WithCodec(cdc)
+			cliCtx := hsm.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(authcmd.GetAccountDecoder(cdc))
+
+			if err := hsm.EnsureAccountExists(cliCtx); err != nil {
+				return err
+			}
+
+			from, err := hsm.GetFromAddress(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			cert, err := certificateFromFlags()
+			if err != nil {
+				return err
+			}
+
+			pageData, err := hex.DecodeString(viper.GetString(flagPageData))
+			if err != nil {
+				return err
+			}
+
+			page := viper.GetInt(flagPage)
+			device := types.DeviceAddress(cert.PubKeyA)
+			challenge := dev.OnboardChallenge(txBldr.ChainID, from, device)
+			signature, err := hex.DecodeString(viper.GetString(flagSignature))
+			if err != nil {
+				return err
+			}
+			if len(signature) == 0 {
+				// the device proves it is held by the sender
+				session := dc.DefaultSession()
+				pubKey, err := session.PubKeyA(context.Background())
+				if err != nil {
+					return err
+				}
+				if !bytes.Equal(pubKey, cert.PubKeyA) {
+					return fmt.Errorf("local secure element is %s, not the certified device %s", types.DeviceAddress(pubKey), device)
+				}
+				if signature, err = session.ComputePageSignature(context.Background(), page, challenge); err != nil {
+					return err
+				}
+			}
+
+			msg := dev.NewMsgOnboardDevice(from, cert, page, pageData, signature)
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return hsm.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().String(flagPubKey, "", "Device public key A, hex of X followed by Y")
+	cmd.Flags().String(flagRomID, "", "Device ROM ID (hex)")
+	cmd.Flags().String(flagManID, "0000", "Manufacturer ID (hex)")
+	cmd.Flags().String(flagCertR, "", "Certificate r from page 14 (hex)")
+	cmd.Flags().String(flagCertS, "", "Certificate s from page 15 (hex)")
+	cmd.Flags().Int(flagPage, 0, "Page the device signs attestations with")
+	cmd.Flags().String(flagPageData, "", "Content of the signing page (hex), empty for an erased page")
+	cmd.Flags().String(flagSignature, "", "Onboarding signature of the device (hex), signed with the local secure element if empty")
+	cmd.MarkFlagRequired(flagPubKey)
+	cmd.MarkFlagRequired(flagRomID)
+	cmd.MarkFlagRequired(flagCertR)
+	cmd.MarkFlagRequired(flagCertS)
+
+	return cmd
+}
+
+func certificateFromFlags() (cert dc.DeviceCertificate, err error) {
+	fields := []struct {
+		flag string
+		dst  *[]byte
+	}{
+		{flagPubKey, &cert.PubKeyA},
+		{flagRomID, &cert.RomID},
+		{flagManID, &cert.ManID},
+		{flagCertR, &cert.R},
+		{flagCertS, &cert.S},
+	}
+	for _, f := range fields {
+		if *f.dst, err = hex.DecodeString(viper.GetString(f.flag)); err != nil {
+			return cert, err
+		}
+	}
+	return cert, nil
+}
+
+// AttestTxCmd answers the attestation challenge with the local DeepCover
+// secure element and broadcasts the signature.
+func AttestTxCmd(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attest",
+		Short: "Sign the attestation challenge with the local secure element and broadcast it",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := authtxb.NewTxBuilderFromCLI().WithCodec(cdc)
+			cliCtx := hsm.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(authcmd.GetAccountDecoder(cdc))
+
+			if err := hsm.EnsureAccountExists(cliCtx); err != nil {
+				return err
+			}
+
+			from, err := hsm.GetFromAddress(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			session := dc.DefaultSession()
+			pubKey, err := session.PubKeyA(context.Background())
+			if err != nil {
+				return err
+			}
+			device := types.DeviceAddress(pubKey)
+
+			height := viper.GetInt64(flagHeight)
+			if height == 0 {
+				// the hash of the latest block is only stored once the next
+				// block begins, so answer the challenge of its parent
+				node, err := cliCtx.GetNode()
+				if err != nil {
+					return err
+				}
+				status, err := node.Status()
+				if err != nil {
+					return err
+				}
+				height = status.SyncInfo.LatestBlockHeight - 1
+			}
+
+			bz, err := cdc.MarshalJSON(dev.QueryChallengeParams{Device: device, Height: height})
+			if err != nil {
+				return err
+			}
+			challenge, err := cliCtx.QueryWithData(fmt.Sprintf("custom/device/%s", dev.QueryChallenge), bz)
+			if err != nil {
+				return err
+			}
+
+			signature, err := session.ComputePageSignature(context.Background(), viper.GetInt(flagPage), challenge)
+			if err != nil {
+				return err
+			}
+
+			msg := dev.NewMsgAttest(from, device, height, signature)
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return hsm.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().Int64(flagHeight, 0, "Challenge height, defaults to the parent of the latest block")
+	cmd.Flags().Int(flagPage, 0, "Page the device was onboarded to sign with")
+
+	return cmd
+}
+
+// SetAttestationPolicyTxCmd will create a setAttestationPolicy tx and sign it with the given key.
+func SetAttestationPolicyTxCmd(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "setAttestationPolicy",
+		Short: "Require buyers to present a device attestation younger than --max-age blocks, 0 disables it",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := authtxb.NewTxBuilderFromCLI().WithCodec(cdc)
+			cliCtx := hsm.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(authcmd.GetAccountDecoder(cdc))
+
+			if err := hsm.EnsureAccountExists(cliCtx); err != nil {
+				return err
+			}
+
+			from, err := hsm.GetFromAddress(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			msg := dev.NewMsgSetAttestationPolicy(from, viper.GetInt64(flagMaxAge))
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return hsm.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().Int64(flagMaxAge, 0, fmt.Sprintf("Maximum attestation age in blocks, at most %d", dev.ChallengeWindow))
+	cmd.MarkFlagRequired(flagMaxAge)
+
+	return cmd
+}
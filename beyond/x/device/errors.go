@@ -0,0 +1,48 @@
+package device
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Device errors reserve 500 ~ 599.
+const (
+	DefaultCodespace           sdk.CodespaceType = 5
+	CodeInvalidCertificate     sdk.CodeType      = 500
+	CodeDeviceAlreadyOnboarded sdk.CodeType      = 501
+	CodeInvalidDeviceData      sdk.CodeType      = 502
+	CodeStaleChallenge         sdk.CodeType      = 503
+	CodeInvalidAttestation     sdk.CodeType      = 504
+	CodeNotADevice             sdk.CodeType      = 505
+	CodeInvalidOnboarding      sdk.CodeType      = 506
+)
+
+// ErrInvalidCertificate
+func ErrInvalidCertificate(codespace sdk.CodespaceType) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidCertificate, fmt.Sprintf("Device certificate is not signed by a known authority"))
+}
+
+func ErrDeviceAlreadyOnboarded(codespace sdk.CodespaceType, addr sdk.AccAddress) sdk.Error {
+	return sdk.NewError(codespace, CodeDeviceAlreadyOnboarded, fmt.Sprintf("Device %s is already onboarded", addr))
+}
+
+func ErrInvalidDeviceData(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidDeviceData, msg)
+}
+
+func ErrStaleChallenge(codespace sdk.CodespaceType, height int64) sdk.Error {
+	return sdk.NewError(codespace, CodeStaleChallenge, fmt.Sprintf("No challenge available for height %d", height))
+}
+
+func ErrInvalidAttestation(codespace sdk.CodespaceType) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidAttestation, fmt.Sprintf("Attestation signature does not verify"))
+}
+
+func ErrNotADevice(codespace sdk.CodespaceType, addr sdk.AccAddress) sdk.Error {
+	return sdk.NewError(codespace, CodeNotADevice, fmt.Sprintf("Account %s is not an onboarded device", addr))
+}
+
+func ErrInvalidOnboarding(codespace sdk.CodespaceType, page int) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidOnboarding, fmt.Sprintf("Onboarding is not signed by the device with page %d", page))
+}
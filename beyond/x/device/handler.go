@@ -0,0 +1,166 @@
+package device
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/vincepg13/bp-sdk/beyond/types"
+	"github.com/vincepg13/bp-sdk/beyond/x/device/tags"
+	dc "github.com/vincepg13/bp-sdk/deepcover-client"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// NewHandler
+func NewHandler(k Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case MsgOnboardDevice:
+			return handleMsgOnboardDevice(ctx, k, msg)
+		case MsgAttest:
+			return handleMsgAttest(ctx, k, msg)
+		case MsgSetAttestationPolicy:
+			return handleMsgSetAttestationPolicy(ctx, k, msg)
+		default:
+			errMsg := fmt.Sprintf("Unrecognized Msg type: %v", reflect.TypeOf(msg).Name())
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+func handleMsgOnboardDevice(ctx sdk.Context, k Keeper, msg MsgOnboardDevice) sdk.Result {
+
+	authority, err := k.VerifyCertificate(ctx, msg.Certificate())
+	if err != nil {
+		return err.Result()
+	}
+
+	// the certificate does not cover the signing page, only the device can
+	// sign the challenge of the sender with the page it claims
+	addr := msg.DeviceAddress()
+	hsmInfo := types.HsmInfo{
+		RomID:     msg.RomID,
+		ManID:     msg.ManID,
+		PubKey:    msg.PubKey,
+		Page:      msg.Page,
+		PageData:  msg.PageData,
+		Authority: authority,
+		Verified:  true,
+	}
+	if !verifyOnboarding(OnboardChallenge(ctx.ChainID(), msg.Sender, addr), hsmInfo, msg.Signature) {
+		return ErrInvalidOnboarding(k.codespace, msg.Page).Result()
+	}
+
+	// create the device account or mark an existing one
+	acc := k.am.GetAccount(ctx, addr)
+	if acc == nil {
+		acc = k.am.NewAccountWithAddress(ctx, addr)
+	}
+
+	appAcc, ok := acc.(*types.AppAccount)
+	if !ok {
+		return sdk.ErrInternal(fmt.Sprintf("Account %s is not an AppAccount", addr)).Result()
+	}
+	if appAcc.HsmInfo.Verified {
+		return ErrDeviceAlreadyOnboarded(k.codespace, addr).Result()
+	}
+
+	appAcc.SetHsmInfo(hsmInfo)
+	k.am.SetAccount(ctx, appAcc)
+	k.SetDeviceAddress(ctx, msg.RomID, addr)
+
+	resTags := sdk.NewTags(
+		tags.Action, tags.ActionOnboardDevice,
+		tags.Device, []byte(addr.String()),
+		tags.RomID, []byte(fmt.Sprintf("%X", msg.RomID)),
+		tags.Authority, []byte(authority),
+	)
+
+	return sdk.Result{
+		Code: sdk.ABCICodeOK,
+		Tags: resTags,
+	}
+}
+
+// verifyOnboarding checks the signature of the device over the onboarding
+// challenge with the layout it is onboarded with
+func verifyOnboarding(challenge []byte, hsmInfo types.HsmInfo, sig []byte) bool {
+	pub, err := dc.PublicKeyFromBytes(hsmInfo.PubKey)
+	if err != nil {
+		return false
+	}
+	digest, err := dc.CalcucateMessageDigest(challenge, hsmInfo.DigestLayout())
+	if err != nil {
+		return false
+	}
+	return dc.VerifySignature(pub, digest, sig)
+}
+
+func handleMsgAttest(ctx sdk.Context, k Keeper, msg MsgAttest) sdk.Result {
+
+	hsmInfo, ok := k.GetHsmInfo(ctx, msg.Device)
+	if !ok {
+		return ErrNotADevice(k.codespace, msg.Device).Result()
+	}
+
+	blockHash := k.GetBlockHash(ctx, msg.Height)
+	if blockHash == nil {
+		return ErrStaleChallenge(k.codespace, msg.Height).Result()
+	}
+
+	// the device signs the challenge with computeReadPageAuthentication,
+	// verify it following the DeepCover message digest rules
+	pub, err := dc.PublicKeyFromBytes(hsmInfo.PubKey)
+	if err != nil {
+		return ErrInvalidAttestation(k.codespace).Result()
+	}
+	digest, err := dc.CalcucateMessageDigest(Challenge(blockHash, msg.Device), hsmInfo.DigestLayout())
+	if err != nil {
+		return ErrInvalidAttestation(k.codespace).Result()
+	}
+	if !dc.VerifySignature(pub, digest, msg.Signature) {
+		return ErrInvalidAttestation(k.codespace).Result()
+	}
+
+	if last, found := k.GetAttestation(ctx, msg.Device); !found || last.Height < msg.Height {
+		k.SetAttestation(ctx, msg.Device, Attestation{
+			Height:     msg.Height,
+			RecordedAt: ctx.BlockHeight(),
+		})
+	}
+
+	resTags := sdk.NewTags(
+		tags.Action, tags.ActionAttest,
+		tags.Device, []byte(msg.Device.String()),
+	)
+
+	return sdk.Result{
+		Code: sdk.ABCICodeOK,
+		Tags: resTags,
+	}
+}
+
+func handleMsgSetAttestationPolicy(ctx sdk.Context, k Keeper, msg MsgSetAttestationPolicy) sdk.Result {
+
+	k.SetAttestationPolicy(ctx, msg.Station, msg.MaxAge)
+
+	resTags := sdk.NewTags(
+		tags.Action, tags.ActionSetAttestationPolicy,
+		tags.Station, []byte(msg.Station.String()),
+	)
+
+	return sdk.Result{
+		Code: sdk.ABCICodeOK,
+		Tags: resTags,
+	}
+}
+
+// BeginBlocker keeps the hash of the previous block as the attestation
+// challenge for its height
+func BeginBlocker(ctx sdk.Context, req abci.RequestBeginBlock, k Keeper) {
+	header := req.Header
+	if header.Height > 1 && len(header.LastBlockId.Hash) != 0 {
+		k.SetBlockHash(ctx, header.Height-1, header.LastBlockId.Hash)
+	}
+}
@@ -0,0 +1,194 @@
+package device
+
+import (
+	"encoding/binary"
+
+	"github.com/vincepg13/bp-sdk/beyond/types"
+	dc "github.com/vincepg13/bp-sdk/deepcover-client"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+)
+
+// Keeper
+type Keeper struct {
+	am        auth.AccountKeeper
+	storeKey  sdk.StoreKey // The (unexposed) key used to access the store from the Context.
+	cdc       *codec.Codec
+	codespace sdk.CodespaceType
+}
+
+func NewKeeper(key sdk.StoreKey, accountKeeper auth.AccountKeeper, codespace sdk.CodespaceType) Keeper {
+	cdc := codec.New()
+	codec.RegisterCrypto(cdc)
+	return Keeper{
+		storeKey:  key,
+		cdc:       cdc,
+		am:        accountKeeper,
+		codespace: codespace,
+	}
+}
+
+// SetAuthority stores a manufacturer authority key
+func (k Keeper) SetAuthority(ctx sdk.Context, authority types.AuthorityKey) {
+	store := ctx.KVStore(k.storeKey)
+	bz, err := k.cdc.MarshalBinaryLengthPrefixed(authority)
+	if err != nil {
+		panic(err)
+	}
+	store.Set(KeyAuthority(authority.Name), bz)
+}
+
+// GetAuthorities returns all manufacturer authority keys
+func (k Keeper) GetAuthorities(ctx sdk.Context) (authorities []types.AuthorityKey) {
+	store := ctx.KVStore(k.storeKey)
+	iter := sdk.KVStorePrefixIterator(store, ByteKeyAuthority)
+	defer iter.Close()
+
+	for ; iter.Valid(); iter.Next() {
+		var authority types.AuthorityKey
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(iter.Value(), &authority)
+		authorities = append(authorities, authority)
+	}
+	return authorities
+}
+
+// VerifyCertificate checks the device certificate against the stored
+// authorities and returns the name of the authority that signed it
+func (k Keeper) VerifyCertificate(ctx sdk.Context, cert dc.DeviceCertificate) (string, sdk.Error) {
+	for _, authority := range k.GetAuthorities(ctx) {
+		pub, err := dc.ParseAuthorityKey(authority.PubKey)
+		if err != nil {
+			continue
+		}
+		if _, err := dc.VerifyCertificate(cert, pub); err == nil {
+			return authority.Name, nil
+		}
+	}
+	return "", ErrInvalidCertificate(k.codespace)
+}
+
+// SetDeviceAddress links a ROM ID to the account of the device
+func (k Keeper) SetDeviceAddress(ctx sdk.Context, romID []byte, addr sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(KeyDevice(romID), addr)
+}
+
+// GetDeviceAddress returns the account of the device with the given ROM ID
+func (k Keeper) GetDeviceAddress(ctx sdk.Context, romID []byte) sdk.AccAddress {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(KeyDevice(romID))
+	if bz == nil {
+		return nil
+	}
+	return sdk.AccAddress(bz)
+}
+
+// SetBlockHash stores the hash of the block at the given height as an
+// attestation challenge and drops the challenge leaving the window
+func (k Keeper) SetBlockHash(ctx sdk.Context, height int64, hash []byte) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(KeyBlockHash(height), hash)
+	store.Delete(KeyBlockHash(height - ChallengeWindow))
+}
+
+// GetBlockHash returns the hash of a block still inside the challenge window
+func (k Keeper) GetBlockHash(ctx sdk.Context, height int64) []byte {
+	store := ctx.KVStore(k.storeKey)
+	return store.Get(KeyBlockHash(height))
+}
+
+// GetHsmInfo returns the secure element backing an onboarded device account
+func (k Keeper) GetHsmInfo(ctx sdk.Context, addr sdk.AccAddress) (types.HsmInfo, bool) {
+	appAcc, ok := k.am.GetAccount(ctx, addr).(*types.AppAccount)
+	if !ok || !appAcc.HsmInfo.IsDevice() {
+		return types.HsmInfo{}, false
+	}
+	return appAcc.GetHsmInfo(), true
+}
+
+// SetAttestation records the latest attestation of a device
+func (k Keeper) SetAttestation(ctx sdk.Context, addr sdk.AccAddress, attestation Attestation) {
+	store := ctx.KVStore(k.storeKey)
+	bz, err := k.cdc.MarshalBinaryLengthPrefixed(attestation)
+	if err != nil {
+		panic(err)
+	}
+	store.Set(KeyAttestation(addr), bz)
+}
+
+// GetAttestation returns the latest attestation of a device
+func (k Keeper) GetAttestation(ctx sdk.Context, addr sdk.AccAddress) (attestation Attestation, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(KeyAttestation(addr))
+	if bz == nil {
+		return attestation, false
+	}
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &attestation)
+	return attestation, true
+}
+
+// HasFreshAttestation returns true if the device attested a challenge not
+// older than maxAge blocks
+func (k Keeper) HasFreshAttestation(ctx sdk.Context, addr sdk.AccAddress, maxAge int64) bool {
+	attestation, found := k.GetAttestation(ctx, addr)
+	return found && ctx.BlockHeight()-attestation.Height <= maxAge
+}
+
+// SetAttestationPolicy sets the maximum attestation age a station requires
+func (k Keeper) SetAttestationPolicy(ctx sdk.Context, station sdk.AccAddress, maxAge int64) {
+	store := ctx.KVStore(k.storeKey)
+	if maxAge == 0 {
+		store.Delete(KeyAttestationPolicy(station))
+		return
+	}
+	store.Set(KeyAttestationPolicy(station), heightBytes(maxAge))
+}
+
+// GetAttestationPolicy returns the maximum attestation age a station
+// requires, zero if it does not require attestations
+func (k Keeper) GetAttestationPolicy(ctx sdk.Context, station sdk.AccAddress) int64 {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(KeyAttestationPolicy(station))
+	if bz == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(bz))
+}
+
+// Keeper keys
+
+var (
+	ByteKeyAuthority         = []byte("authority:")
+	ByteKeyDevice            = []byte("device:")
+	ByteKeyBlockHash         = []byte("blockHash:")
+	ByteKeyAttestation       = []byte("attestation:")
+	ByteKeyAttestationPolicy = []byte("attestationPolicy:")
+)
+
+func heightBytes(height int64) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, uint64(height))
+	return bz
+}
+
+func KeyAuthority(name string) []byte {
+	return append(append([]byte{}, ByteKeyAuthority...), name...)
+}
+
+func KeyDevice(romID []byte) []byte {
+	return append(append([]byte{}, ByteKeyDevice...), romID...)
+}
+
+func KeyBlockHash(height int64) []byte {
+	return append(append([]byte{}, ByteKeyBlockHash...), heightBytes(height)...)
+}
+
+func KeyAttestation(addr sdk.AccAddress) []byte {
+	return append(append([]byte{}, ByteKeyAttestation...), addr...)
+}
+
+func KeyAttestationPolicy(station sdk.AccAddress) []byte {
+	return append(append([]byte{}, ByteKeyAttestationPolicy...), station...)
+}
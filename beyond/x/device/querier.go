@@ -0,0 +1,91 @@
+package device
+
+import (
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// query endpoints supported by the device Querier
+const (
+	QueryChallenge         = "challenge"
+	QueryAttestation       = "attestation"
+	QueryAttestationPolicy = "attestationPolicy"
+)
+
+func NewQuerier(keeper Keeper) sdk.Querier {
+	return func(ctx sdk.Context, path []string, req abci.RequestQuery) (res []byte, err sdk.Error) {
+		switch path[0] {
+		case QueryChallenge:
+			return queryChallenge(ctx, req, keeper)
+		case QueryAttestation:
+			return queryAttestation(ctx, req, keeper)
+		case QueryAttestationPolicy:
+			return queryAttestationPolicy(ctx, req, keeper)
+		default:
+			return nil, sdk.ErrUnknownRequest("unknown device query endpoint")
+		}
+	}
+}
+
+// Params for query 'custom/device/challenge'
+type QueryChallengeParams struct {
+	Device sdk.AccAddress
+	Height int64
+}
+
+// Params for queries 'custom/device/attestation' and
+// 'custom/device/attestationPolicy'
+type QueryAddressParams struct {
+	Address sdk.AccAddress
+}
+
+// QueryAttestationPolicyResult is the attestation requirement of a station
+type QueryAttestationPolicyResult struct {
+	MaxAge int64 `json:"maxAge"`
+}
+
+func queryChallenge(ctx sdk.Context, req abci.RequestQuery, keeper Keeper) (res []byte, err sdk.Error) {
+	var params QueryChallengeParams
+	if err2 := keeper.cdc.UnmarshalJSON(req.Data, &params); err2 != nil {
+		return nil, sdk.ErrUnknownRequest(sdk.AppendMsgToErr("incorrectly formatted request data", err2.Error()))
+	}
+
+	blockHash := keeper.GetBlockHash(ctx, params.Height)
+	if blockHash == nil {
+		return nil, ErrStaleChallenge(keeper.codespace, params.Height)
+	}
+	return Challenge(blockHash, params.Device), nil
+}
+
+func queryAttestation(ctx sdk.Context, req abci.RequestQuery, keeper Keeper) (res []byte, err sdk.Error) {
+	var params QueryAddressParams
+	if err2 := keeper.cdc.UnmarshalJSON(req.Data, &params); err2 != nil {
+		return nil, sdk.ErrUnknownRequest(sdk.AppendMsgToErr("incorrectly formatted request data", err2.Error()))
+	}
+
+	attestation, found := keeper.GetAttestation(ctx, params.Address)
+	if !found {
+		return nil, sdk.ErrUnknownRequest("no attestation recorded for " + params.Address.String())
+	}
+	return marshalJSON(keeper.cdc, attestation)
+}
+
+func queryAttestationPolicy(ctx sdk.Context, req abci.RequestQuery, keeper Keeper) (res []byte, err sdk.Error) {
+	var params QueryAddressParams
+	if err2 := keeper.cdc.UnmarshalJSON(req.Data, &params); err2 != nil {
+		return nil, sdk.ErrUnknownRequest(sdk.AppendMsgToErr("incorrectly formatted request data", err2.Error()))
+	}
+
+	return marshalJSON(keeper.cdc, QueryAttestationPolicyResult{
+		MaxAge: keeper.GetAttestationPolicy(ctx, params.Address),
+	})
+}
+
+func marshalJSON(cdc *codec.Codec, o interface{}) (res []byte, err sdk.Error) {
+	bz, err2 := codec.MarshalJSONIndent(cdc, o)
+	if err2 != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not marshal result to JSON", err2.Error()))
+	}
+	return bz, nil
+}
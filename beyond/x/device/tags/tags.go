@@ -0,0 +1,18 @@
+// nolint
+package tags
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var (
+	ActionOnboardDevice        = []byte("onboardDevice")
+	ActionAttest               = []byte("attest")
+	ActionSetAttestationPolicy = []byte("setAttestationPolicy")
+
+	Action    = sdk.TagAction
+	Device    = "device"
+	RomID     = "romId"
+	Authority = "authority"
+	Station   = "station"
+)
@@ -0,0 +1,257 @@
+package device
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/vincepg13/bp-sdk/beyond/types"
+	dc "github.com/vincepg13/bp-sdk/deepcover-client"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// MsgOnboardDevice registers a DeepCover secure element on chain. The
+// certificate stored on the device must be signed by one of the
+// manufacturer authorities from genesis. Any account may submit it, the
+// device account is derived from the certified public key. The certificate
+// does not cover the signing page, so the device also signs the
+// OnboardChallenge of the sender with it, which proves the sender holds
+// the device and that the device signs with that page.
+type MsgOnboardDevice struct {
+	Sender sdk.AccAddress
+	PubKey []byte // PubKeyAX followed by PubKeyAY
+	RomID  []byte
+	ManID  []byte
+	CertR  []byte // certificate r, page 14
+	CertS  []byte // certificate s, page 15
+
+	// page the device signs attestations with and its content, empty
+	// PageData is an erased page
+	Page     int
+	PageData []byte
+
+	Signature []byte // device signature of the OnboardChallenge, R followed by S, low-S form
+}
+
+// Construct new NewMsgOnboardDevice.
+func NewMsgOnboardDevice(sender sdk.AccAddress, cert dc.DeviceCertificate, page int, pageData []byte, signature []byte) MsgOnboardDevice {
+	return MsgOnboardDevice{
+		Sender:    sender,
+		PubKey:    cert.PubKeyA,
+		RomID:     cert.RomID,
+		ManID:     cert.ManID,
+		CertR:     cert.R,
+		CertS:     cert.S,
+		Page:      page,
+		PageData:  pageData,
+		Signature: signature,
+	}
+}
+
+// OnboardChallenge returns the 32 byte challenge a device signs to be
+// onboarded by sender on the chain chainID
+func OnboardChallenge(chainID string, sender sdk.AccAddress, device sdk.AccAddress) []byte {
+	h := sha256.New()
+	h.Write([]byte("onboard:"))
+	h.Write([]byte(chainID))
+	h.Write([]byte{0})
+	h.Write(sender)
+	h.Write(device)
+	return h.Sum(nil)
+}
+
+// enforce the msg type at compile time
+var _ sdk.Msg = MsgOnboardDevice{}
+
+//nolint
+func (msg MsgOnboardDevice) Type() string                 { return "onboard" }
+func (msg MsgOnboardDevice) Route() string                { return "device" }
+func (msg MsgOnboardDevice) GetSigners() []sdk.AccAddress { return []sdk.AccAddress{msg.Sender} }
+func (msg MsgOnboardDevice) String() string {
+	return fmt.Sprintf("MsgOnboardDevice{Sender: %v, RomID: %X, Device: %v}", msg.Sender, msg.RomID, msg.DeviceAddress())
+}
+
+// DeviceAddress returns the account address of the onboarded device
+func (msg MsgOnboardDevice) DeviceAddress() sdk.AccAddress {
+	return types.DeviceAddress(msg.PubKey)
+}
+
+// Certificate returns the device certificate carried by the message
+func (msg MsgOnboardDevice) Certificate() dc.DeviceCertificate {
+	return dc.DeviceCertificate{
+		PubKeyA: msg.PubKey,
+		RomID:   msg.RomID,
+		ManID:   msg.ManID,
+		R:       msg.CertR,
+		S:       msg.CertS,
+	}
+}
+
+// validate MsgOnboardDevice
+func (msg MsgOnboardDevice) ValidateBasic() sdk.Error {
+	if len(msg.Sender) == 0 {
+		return sdk.ErrUnknownAddress(msg.Sender.String()).TraceSDK("")
+	}
+	if len(msg.PubKey) != 64 {
+		return ErrInvalidDeviceData(DefaultCodespace, "Public key must be 64 bytes")
+	}
+	if err := dc.ValidateROMID(msg.RomID); err != nil {
+		return ErrInvalidDeviceData(DefaultCodespace, err.Error())
+	}
+	if len(msg.ManID) != 2 {
+		return ErrInvalidDeviceData(DefaultCodespace, "Manufacturer ID must be 2 bytes")
+	}
+	if len(msg.CertR) != 32 || len(msg.CertS) != 32 {
+		return ErrInvalidDeviceData(DefaultCodespace, "Certificate r and s must be 32 bytes each")
+	}
+	if len(msg.PageData) != 0 && len(msg.PageData) != dc.PageSize {
+		return ErrInvalidDeviceData(DefaultCodespace, fmt.Sprintf("Page data must be %d bytes", dc.PageSize))
+	}
+	if msg.Page < 0 || msg.Page > 31 {
+		return ErrInvalidDeviceData(DefaultCodespace, fmt.Sprintf("Invalid page %d", msg.Page))
+	}
+	if !dc.IsLowS(msg.Signature) {
+		return ErrInvalidDeviceData(DefaultCodespace, "Device signature must be 64 bytes in low-S form")
+	}
+	return nil
+}
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg MsgOnboardDevice) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+//_______________________________________________________________________
+
+// ChallengeWindow is the number of past block hashes kept as attestation
+// challenges
+const ChallengeWindow = 100
+
+// Challenge returns the 32 byte challenge a device has to sign to attest
+// its presence, built from the hash of the block at the challenge height
+// and the device account.
+func Challenge(blockHash []byte, device sdk.AccAddress) []byte {
+	h := sha256.New()
+	h.Write(blockHash)
+	h.Write(device)
+	return h.Sum(nil)
+}
+
+// MsgAttest proves that a physical secure element is present. The device
+// answers the challenge of a recent block with computeReadPageAuthentication
+// and any account, usually the station, submits the signature.
+type MsgAttest struct {
+	Sender    sdk.AccAddress
+	Device    sdk.AccAddress
+	Height    int64  // height of the block whose hash is the challenge
+	Signature []byte // R followed by S, low-S form
+}
+
+// Construct new NewMsgAttest.
+func NewMsgAttest(sender sdk.AccAddress, device sdk.AccAddress, height int64, signature []byte) MsgAttest {
+	return MsgAttest{
+		Sender:    sender,
+		Device:    device,
+		Height:    height,
+		Signature: signature,
+	}
+}
+
+var _ sdk.Msg = MsgAttest{}
+
+//nolint
+func (msg MsgAttest) Type() string                 { return "attest" }
+func (msg MsgAttest) Route() string                { return "device" }
+func (msg MsgAttest) GetSigners() []sdk.AccAddress { return []sdk.AccAddress{msg.Sender} }
+func (msg MsgAttest) String() string {
+	return fmt.Sprintf("MsgAttest{Sender: %v, Device: %v, Height: %v}", msg.Sender, msg.Device, msg.Height)
+}
+
+// validate MsgAttest
+func (msg MsgAttest) ValidateBasic() sdk.Error {
+	if len(msg.Sender) == 0 {
+		return sdk.ErrUnknownAddress(msg.Sender.String()).TraceSDK("")
+	}
+	if len(msg.Device) == 0 {
+		return sdk.ErrUnknownAddress(msg.Device.String()).TraceSDK("")
+	}
+	if msg.Height <= 0 {
+		return ErrStaleChallenge(DefaultCodespace, msg.Height)
+	}
+	if !dc.IsLowS(msg.Signature) {
+		return ErrInvalidAttestation(DefaultCodespace)
+	}
+	return nil
+}
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg MsgAttest) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+//_______________________________________________________________________
+
+// MsgSetAttestationPolicy lets a station require buyers to present an
+// attestation younger than MaxAge blocks before initOrder. Zero disables
+// the requirement.
+type MsgSetAttestationPolicy struct {
+	Station sdk.AccAddress
+	MaxAge  int64
+}
+
+// Construct new NewMsgSetAttestationPolicy.
+func NewMsgSetAttestationPolicy(station sdk.AccAddress, maxAge int64) MsgSetAttestationPolicy {
+	return MsgSetAttestationPolicy{
+		Station: station,
+		MaxAge:  maxAge,
+	}
+}
+
+var _ sdk.Msg = MsgSetAttestationPolicy{}
+
+//nolint
+func (msg MsgSetAttestationPolicy) Type() string  { return "setAttestationPolicy" }
+func (msg MsgSetAttestationPolicy) Route() string { return "device" }
+func (msg MsgSetAttestationPolicy) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Station}
+}
+func (msg MsgSetAttestationPolicy) String() string {
+	return fmt.Sprintf("MsgSetAttestationPolicy{Station: %v, MaxAge: %v}", msg.Station, msg.MaxAge)
+}
+
+// validate MsgSetAttestationPolicy
+func (msg MsgSetAttestationPolicy) ValidateBasic() sdk.Error {
+	if len(msg.Station) == 0 {
+		return sdk.ErrUnknownAddress(msg.Station.String()).TraceSDK("")
+	}
+	if msg.MaxAge < 0 || msg.MaxAge > ChallengeWindow {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("Maximum attestation age must be between 0 and %d blocks", ChallengeWindow))
+	}
+	return nil
+}
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg MsgSetAttestationPolicy) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+//_______________________________________________________________________
+
+// Attestation is the latest verified attestation of a device
+type Attestation struct {
+	Height     int64 `json:"height"`     // challenge height
+	RecordedAt int64 `json:"recordedAt"` // height of the block including MsgAttest
+}
@@ -0,0 +1,10 @@
+package device
+
+import "github.com/cosmos/cosmos-sdk/codec"
+
+// Register concrete types on wire codec
+func RegisterCodec(cdc *codec.Codec) {
+	cdc.RegisterConcrete(MsgOnboardDevice{}, "device/OnboardDevice", nil)
+	cdc.RegisterConcrete(MsgAttest{}, "device/Attest", nil)
+	cdc.RegisterConcrete(MsgSetAttestationPolicy{}, "device/SetAttestationPolicy", nil)
+}
@@ -1,12 +1,10 @@
 package cli
 
 import (
+	"github.com/vincepg13/bp-sdk/beyond/client/hsm"
 	mob "github.com/vincepg13/bp-sdk/beyond/x/mobility"
 	"strconv"
 
-	"github.com/cosmos/cosmos-sdk/client/utils"
-
-	"github.com/cosmos/cosmos-sdk/client/context"
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	authcmd "github.com/cosmos/cosmos-sdk/x/auth/client/cli"
@@ -33,11 +31,11 @@ func SendInitOrderTxCmd(cdc *codec.Codec) *cobra.Command {
 		Short: "Create and sign a initOrder tx",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			txBldr := authtxb.NewTxBuilderFromCLI().WithCodec(cdc)
-			cliCtx := context.NewCLIContext().
+			cliCtx := hsm.NewCLIContext().
 				WithCodec(cdc).
 				WithAccountDecoder(authcmd.GetAccountDecoder(cdc))
 
-			if err := cliCtx.EnsureAccountExists(); err != nil {
+			if err := hsm.EnsureAccountExists(cliCtx); err != nil {
 				return err
 			}
 
@@ -51,7 +49,7 @@ func SendInitOrderTxCmd(cdc *codec.Codec) *cobra.Command {
 			// get estimated energy amount
 			amount := viper.GetInt64(flagEstimatedAmount)
 
-			from, err := cliCtx.GetFromAddress()
+			from, err := hsm.GetFromAddress(cliCtx)
 			if err != nil {
 				return err
 			}
@@ -72,7 +70,7 @@ func SendInitOrderTxCmd(cdc *codec.Codec) *cobra.Command {
 			// TODO: price is fixed for now (demo). 1KwH costs 2 bynd coins.
 			msg := mob.NewMsgInitOrder(from, to, uint64(amount)*2, uint64(amount))
 
-			return utils.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msg})
+			return hsm.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msg})
 		},
 	}
 	cmd.Flags().String(flagTo, "", "Address of charging station or car (electricity source)")
@@ -92,11 +90,11 @@ func SendFinalizeOrderTxCmd(cdc *codec.Codec) *cobra.Command {
 		Short: "Create and sign a finalizeOrder tx",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			txBldr := authtxb.NewTxBuilderFromCLI().WithCodec(cdc)
-			cliCtx := context.NewCLIContext().
+			cliCtx := hsm.NewCLIContext().
 				WithCodec(cdc).
 				WithAccountDecoder(authcmd.GetAccountDecoder(cdc))
 
-			if err := cliCtx.EnsureAccountExists(); err != nil {
+			if err := hsm.EnsureAccountExists(cliCtx); err != nil {
 				return err
 			}
 
@@ -110,7 +108,7 @@ func SendFinalizeOrderTxCmd(cdc *codec.Codec) *cobra.Command {
 			// get charge amount from CLI
 			charge := viper.GetInt64(flagChargeAmount)
 
-			from, err := cliCtx.GetFromAddress()
+			from, err := hsm.GetFromAddress(cliCtx)
 			if err != nil {
 				return err
 			}
@@ -137,7 +135,7 @@ func SendFinalizeOrderTxCmd(cdc *codec.Codec) *cobra.Command {
 
 			msgSend := bank.CreateMsg(from, to, coins)
 
-			return utils.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msgFinalize, msgSend})
+			return hsm.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msgFinalize, msgSend})
 		},
 	}
 	cmd.Flags().String(flagTo, "", "Address of charging station or car (electricity source)")
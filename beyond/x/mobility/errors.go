@@ -9,6 +9,7 @@ import (
 // Mobility errors reserve 300 ~ 399.
 const (
 	DefaultCodespace      sdk.CodespaceType = 4
+	CodeAttestationNeeded sdk.CodeType      = 397
 	CodeNoChargeAmount    sdk.CodeType      = 398
 	CodeNoOrderNumber     sdk.CodeType      = 399
 	CodeEmptyEnergyAmount sdk.CodeType      = 400
@@ -25,3 +26,7 @@ func ErrNoOrderNumber(codespace sdk.CodespaceType) sdk.Error {
 func ErrNoChargeAmountProvided() sdk.Error {
 	return sdk.NewError(DefaultCodespace, CodeNoChargeAmount, fmt.Sprintf("Provide total charge amount"))
 }
+
+func ErrAttestationNeeded(codespace sdk.CodespaceType, station sdk.AccAddress, maxAge int64) sdk.Error {
+	return sdk.NewError(codespace, CodeAttestationNeeded, fmt.Sprintf("Station %s requires a device attestation younger than %d blocks", station, maxAge))
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/vincepg13/bp-sdk/beyond/x/mobility/tags"
+	"github.com/vincepg13/bp-sdk/beyond/x/revocation"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
@@ -25,8 +26,28 @@ func NewHandler(k Keeper) sdk.Handler {
 	}
 }
 
+// checkRevoked rejects orders involving a revoked device
+func checkRevoked(ctx sdk.Context, k Keeper, parties ...sdk.AccAddress) sdk.Error {
+	for _, addr := range parties {
+		if k.rk.IsAccountRevoked(ctx, addr) {
+			return revocation.ErrDeviceRevoked(revocation.DefaultCodespace, addr)
+		}
+	}
+	return nil
+}
+
 func handleMsgInitOrder(ctx sdk.Context, k Keeper, msg MsgInitOrder) sdk.Result {
 
+	if err := checkRevoked(ctx, k, msg.InitiatorAddress, msg.RecipientAddress); err != nil {
+		return err.Result()
+	}
+
+	// stations may require the buyer's device to have attested recently
+	if maxAge := k.dk.GetAttestationPolicy(ctx, msg.RecipientAddress); maxAge > 0 &&
+		!k.dk.HasFreshAttestation(ctx, msg.InitiatorAddress, maxAge) {
+		return ErrAttestationNeeded(k.codespace, msg.RecipientAddress, maxAge).Result()
+	}
+
 	var lastOrderNumber uint64
 	lastOrderNumber = k.GetOrderCount(ctx, msg.InitiatorAddress)
 
@@ -50,6 +71,10 @@ func handleMsgInitOrder(ctx sdk.Context, k Keeper, msg MsgInitOrder) sdk.Result
 
 func handleMsgFinalizeOrder(ctx sdk.Context, k Keeper, msg MsgFinalizeOrder) sdk.Result {
 
+	if err := checkRevoked(ctx, k, msg.InitiatorAddress, msg.RecipientAddress); err != nil {
+		return err.Result()
+	}
+
 	//Retrieve last InitOrderNumber from Initiator address
 	lastOrderNumber := k.GetOrderCount(ctx, msg.InitiatorAddress)
 	//Link initOrder and finalizeOrder in tags
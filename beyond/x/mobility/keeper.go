@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/vincepg13/bp-sdk/beyond/x/device"
+	"github.com/vincepg13/bp-sdk/beyond/x/revocation"
+
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/bank"
@@ -12,18 +15,22 @@ import (
 // Keeper
 type Keeper struct {
 	ck        bank.Keeper
+	rk        revocation.Keeper
+	dk        device.Keeper
 	storeKey  sdk.StoreKey // The (unexposed) key used to access the store from the Context.
 	cdc       *codec.Codec
 	codespace sdk.CodespaceType
 }
 
-func NewKeeper(key sdk.StoreKey, coinKeeper bank.Keeper, codespace sdk.CodespaceType) Keeper {
+func NewKeeper(key sdk.StoreKey, coinKeeper bank.Keeper, revocationKeeper revocation.Keeper, deviceKeeper device.Keeper, codespace sdk.CodespaceType) Keeper {
 	cdc := codec.New()
 	codec.RegisterCrypto(cdc)
 	return Keeper{
 		storeKey:  key,
 		cdc:       cdc,
 		ck:        coinKeeper,
+		rk:        revocationKeeper,
+		dk:        deviceKeeper,
 		codespace: codespace,
 	}
 }
@@ -0,0 +1,29 @@
+package revocation
+
+import (
+	"github.com/cosmos/cosmos-sdk/x/auth"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// NewAnteHandler rejects transactions signed by accounts whose secure
+// element has been revoked, or signed with the key of a revoked secure
+// element, before handing them to the next ante handler.
+func NewAnteHandler(k Keeper, next sdk.AnteHandler) sdk.AnteHandler {
+	return func(ctx sdk.Context, tx sdk.Tx, simulate bool) (newCtx sdk.Context, res sdk.Result, abort bool) {
+		stdTx, ok := tx.(auth.StdTx)
+		if !ok {
+			return next(ctx, tx, simulate)
+		}
+
+		sigs := stdTx.GetSignatures()
+		for i, signer := range stdTx.GetSigners() {
+			// the key of a new account is only known from its signature
+			if k.IsAccountRevoked(ctx, signer) || (i < len(sigs) && k.IsPubKeyRevoked(ctx, sigs[i].PubKey)) {
+				return ctx, ErrDeviceRevoked(k.codespace, signer).Result(), true
+			}
+		}
+
+		return next(ctx, tx, simulate)
+	}
+}
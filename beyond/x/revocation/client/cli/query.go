@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	rev "github.com/vincepg13/bp-sdk/beyond/x/revocation"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/spf13/cobra"
+)
+
+// GetCmdQueryRevocations implements the query revocation list command.
+func GetCmdQueryRevocations(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "revocations",
+		Short: "Query the device revocation list",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", queryRoute, rev.QueryRevocations), nil)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(res))
+			return nil
+		},
+	}
+}
+
+// GetCmdQueryRevokers implements the query revokers command.
+func GetCmdQueryRevokers(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "revokers",
+		Short: "Query the accounts allowed to revoke devices",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", queryRoute, rev.QueryRevokers), nil)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(res))
+			return nil
+		},
+	}
+}
+
+// GetCmdQueryRevocation implements the query revocation status command.
+func GetCmdQueryRevocation(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "revocation [address|romid|pubkey]",
+		Short: "Query whether a device is revoked, by account address, ROM ID or public key (hex)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			var params rev.QueryRevocationParams
+			if addr, err := sdk.AccAddressFromBech32(args[0]); err == nil {
+				params.Address = addr
+			} else {
+				bz, err := hex.DecodeString(args[0])
+				if err != nil {
+					return fmt.Errorf("%s is neither an address nor a hex ROM ID or public key", args[0])
+				}
+				if len(bz) == 8 {
+					params.RomID = bz
+				} else {
+					params.PubKey = bz
+				}
+			}
+
+			bz, err := cdc.MarshalJSON(params)
+			if err != nil {
+				return err
+			}
+
+			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", queryRoute, rev.QueryRevocation), bz)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(res))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"encoding/hex"
+
+	"github.com/vincepg13/bp-sdk/beyond/client/hsm"
+	rev "github.com/vincepg13/bp-sdk/beyond/x/revocation"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authcmd "github.com/cosmos/cosmos-sdk/x/auth/client/cli"
+	authtxb "github.com/cosmos/cosmos-sdk/x/auth/client/txbuilder"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	flagRomID  = "romid"
+	flagPubKey = "pubkey"
+	flagReason = "reason"
+)
+
+// RevokeDeviceTxCmd will create a revokeDevice tx and sign it with the given key.
+func RevokeDeviceTxCmd(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revokeDevice",
+		Short: "Create and sign a revokeDevice tx adding a secure element to the revocation list",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := authtxb.NewTxBuilderFromCLI().WithCodec(cdc)
+			cliCtx := hsm.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(authcmd.GetAccountDecoder(cdc))
+
+			if err := hsm.EnsureAccountExists(cliCtx); err != nil {
+				return err
+			}
+
+			from, err := hsm.GetFromAddress(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			romID, err := hex.DecodeString(viper.GetString(flagRomID))
+			if err != nil {
+				return err
+			}
+			pubKey, err := hex.DecodeString(viper.GetString(flagPubKey))
+			if err != nil {
+				return err
+			}
+
+			msg := rev.NewMsgRevokeDevice(from, romID, pubKey, viper.GetString(flagReason))
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return hsm.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().String(flagRomID, "", "ROM ID of the revoked device (hex)")
+	cmd.Flags().String(flagPubKey, "", "Public key of the revoked device, hex of X followed by Y")
+	cmd.Flags().String(flagReason, "", "Reason for the revocation, e.g. stolen or cloned")
+
+	return cmd
+}
@@ -0,0 +1,33 @@
+package revocation
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Revocation errors reserve 600 ~ 699.
+const (
+	DefaultCodespace      sdk.CodespaceType = 6
+	CodeDeviceRevoked     sdk.CodeType      = 600
+	CodeNotRevoker        sdk.CodeType      = 601
+	CodeNoDeviceSpecified sdk.CodeType      = 602
+	CodeAlreadyRevoked    sdk.CodeType      = 603
+)
+
+// ErrDeviceRevoked
+func ErrDeviceRevoked(codespace sdk.CodespaceType, addr sdk.AccAddress) sdk.Error {
+	return sdk.NewError(codespace, CodeDeviceRevoked, fmt.Sprintf("Device of account %s has been revoked", addr))
+}
+
+func ErrNotRevoker(codespace sdk.CodespaceType, addr sdk.AccAddress) sdk.Error {
+	return sdk.NewError(codespace, CodeNotRevoker, fmt.Sprintf("Account %s is not allowed to revoke devices", addr))
+}
+
+func ErrNoDeviceSpecified(codespace sdk.CodespaceType) sdk.Error {
+	return sdk.NewError(codespace, CodeNoDeviceSpecified, fmt.Sprintf("Provide a ROM ID or a public key to revoke"))
+}
+
+func ErrAlreadyRevoked(codespace sdk.CodespaceType) sdk.Error {
+	return sdk.NewError(codespace, CodeAlreadyRevoked, fmt.Sprintf("Device is already revoked"))
+}
@@ -0,0 +1,64 @@
+package revocation
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/vincepg13/bp-sdk/beyond/types"
+	"github.com/vincepg13/bp-sdk/beyond/x/revocation/tags"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// NewHandler
+func NewHandler(k Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case MsgRevokeDevice:
+			return handleMsgRevokeDevice(ctx, k, msg)
+		default:
+			errMsg := fmt.Sprintf("Unrecognized Msg type: %v", reflect.TypeOf(msg).Name())
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+func handleMsgRevokeDevice(ctx sdk.Context, k Keeper, msg MsgRevokeDevice) sdk.Result {
+
+	if !k.IsRevoker(ctx, msg.Revoker) {
+		return ErrNotRevoker(k.codespace, msg.Revoker).Result()
+	}
+
+	// only the identifiers that are not revoked yet are added, an entry
+	// revoking the ROM ID does not cover a public key given later
+	romID, pubKey := msg.RomID, msg.PubKey
+	if len(romID) != 0 && k.IsRevoked(ctx, romID, nil) {
+		romID = nil
+	}
+	if len(pubKey) != 0 && k.IsRevoked(ctx, nil, pubKey) {
+		pubKey = nil
+	}
+	if len(romID) == 0 && len(pubKey) == 0 {
+		return ErrAlreadyRevoked(k.codespace).Result()
+	}
+
+	k.AddRevocation(ctx, types.Revocation{
+		RomID:   romID,
+		PubKey:  pubKey,
+		Reason:  msg.Reason,
+		Revoker: msg.Revoker,
+		Height:  ctx.BlockHeight(),
+	})
+
+	resTags := sdk.NewTags(
+		tags.Action, tags.ActionRevokeDevice,
+		tags.Revoker, []byte(msg.Revoker.String()),
+		tags.RomID, []byte(fmt.Sprintf("%X", romID)),
+		tags.PubKey, []byte(fmt.Sprintf("%X", pubKey)),
+	)
+
+	return sdk.Result{
+		Code: sdk.ABCICodeOK,
+		Tags: resTags,
+	}
+}
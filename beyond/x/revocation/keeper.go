@@ -0,0 +1,198 @@
+package revocation
+
+import (
+	"encoding/binary"
+
+	"github.com/vincepg13/bp-sdk/beyond/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/tendermint/tendermint/crypto"
+)
+
+// Keeper
+type Keeper struct {
+	am        auth.AccountKeeper
+	storeKey  sdk.StoreKey // The (unexposed) key used to access the store from the Context.
+	cdc       *codec.Codec
+	codespace sdk.CodespaceType
+}
+
+func NewKeeper(key sdk.StoreKey, accountKeeper auth.AccountKeeper, codespace sdk.CodespaceType) Keeper {
+	cdc := codec.New()
+	codec.RegisterCrypto(cdc)
+	return Keeper{
+		storeKey:  key,
+		cdc:       cdc,
+		am:        accountKeeper,
+		codespace: codespace,
+	}
+}
+
+// SetRevoker allows the account to add devices to the revocation list
+func (k Keeper) SetRevoker(ctx sdk.Context, addr sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(KeyRevoker(addr), []byte{1})
+}
+
+// IsRevoker returns true if the account may revoke devices
+func (k Keeper) IsRevoker(ctx sdk.Context, addr sdk.AccAddress) bool {
+	store := ctx.KVStore(k.storeKey)
+	return store.Has(KeyRevoker(addr))
+}
+
+// GetRevokers returns all accounts allowed to revoke devices
+func (k Keeper) GetRevokers(ctx sdk.Context) (revokers []sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	iter := sdk.KVStorePrefixIterator(store, ByteKeyRevoker)
+	defer iter.Close()
+
+	for ; iter.Valid(); iter.Next() {
+		revokers = append(revokers, sdk.AccAddress(iter.Key()[len(ByteKeyRevoker):]))
+	}
+	return revokers
+}
+
+// AddRevocation appends an entry to the revocation list and indexes it by
+// ROM ID and public key
+func (k Keeper) AddRevocation(ctx sdk.Context, revocation types.Revocation) {
+	store := ctx.KVStore(k.storeKey)
+
+	var index uint64
+	if bz := store.Get(KeyRevocationCount); bz != nil {
+		index = binary.BigEndian.Uint64(bz)
+	}
+	indexBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(indexBytes, index)
+
+	bz, err := k.cdc.MarshalBinaryLengthPrefixed(revocation)
+	if err != nil {
+		panic(err)
+	}
+	store.Set(KeyRevocation(indexBytes), bz)
+	if len(revocation.RomID) != 0 {
+		store.Set(KeyRevokedRomID(revocation.RomID), indexBytes)
+	}
+	if len(revocation.PubKey) != 0 {
+		store.Set(KeyRevokedPubKey(revocation.PubKey), indexBytes)
+	}
+
+	countBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(countBytes, index+1)
+	store.Set(KeyRevocationCount, countBytes)
+}
+
+// GetRevocations returns the revocation list
+func (k Keeper) GetRevocations(ctx sdk.Context) (revocations []types.Revocation) {
+	store := ctx.KVStore(k.storeKey)
+	iter := sdk.KVStorePrefixIterator(store, ByteKeyRevocation)
+	defer iter.Close()
+
+	for ; iter.Valid(); iter.Next() {
+		var revocation types.Revocation
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(iter.Value(), &revocation)
+		revocations = append(revocations, revocation)
+	}
+	return revocations
+}
+
+// GetRevocation returns the entry revoking the given ROM ID or public key
+func (k Keeper) GetRevocation(ctx sdk.Context, romID []byte, pubKey []byte) (revocation types.Revocation, found bool) {
+	store := ctx.KVStore(k.storeKey)
+
+	var indexBytes []byte
+	if len(romID) != 0 {
+		indexBytes = store.Get(KeyRevokedRomID(romID))
+	}
+	if indexBytes == nil && len(pubKey) != 0 {
+		indexBytes = store.Get(KeyRevokedPubKey(pubKey))
+	}
+	if indexBytes == nil {
+		return revocation, false
+	}
+
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(store.Get(KeyRevocation(indexBytes)), &revocation)
+	return revocation, true
+}
+
+// IsRevoked returns true if the ROM ID or the public key has been revoked
+func (k Keeper) IsRevoked(ctx sdk.Context, romID []byte, pubKey []byte) bool {
+	_, found := k.GetRevocation(ctx, romID, pubKey)
+	return found
+}
+
+// IsAccountRevoked returns true if the account is backed by a revoked
+// device, either onboarded or holding the key of the secure element
+func (k Keeper) IsAccountRevoked(ctx sdk.Context, addr sdk.AccAddress) bool {
+	acc := k.am.GetAccount(ctx, addr)
+	if acc == nil {
+		return false
+	}
+	if appAcc, ok := acc.(*types.AppAccount); ok {
+		hsmInfo := appAcc.GetHsmInfo()
+		if (len(hsmInfo.RomID) != 0 || len(hsmInfo.PubKey) != 0) && k.IsRevoked(ctx, hsmInfo.RomID, hsmInfo.PubKey) {
+			return true
+		}
+	}
+	return k.IsPubKeyRevoked(ctx, acc.GetPubKey())
+}
+
+// IsPubKeyRevoked returns true if pubKey is the key of a revoked secure
+// element, other keys are never revoked
+func (k Keeper) IsPubKeyRevoked(ctx sdk.Context, pubKey crypto.PubKey) bool {
+	dcKey, ok := pubKey.(types.PubKeyDeepCover)
+	if !ok {
+		return false
+	}
+	return k.IsRevoked(ctx, dcKey.RomID, dcKey.Key)
+}
+
+// device returns the ROM ID and public key of the secure element backing an
+// account, from its onboarding or else from its key
+func (k Keeper) device(ctx sdk.Context, addr sdk.AccAddress) (romID, pubKey []byte, found bool) {
+	acc := k.am.GetAccount(ctx, addr)
+	if acc == nil {
+		return nil, nil, false
+	}
+	if appAcc, ok := acc.(*types.AppAccount); ok {
+		hsmInfo := appAcc.GetHsmInfo()
+		if len(hsmInfo.RomID) != 0 || len(hsmInfo.PubKey) != 0 {
+			return hsmInfo.RomID, hsmInfo.PubKey, true
+		}
+	}
+	if dcKey, ok := acc.GetPubKey().(types.PubKeyDeepCover); ok {
+		return dcKey.RomID, dcKey.Key, true
+	}
+	return nil, nil, true
+}
+
+// Keeper keys
+
+var (
+	ByteKeyRevoker       = []byte("revoker:")
+	ByteKeyRevocation    = []byte("revocation:")
+	ByteKeyRevokedRomID  = []byte("romId:")
+	ByteKeyRevokedPubKey = []byte("pubKey:")
+	KeyRevocationCount   = []byte("revocationCount")
+)
+
+func prefixed(prefix []byte, key []byte) []byte {
+	return append(append([]byte{}, prefix...), key...)
+}
+
+func KeyRevoker(addr sdk.AccAddress) []byte {
+	return prefixed(ByteKeyRevoker, addr)
+}
+
+func KeyRevocation(index []byte) []byte {
+	return prefixed(ByteKeyRevocation, index)
+}
+
+func KeyRevokedRomID(romID []byte) []byte {
+	return prefixed(ByteKeyRevokedRomID, romID)
+}
+
+func KeyRevokedPubKey(pubKey []byte) []byte {
+	return prefixed(ByteKeyRevokedPubKey, pubKey)
+}
@@ -0,0 +1,181 @@
+package revocation
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/vincepg13/bp-sdk/beyond/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/store"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func setupKeeper(t *testing.T) (sdk.Context, auth.AccountKeeper, Keeper) {
+	db := dbm.NewMemDB()
+	keyAcc := sdk.NewKVStoreKey("acc")
+	keyRevocation := sdk.NewKVStoreKey("revocation")
+	ms := store.NewCommitMultiStore(db)
+	ms.MountStoreWithDB(keyAcc, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(keyRevocation, sdk.StoreTypeIAVL, db)
+	require.Nil(t, ms.LoadLatestVersion())
+
+	cdc := codec.New()
+	codec.RegisterCrypto(cdc)
+	auth.RegisterCodec(cdc)
+	cdc.RegisterConcrete(&types.AppAccount{}, "beyond/Account", nil)
+	cdc.RegisterConcrete(types.PubKeyDeepCover{}, types.PubKeyDeepCoverAminoRoute, nil)
+
+	am := auth.NewAccountKeeper(cdc, keyAcc, func() auth.Account { return &types.AppAccount{} })
+	ctx := sdk.NewContext(ms, abci.Header{Height: 3}, false, log.NewNopLogger())
+	return ctx, am, NewKeeper(keyRevocation, am, DefaultCodespace)
+}
+
+// device returns the ROM ID and public key of a fake secure element
+func device(b byte) (romID, pubKey []byte) {
+	return bytes.Repeat([]byte{b}, 8), bytes.Repeat([]byte{b}, 64)
+}
+
+func TestRevokeDevice(t *testing.T) {
+	ctx, _, k := setupKeeper(t)
+	handler := NewHandler(k)
+	revoker := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	other := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	k.SetRevoker(ctx, revoker)
+	romID, pubKey := device(1)
+
+	res := handler(ctx, NewMsgRevokeDevice(other, romID, nil, "stolen"))
+	require.Equal(t, sdk.ToABCICode(DefaultCodespace, CodeNotRevoker), res.Code)
+	require.False(t, k.IsRevoked(ctx, romID, pubKey))
+
+	res = handler(ctx, NewMsgRevokeDevice(revoker, romID, nil, "stolen"))
+	require.True(t, res.IsOK(), res.Log)
+	require.True(t, k.IsRevoked(ctx, romID, nil))
+	require.True(t, k.IsRevoked(ctx, romID, pubKey))
+	require.Equal(t, []types.Revocation{{RomID: romID, Reason: "stolen", Revoker: revoker, Height: 3}}, k.GetRevocations(ctx))
+
+	// the public key is added to a revoked ROM ID, the ROM ID is not
+	// revoked twice
+	res = handler(ctx, NewMsgRevokeDevice(revoker, romID, pubKey, "cloned"))
+	require.True(t, res.IsOK(), res.Log)
+	require.True(t, k.IsRevoked(ctx, nil, pubKey))
+	require.Equal(t, []types.Revocation{
+		{RomID: romID, Reason: "stolen", Revoker: revoker, Height: 3},
+		{PubKey: pubKey, Reason: "cloned", Revoker: revoker, Height: 3},
+	}, k.GetRevocations(ctx))
+
+	res = handler(ctx, NewMsgRevokeDevice(revoker, romID, pubKey, "again"))
+	require.Equal(t, sdk.ToABCICode(DefaultCodespace, CodeAlreadyRevoked), res.Code)
+	res = handler(ctx, NewMsgRevokeDevice(revoker, nil, pubKey, "again"))
+	require.Equal(t, sdk.ToABCICode(DefaultCodespace, CodeAlreadyRevoked), res.Code)
+	require.Len(t, k.GetRevocations(ctx), 2)
+}
+
+func TestAnteHandler(t *testing.T) {
+	ctx, am, k := setupKeeper(t)
+	var called bool
+	ante := NewAnteHandler(k, func(ctx sdk.Context, tx sdk.Tx, simulate bool) (sdk.Context, sdk.Result, bool) {
+		called = true
+		return ctx, sdk.Result{}, false
+	})
+	deliver := func(signer sdk.AccAddress, sig auth.StdSignature) sdk.Result {
+		called = false
+		tx := auth.NewStdTx([]sdk.Msg{NewMsgRevokeDevice(signer, []byte("12345678"), nil, "")}, auth.StdFee{}, []auth.StdSignature{sig}, "")
+		_, res, abort := ante(ctx, tx, false)
+		require.Equal(t, !res.IsOK(), abort)
+		require.Equal(t, res.IsOK(), called)
+		return res
+	}
+	revoked := sdk.ToABCICode(DefaultCodespace, CodeDeviceRevoked)
+
+	// an onboarded account, revoked by ROM ID
+	onboardedRomID, onboardedPubKey := device(1)
+	onboarded := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	am.SetAccount(ctx, types.NewAppAccount("car", "", "1",
+		types.HsmInfo{RomID: onboardedRomID, PubKey: onboardedPubKey}, auth.NewBaseAccountWithAddress(onboarded)))
+	k.AddRevocation(ctx, types.Revocation{RomID: onboardedRomID})
+
+	// an account that signed before with a DeepCover key, revoked by ROM ID
+	storedRomID, storedPubKey := device(2)
+	storedKey := types.PubKeyDeepCover{RomID: storedRomID, Key: storedPubKey}
+	stored := auth.NewBaseAccountWithAddress(sdk.AccAddress(storedKey.Address()))
+	require.Nil(t, stored.SetPubKey(storedKey))
+	am.SetAccount(ctx, &types.AppAccount{BaseAccount: stored})
+	k.AddRevocation(ctx, types.Revocation{RomID: storedRomID})
+
+	// a DeepCover key signing for the first time, revoked by public key
+	newRomID, newPubKey := device(3)
+	newKey := types.PubKeyDeepCover{RomID: newRomID, Key: newPubKey}
+	k.AddRevocation(ctx, types.Revocation{PubKey: newPubKey})
+
+	require.Equal(t, revoked, deliver(onboarded, auth.StdSignature{}).Code)
+	require.Equal(t, revoked, deliver(stored.Address, auth.StdSignature{}).Code)
+	require.Equal(t, revoked, deliver(sdk.AccAddress(newKey.Address()), auth.StdSignature{PubKey: newKey}).Code)
+
+	// devices that are not revoked and other keys pass
+	freeRomID, freePubKey := device(4)
+	freeKey := types.PubKeyDeepCover{RomID: freeRomID, Key: freePubKey}
+	require.True(t, deliver(sdk.AccAddress(freeKey.Address()), auth.StdSignature{PubKey: freeKey}).IsOK())
+	priv := ed25519.GenPrivKey()
+	require.True(t, deliver(sdk.AccAddress(priv.PubKey().Address()), auth.StdSignature{PubKey: priv.PubKey()}).IsOK())
+}
+
+func TestQueryRevocation(t *testing.T) {
+	ctx, am, k := setupKeeper(t)
+	querier := NewQuerier(k)
+	query := func(params QueryRevocationParams) (result QueryRevocationResult, err sdk.Error) {
+		bz, err2 := k.cdc.MarshalJSON(params)
+		require.Nil(t, err2)
+		res, err := querier(ctx, []string{QueryRevocation}, abci.RequestQuery{Data: bz})
+		if err != nil {
+			return result, err
+		}
+		require.Nil(t, k.cdc.UnmarshalJSON(res, &result))
+		return result, nil
+	}
+
+	romID, pubKey := device(1)
+	revoker := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	revocation := types.Revocation{RomID: romID, Reason: "cloned", Revoker: revoker, Height: 2}
+	k.AddRevocation(ctx, revocation)
+	otherRomID, otherPubKey := device(2)
+	k.AddRevocation(ctx, types.Revocation{PubKey: otherPubKey, Revoker: revoker})
+
+	res, err := query(QueryRevocationParams{RomID: romID})
+	require.Nil(t, err)
+	require.True(t, res.Revoked)
+	require.Equal(t, revocation, *res.Revocation)
+	res, err = query(QueryRevocationParams{PubKey: otherPubKey})
+	require.Nil(t, err)
+	require.True(t, res.Revoked)
+	require.Equal(t, otherPubKey, res.Revocation.PubKey)
+	res, err = query(QueryRevocationParams{RomID: otherRomID, PubKey: pubKey})
+	require.Nil(t, err)
+	require.False(t, res.Revoked)
+	require.Nil(t, res.Revocation)
+
+	// by address, from the onboarding or the DeepCover key of the account
+	onboarded := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	am.SetAccount(ctx, types.NewAppAccount("car", "", "1",
+		types.HsmInfo{RomID: romID, PubKey: pubKey}, auth.NewBaseAccountWithAddress(onboarded)))
+	res, err = query(QueryRevocationParams{Address: onboarded})
+	require.Nil(t, err)
+	require.True(t, res.Revoked)
+	key := types.PubKeyDeepCover{RomID: otherRomID, Key: otherPubKey}
+	signer := auth.NewBaseAccountWithAddress(sdk.AccAddress(key.Address()))
+	require.Nil(t, signer.SetPubKey(key))
+	am.SetAccount(ctx, &types.AppAccount{BaseAccount: signer})
+	res, err = query(QueryRevocationParams{Address: signer.Address})
+	require.Nil(t, err)
+	require.True(t, res.Revoked)
+
+	_, err = query(QueryRevocationParams{Address: sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())})
+	require.NotNil(t, err)
+	require.Equal(t, sdk.CodeUnknownAddress, err.Code())
+}
@@ -0,0 +1,86 @@
+package revocation
+
+import (
+	"github.com/vincepg13/bp-sdk/beyond/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// query endpoints supported by the revocation Querier
+const (
+	QueryRevocations = "revocations"
+	QueryRevocation  = "revocation"
+	QueryRevokers    = "revokers"
+)
+
+func NewQuerier(keeper Keeper) sdk.Querier {
+	return func(ctx sdk.Context, path []string, req abci.RequestQuery) (res []byte, err sdk.Error) {
+		switch path[0] {
+		case QueryRevocations:
+			return queryRevocations(ctx, keeper)
+		case QueryRevocation:
+			return queryRevocation(ctx, req, keeper)
+		case QueryRevokers:
+			return queryRevokers(ctx, keeper)
+		default:
+			return nil, sdk.ErrUnknownRequest("unknown revocation query endpoint")
+		}
+	}
+}
+
+// Params for query 'custom/revocation/revocation'. When Address is set the
+// ROM ID and public key of the device backing the account are looked up,
+// from its onboarding or else from its DeepCover key.
+type QueryRevocationParams struct {
+	RomID   []byte
+	PubKey  []byte
+	Address sdk.AccAddress
+}
+
+// QueryRevocationResult is the status of a single device
+type QueryRevocationResult struct {
+	Revoked    bool              `json:"revoked"`
+	Revocation *types.Revocation `json:"revocation,omitempty"`
+}
+
+func queryRevocations(ctx sdk.Context, keeper Keeper) (res []byte, err sdk.Error) {
+	return marshalJSON(keeper.cdc, keeper.GetRevocations(ctx))
+}
+
+func queryRevokers(ctx sdk.Context, keeper Keeper) (res []byte, err sdk.Error) {
+	return marshalJSON(keeper.cdc, keeper.GetRevokers(ctx))
+}
+
+func queryRevocation(ctx sdk.Context, req abci.RequestQuery, keeper Keeper) (res []byte, err sdk.Error) {
+	var params QueryRevocationParams
+	err2 := keeper.cdc.UnmarshalJSON(req.Data, &params)
+	if err2 != nil {
+		return nil, sdk.ErrUnknownRequest(sdk.AppendMsgToErr("incorrectly formatted request data", err2.Error()))
+	}
+
+	romID, pubKey := params.RomID, params.PubKey
+	if len(params.Address) != 0 {
+		var found bool
+		romID, pubKey, found = keeper.device(ctx, params.Address)
+		if !found {
+			return nil, sdk.ErrUnknownAddress(params.Address.String())
+		}
+	}
+
+	var result QueryRevocationResult
+	if revocation, found := keeper.GetRevocation(ctx, romID, pubKey); found {
+		result.Revoked = true
+		result.Revocation = &revocation
+	}
+	return marshalJSON(keeper.cdc, result)
+}
+
+func marshalJSON(cdc *codec.Codec, o interface{}) (res []byte, err sdk.Error) {
+	bz, err2 := codec.MarshalJSONIndent(cdc, o)
+	if err2 != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not marshal result to JSON", err2.Error()))
+	}
+	return bz, nil
+}
@@ -0,0 +1,15 @@
+// nolint
+package tags
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var (
+	ActionRevokeDevice = []byte("revokeDevice")
+
+	Action  = sdk.TagAction
+	Revoker = "revoker"
+	RomID   = "romId"
+	PubKey  = "pubKey"
+)
@@ -0,0 +1,65 @@
+package revocation
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// MsgRevokeDevice adds the ROM ID and/or public key of a stolen or cloned
+// secure element to the revocation list. Only accounts listed as revokers
+// in genesis may send it.
+type MsgRevokeDevice struct {
+	Revoker sdk.AccAddress
+	RomID   []byte
+	PubKey  []byte
+	Reason  string
+}
+
+// Construct new NewMsgRevokeDevice.
+func NewMsgRevokeDevice(revoker sdk.AccAddress, romID []byte, pubKey []byte, reason string) MsgRevokeDevice {
+	return MsgRevokeDevice{
+		Revoker: revoker,
+		RomID:   romID,
+		PubKey:  pubKey,
+		Reason:  reason,
+	}
+}
+
+// enforce the msg type at compile time
+var _ sdk.Msg = MsgRevokeDevice{}
+
+//nolint
+func (msg MsgRevokeDevice) Type() string                 { return "revoke" }
+func (msg MsgRevokeDevice) Route() string                { return "revocation" }
+func (msg MsgRevokeDevice) GetSigners() []sdk.AccAddress { return []sdk.AccAddress{msg.Revoker} }
+func (msg MsgRevokeDevice) String() string {
+	return fmt.Sprintf("MsgRevokeDevice{Revoker: %v, RomID: %X, PubKey: %X}", msg.Revoker, msg.RomID, msg.PubKey)
+}
+
+// validate MsgRevokeDevice
+func (msg MsgRevokeDevice) ValidateBasic() sdk.Error {
+	if len(msg.Revoker) == 0 {
+		return sdk.ErrUnknownAddress(msg.Revoker.String()).TraceSDK("")
+	}
+	if len(msg.RomID) == 0 && len(msg.PubKey) == 0 {
+		return ErrNoDeviceSpecified(DefaultCodespace)
+	}
+	if len(msg.RomID) != 0 && len(msg.RomID) != 8 {
+		return sdk.ErrUnknownRequest("ROM ID must be 8 bytes")
+	}
+	if len(msg.PubKey) != 0 && len(msg.PubKey) != 64 {
+		return sdk.ErrInvalidPubKey("Public key must be 64 bytes")
+	}
+	return nil
+}
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg MsgRevokeDevice) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
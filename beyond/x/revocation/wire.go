@@ -0,0 +1,8 @@
+package revocation
+
+import "github.com/cosmos/cosmos-sdk/codec"
+
+// Register concrete types on wire codec
+func RegisterCodec(cdc *codec.Codec) {
+	cdc.RegisterConcrete(MsgRevokeDevice{}, "revocation/RevokeDevice", nil)
+}
@@ -0,0 +1,217 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultRevenueDenom is the denomination orders are paid in
+const DefaultRevenueDenom = "byndcoin"
+
+// bucketFormats are the SQLite expressions truncating a unix time to the
+// start of its bucket, weeks start on Monday
+var bucketFormats = map[string]string{
+	"hour":  `strftime('%Y-%m-%dT%H:00:00Z', o.finalize_time, 'unixepoch')`,
+	"day":   `strftime('%Y-%m-%dT00:00:00Z', o.finalize_time, 'unixepoch')`,
+	"week":  `strftime('%Y-%m-%dT00:00:00Z', o.finalize_time, 'unixepoch', 'weekday 0', '-6 days')`,
+	"month": `strftime('%Y-%m-01T00:00:00Z', o.finalize_time, 'unixepoch')`,
+}
+
+// EnergyReport is the energy sold by a station in a time bucket. Charges
+// are in kWh, only finalized orders are counted and they fall in the
+// bucket of their finalizeOrder.
+type EnergyReport struct {
+	Station         string    `json:"station"`
+	Bucket          time.Time `json:"bucket"`
+	Orders          int64     `json:"orders"`
+	Energy          int64     `json:"energy"`
+	AverageSession  float64   `json:"averageSession"`
+	Revenue         int64     `json:"revenue"`
+	EstimatedCharge int64     `json:"estimatedCharge"`
+	// ChargeAccuracy is the actual charge over the estimated charge, 0 if
+	// nothing was estimated
+	ChargeAccuracy float64 `json:"chargeAccuracy"`
+}
+
+// BuyerReport is the energy bought by a buyer
+type BuyerReport struct {
+	Buyer  string `json:"buyer"`
+	Orders int64  `json:"orders"`
+	Energy int64  `json:"energy"`
+	Spent  int64  `json:"spent"`
+}
+
+// AnalyticsFilter selects the finalized orders of the reports
+type AnalyticsFilter struct {
+	Station      string
+	Since, Until time.Time // finalize time range, Until excluded
+	Denom        string
+}
+
+func (f AnalyticsFilter) where() *where {
+	w := &where{}
+	w.add("o.finalize_height IS NOT NULL")
+	if f.Station != "" {
+		w.add("o.seller = ?", f.Station)
+	}
+	if !f.Since.IsZero() {
+		w.add("o.finalize_time >= ?", f.Since.Unix())
+	}
+	if !f.Until.IsZero() {
+		w.add("o.finalize_time < ?", f.Until.Unix())
+	}
+	return w
+}
+
+// paidOrders sums the payments of each order by their recipient and sender
+const paidOrders = `(SELECT order_buyer, order_number, sender, recipient, SUM(amount) AS amount
+	FROM payments WHERE denom = ? AND order_buyer IS NOT NULL
+	GROUP BY order_buyer, order_number, sender, recipient)`
+
+// EnergyReports returns the energy sold per station and bucket, oldest
+// bucket first
+func (s *IndexStore) EnergyReports(bucket string, f AnalyticsFilter) ([]EnergyReport, error) {
+	format, ok := bucketFormats[bucket]
+	if !ok {
+		return nil, fmt.Errorf("invalid bucket %q", bucket)
+	}
+	w := f.where()
+	rows, err := s.db.Query(`SELECT o.seller, `+format+` AS bucket, COUNT(*),
+		SUM(o.total_charge), AVG(o.total_charge), COALESCE(SUM(p.amount), 0), SUM(o.estimated_charge)
+		FROM orders o LEFT JOIN `+paidOrders+` p
+		ON p.order_buyer = o.buyer AND p.order_number = o.number AND p.recipient = o.seller`+w.String()+`
+		GROUP BY o.seller, bucket ORDER BY bucket, o.seller`, append([]interface{}{f.Denom}, w.args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []EnergyReport{}
+	for rows.Next() {
+		var r EnergyReport
+		var bucket string
+		if err := rows.Scan(&r.Station, &bucket, &r.Orders, &r.Energy, &r.AverageSession, &r.Revenue, &r.EstimatedCharge); err != nil {
+			return nil, err
+		}
+		if r.Bucket, err = time.Parse(time.RFC3339, bucket); err != nil {
+			return nil, err
+		}
+		if r.EstimatedCharge > 0 {
+			r.ChargeAccuracy = float64(r.Energy) / float64(r.EstimatedCharge)
+		}
+		reports = append(reports, r)
+	}
+	return reports, rows.Err()
+}
+
+// TopBuyers returns the buyers that bought the most energy
+func (s *IndexStore) TopBuyers(f AnalyticsFilter, limit int) ([]BuyerReport, error) {
+	w := f.where()
+	rows, err := s.db.Query(`SELECT o.buyer, COUNT(*), SUM(o.total_charge), COALESCE(SUM(p.amount), 0)
+		FROM orders o LEFT JOIN `+paidOrders+` p
+		ON p.order_buyer = o.buyer AND p.order_number = o.number AND p.sender = o.buyer`+w.String()+`
+		GROUP BY o.buyer ORDER BY SUM(o.total_charge) DESC, o.buyer LIMIT ?`,
+		append(append([]interface{}{f.Denom}, w.args...), limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []BuyerReport{}
+	for rows.Next() {
+		var r BuyerReport
+		if err := rows.Scan(&r.Buyer, &r.Orders, &r.Energy, &r.Spent); err != nil {
+			return nil, err
+		}
+		reports = append(reports, r)
+	}
+	return reports, rows.Err()
+}
+
+// queryTime parses an optional RFC3339 time or YYYY-MM-DD date parameter
+func queryTime(r *http.Request, name string) (time.Time, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid %s %q, expected RFC3339 or YYYY-MM-DD", name, v)
+}
+
+// queryAnalyticsFilter parses the station, since, until and denom parameters
+func queryAnalyticsFilter(r *http.Request) (f AnalyticsFilter, err error) {
+	if f.Station, err = queryAddress(r, "station"); err != nil {
+		return
+	}
+	if f.Since, err = queryTime(r, "since"); err != nil {
+		return
+	}
+	if f.Until, err = queryTime(r, "until"); err != nil {
+		return
+	}
+	if !f.Since.IsZero() && !f.Until.IsZero() && !f.Since.Before(f.Until) {
+		err = fmt.Errorf("since must be before until")
+		return
+	}
+	if f.Denom = r.URL.Query().Get("denom"); f.Denom == "" {
+		f.Denom = DefaultRevenueDenom
+	}
+	return f, nil
+}
+
+// GetEnergyReports returns the energy sold and revenue per station and
+// time bucket
+func (s *Server) GetEnergyReports(w http.ResponseWriter, r *http.Request) {
+	f, err := queryAnalyticsFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	bucket := r.URL.Query().Get("bucket")
+	if bucket == "" {
+		bucket = "day"
+	}
+	if _, ok := bucketFormats[bucket]; !ok {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid bucket %q, expected hour, day, week or month", bucket))
+		return
+	}
+
+	reports, err := s.index.EnergyReports(bucket, f)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, reports)
+}
+
+// GetTopBuyers returns the buyers that bought the most energy
+func (s *Server) GetTopBuyers(w http.ResponseWriter, r *http.Request) {
+	f, err := queryAnalyticsFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := queryPage(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reports, err := s.index.TopBuyers(f, page.Limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, reports)
+}
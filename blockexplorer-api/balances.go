@@ -0,0 +1,323 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/vincepg13/bp-sdk/beyond/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gorilla/mux"
+)
+
+// Kinds of balance changes
+const (
+	BalanceGenesis  = "genesis"
+	BalanceFee      = "fee"
+	BalanceTransfer = "transfer"
+)
+
+// BalanceChange is a change of the balance of an address in a denom.
+// Balance is the balance after the change, Tx is nil for the genesis.
+type BalanceChange struct {
+	Height  int64     `json:"height"`
+	Time    time.Time `json:"time"`
+	Tx      *string   `json:"tx,omitempty"`
+	Kind    string    `json:"kind"`
+	Address string    `json:"address"`
+	Denom   string    `json:"denom"`
+	Delta   int64     `json:"delta"`
+	Balance int64     `json:"balance"`
+}
+
+// Holder is an address ranked by its balance in a denom. Share is its part
+// of the supply held by all indexed addresses.
+type Holder struct {
+	Rank    int     `json:"rank"`
+	Address string  `json:"address"`
+	Amount  int64   `json:"amount"`
+	Share   float64 `json:"share"`
+}
+
+// RichList is a page of the holders of a denom, richest first
+type RichList struct {
+	Denom   string   `json:"denom"`
+	Supply  int64    `json:"supply"`
+	Holders []Holder `json:"holders"`
+}
+
+// BalanceFilter selects the balance changes of an address, zero fields
+// match everything
+type BalanceFilter struct {
+	Address      string
+	Denom        string
+	From, To     int64     // height range
+	Since, Until time.Time // time range, Until excluded
+	Page
+}
+
+// balanceChanges records the balance changes of a block in order
+type balanceChanges struct {
+	dbTx         *sql.Tx
+	height, time int64
+	seq          int
+}
+
+// add moves the balances of address by sign times coins
+func (c *balanceChanges) add(tx interface{}, kind, address string, coins sdk.Coins, sign int64) error {
+	for _, coin := range coins {
+		if coin.Amount.IsZero() {
+			continue
+		}
+		if !coin.Amount.BigInt().IsInt64() {
+			return fmt.Errorf("amount %s does not fit the index", coin)
+		}
+		delta := sign * coin.Amount.Int64()
+		_, err := c.dbTx.Exec(`INSERT INTO balances (address, denom, amount) VALUES (?, ?, ?)
+			ON CONFLICT (address, denom) DO UPDATE SET amount = amount + excluded.amount`,
+			address, coin.Denom, delta)
+		if err != nil {
+			return err
+		}
+		var balance int64
+		err = c.dbTx.QueryRow(`SELECT amount FROM balances WHERE address = ? AND denom = ?`, address, coin.Denom).Scan(&balance)
+		if err != nil {
+			return err
+		}
+		_, err = c.dbTx.Exec(`INSERT INTO balance_changes (height, seq, time, tx, kind, address, denom, delta, balance)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			c.height, c.seq, c.time, tx, kind, address, coin.Denom, delta, balance)
+		if err != nil {
+			return err
+		}
+		c.seq++
+	}
+	return nil
+}
+
+// applyFee records the fee of a tx, applied or not
+func (c *balanceChanges) applyFee(tx Tx) error {
+	return c.add(tx.Hash, BalanceFee, tx.FeePayer, tx.Fee.Amount, -1)
+}
+
+// applyTx records the send msgs of an applied tx. Other msgs of the app do
+// not move coins.
+func (c *balanceChanges) applyTx(tx Tx) error {
+	for _, msg := range tx.Msgs {
+		send, ok := msg.Value.(Send)
+		if !ok {
+			continue
+		}
+		for _, in := range send.Inputs {
+			if err := c.add(tx.Hash, BalanceTransfer, in.Address, in.Coins, -1); err != nil {
+				return err
+			}
+		}
+		for _, out := range send.Outputs {
+			if err := c.add(tx.Hash, BalanceTransfer, out.Address, out.Coins, 1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyGenesis sets the balances of the genesis accounts at height 0. It
+// fails once a block is indexed and replaces a previous genesis, so it can
+// be retried until the first block is applied.
+func (s *IndexStore) ApplyGenesis(genesisTime time.Time, accounts []Transfer) (err error) {
+	dbTx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dbTx.Rollback()
+		}
+	}()
+
+	var indexed int
+	if err = dbTx.QueryRow(`SELECT COUNT(*) FROM checkpoint`).Scan(&indexed); err != nil {
+		return err
+	}
+	if indexed > 0 {
+		return fmt.Errorf("the genesis can not be applied to an index with blocks")
+	}
+	if _, err = dbTx.Exec(`DELETE FROM balance_changes`); err != nil {
+		return err
+	}
+	if _, err = dbTx.Exec(`DELETE FROM balances`); err != nil {
+		return err
+	}
+
+	changes := &balanceChanges{dbTx: dbTx, time: genesisTime.Unix()}
+	for _, acc := range accounts {
+		if err = changes.add(nil, BalanceGenesis, acc.Address, acc.Coins, 1); err != nil {
+			return fmt.Errorf("genesis account %s: %v", acc.Address, err)
+		}
+	}
+	return dbTx.Commit()
+}
+
+// BalancesFrom returns the height from which balances are tracked, 0 if
+// from the genesis. Addresses start with a balance of 0 at that height.
+func (s *IndexStore) BalancesFrom() (height int64, err error) {
+	err = s.db.QueryRow(`SELECT height FROM balances_from WHERE id = 0`).Scan(&height)
+	return height, err
+}
+
+// BalanceHistory returns the balance changes matching the filter, newest
+// first
+func (s *IndexStore) BalanceHistory(f BalanceFilter) ([]BalanceChange, error) {
+	var w where
+	if f.Address != "" {
+		w.add("address = ?", f.Address)
+	}
+	if f.Denom != "" {
+		w.add("denom = ?", f.Denom)
+	}
+	if f.From > 0 {
+		w.add("height >= ?", f.From)
+	}
+	if f.To > 0 {
+		w.add("height <= ?", f.To)
+	}
+	if !f.Since.IsZero() {
+		w.add("time >= ?", f.Since.Unix())
+	}
+	if !f.Until.IsZero() {
+		w.add("time < ?", f.Until.Unix())
+	}
+	rows, err := s.db.Query(`SELECT height, time, tx, kind, address, denom, delta, balance
+		FROM balance_changes`+w.String()+` ORDER BY height DESC, seq DESC LIMIT ? OFFSET ?`, append(w.args, f.Limit, f.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []BalanceChange{}
+	for rows.Next() {
+		var c BalanceChange
+		var t int64
+		if err := rows.Scan(&c.Height, &t, &c.Tx, &c.Kind, &c.Address, &c.Denom, &c.Delta, &c.Balance); err != nil {
+			return nil, err
+		}
+		c.Time = time.Unix(t, 0).UTC()
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// RichList returns a page of the addresses with a positive balance in
+// denom, richest first
+func (s *IndexStore) RichList(denom string, page Page) (list RichList, err error) {
+	list = RichList{Denom: denom, Holders: []Holder{}}
+	err = s.db.QueryRow(`SELECT COALESCE(SUM(amount), 0) FROM balances WHERE denom = ? AND amount > 0`, denom).Scan(&list.Supply)
+	if err != nil {
+		return list, err
+	}
+	rows, err := s.db.Query(`SELECT address, amount FROM balances WHERE denom = ? AND amount > 0
+		ORDER BY amount DESC, address LIMIT ? OFFSET ?`, denom, page.Limit, page.Offset)
+	if err != nil {
+		return list, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		h := Holder{Rank: page.Offset + len(list.Holders) + 1}
+		if err := rows.Scan(&h.Address, &h.Amount); err != nil {
+			return list, err
+		}
+		h.Share = float64(h.Amount) / float64(list.Supply)
+		list.Holders = append(list.Holders, h)
+	}
+	return list, rows.Err()
+}
+
+// nodeGenesis is the result of the genesis RPC
+type nodeGenesis struct {
+	Genesis struct {
+		GenesisTime time.Time       `json:"genesis_time"`
+		AppState    json.RawMessage `json:"app_state"`
+	} `json:"genesis"`
+}
+
+// genesis returns the genesis time and the coins of the genesis accounts
+func (s *Server) genesis() (genesisTime time.Time, accounts []Transfer, err error) {
+	var res nodeGenesis
+	if err := s.call("genesis", nil, &res); err != nil {
+		return genesisTime, nil, err
+	}
+	var state types.GenesisState
+	if len(res.Genesis.AppState) > 0 {
+		if err := s.cdc.UnmarshalJSON(res.Genesis.AppState, &state); err != nil {
+			return genesisTime, nil, err
+		}
+	}
+	for _, acc := range state.Accounts {
+		accounts = append(accounts, Transfer{Address: acc.Address.String(), Coins: acc.Coins})
+	}
+	return res.Genesis.GenesisTime, accounts, nil
+}
+
+// GetBalanceHistory lists the balance changes of an address, newest first
+func (s *Server) GetBalanceHistory(w http.ResponseWriter, r *http.Request) {
+	f := BalanceFilter{Address: mux.Vars(r)["address"], Denom: r.URL.Query().Get("denom")}
+	var err error
+	if _, err = sdk.AccAddressFromBech32(f.Address); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if f.From, f.To, err = queryHeights(r); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if f.Since, err = queryTime(r, "since"); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if f.Until, err = queryTime(r, "until"); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if f.Page, err = queryPage(r); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	changes, err := s.index.BalanceHistory(f)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, changes)
+}
+
+// GetRichList returns the holders of a denom, richest first
+func (s *Server) GetRichList(w http.ResponseWriter, r *http.Request) {
+	denom := r.URL.Query().Get("denom")
+	if denom == "" {
+		denom = DefaultRevenueDenom
+	}
+	page, err := queryPage(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	list, err := s.index.RichList(denom, page)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
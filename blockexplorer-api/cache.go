@@ -0,0 +1,238 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package main
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache defaults
+const (
+	DefaultCacheSize = 64 << 20 // bytes
+	DefaultStatusTTL = 2 * time.Second
+)
+
+// immutableCacheControl is sent with responses that never change
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// cachedResponse is a recorded successful response
+type cachedResponse struct {
+	key         string
+	contentType string
+	body        []byte
+	etag        string
+	expires     time.Time   // zero for responses that never change
+	value       interface{} // the object encoded in body, nil for recorded responses
+}
+
+// ResponseCache keeps the most recently used responses up to a total body
+// size. Committed blocks and txs never change, so their responses are kept
+// until evicted.
+type ResponseCache struct {
+	maxBytes int
+
+	mtx   sync.Mutex
+	size  int
+	order *list.List // front is the most recently used
+	items map[string]*list.Element
+}
+
+// NewResponseCache returns a cache of at most maxBytes of response bodies
+func NewResponseCache(maxBytes int) *ResponseCache {
+	return &ResponseCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the unexpired response cached under key
+func (c *ResponseCache) Get(key string) (*cachedResponse, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	res := e.Value.(*cachedResponse)
+	if !res.expires.IsZero() && time.Now().After(res.expires) {
+		c.remove(e)
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return res, true
+}
+
+// Add caches a response, evicting the least recently used ones to stay
+// within the size limit
+func (c *ResponseCache) Add(res *cachedResponse) {
+	if len(res.body) > c.maxBytes {
+		return
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if e, ok := c.items[res.key]; ok {
+		c.remove(e)
+	}
+	c.items[res.key] = c.order.PushFront(res)
+	c.size += len(res.body)
+	for c.size > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+// Size returns the number of cached responses and their total body size
+func (c *ResponseCache) Size() (n int, size int) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.order.Len(), c.size
+}
+
+func (c *ResponseCache) remove(e *list.Element) {
+	res := c.order.Remove(e).(*cachedResponse)
+	delete(c.items, res.key)
+	c.size -= len(res.body)
+}
+
+// responseRecorder buffers a response so it can be cached and tagged
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: make(http.Header), status: http.StatusOK}
+}
+
+func (r *responseRecorder) Header() http.Header         { return r.header }
+func (r *responseRecorder) Write(b []byte) (int, error) { return r.body.Write(b) }
+func (r *responseRecorder) WriteHeader(status int)      { r.status = status }
+
+func etag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified returns true if the request already has the response tagged
+// etag
+func notModified(r *http.Request, etag string) bool {
+	for _, tag := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == etag || tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// serve writes a successful response, or 304 if the client has it
+func (res *cachedResponse) serve(w http.ResponseWriter, r *http.Request, cacheControl string) {
+	w.Header().Set("ETag", res.etag)
+	if cacheControl != "" {
+		w.Header().Set("Cache-Control", cacheControl)
+	}
+	if notModified(r, res.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", res.contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res.body)
+}
+
+// record runs the handler and returns its response if it succeeded, after
+// writing any other response to w
+func record(h http.HandlerFunc, w http.ResponseWriter, r *http.Request) *cachedResponse {
+	rec := newResponseRecorder()
+	h(rec, r)
+	if rec.status != http.StatusOK {
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.status)
+		w.Write(rec.body.Bytes())
+		return nil
+	}
+	body := rec.body.Bytes()
+	return &cachedResponse{
+		key:         r.URL.RequestURI(),
+		contentType: rec.header.Get("Content-Type"),
+		body:        body,
+		etag:        etag(body),
+	}
+}
+
+// conditional tags successful responses with an ETag and answers 304 to
+// clients that already have them
+func conditional(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if res := record(h, w, r); res != nil {
+			res.serve(w, r, "")
+		}
+	}
+}
+
+// cached serves successful responses from the cache. A zero ttl is for
+// responses that never change, they are kept until evicted.
+func (s *Server) cached(ttl time.Duration, h http.HandlerFunc) http.HandlerFunc {
+	cacheControl := immutableCacheControl
+	if ttl > 0 {
+		cacheControl = fmt.Sprintf("public, max-age=%d", int(ttl/time.Second))
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if res, ok := s.cache.Get(r.URL.RequestURI()); ok {
+			res.serve(w, r, cacheControl)
+			return
+		}
+		res := record(h, w, r)
+		if res == nil {
+			return
+		}
+		if ttl > 0 {
+			res.expires = time.Now().Add(ttl)
+		}
+		s.cache.Add(res)
+		res.serve(w, r, cacheControl)
+	}
+}
+
+// cachedObject returns the response cached under key if it holds an object.
+// Otherwise fetch is called and its object cached together with its JSON
+// encoding, so the API and GraphQL share one fetch of an object that never
+// changes.
+func (s *Server) cachedObject(key string, fetch func() (interface{}, error)) (*cachedResponse, error) {
+	if res, ok := s.cache.Get(key); ok && res.value != nil {
+		return res, nil
+	}
+	v, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(v); err != nil {
+		return nil, err
+	}
+	res := &cachedResponse{
+		key:         key,
+		contentType: "application/json",
+		body:        body.Bytes(),
+		etag:        etag(body.Bytes()),
+		value:       v,
+	}
+	s.cache.Add(res)
+	return res, nil
+}
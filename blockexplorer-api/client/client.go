@@ -0,0 +1,314 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+// Package client is a typed client of the block explorer API. Its types are
+// written by hand after the schemas of the OpenAPI document served on
+// /openapi.yaml, the explorer tests decode the example of every schema into
+// them.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error is a failed request, StatusCode is the HTTP status of the response
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("explorer: %d %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound returns true if the requested object does not exist
+func IsNotFound(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.StatusCode == http.StatusNotFound
+}
+
+// Client sends requests to an explorer
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// New returns a client of the explorer at baseURL, e.g.
+// http://localhost:8000. A nil httpClient is http.DefaultClient.
+func New(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), http: httpClient}
+}
+
+// get decodes the response to a GET of path into v, statuses other than
+// 200 are returned as an *Error
+func (c *Client) get(ctx context.Context, path string, query url.Values, v interface{}) error {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.http.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	bz, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		var body struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(bz, &body) != nil || body.Error == "" {
+			body.Error = http.StatusText(res.StatusCode)
+		}
+		// the health check returns the nodes with its failure
+		json.Unmarshal(bz, v)
+		return &Error{StatusCode: res.StatusCode, Message: body.Error}
+	}
+	return json.Unmarshal(bz, v)
+}
+
+// values collects the non zero query parameters
+type values url.Values
+
+func (v values) str(name, value string) {
+	if value != "" {
+		v[name] = []string{value}
+	}
+}
+
+func (v values) int(name string, value int64) {
+	if value != 0 {
+		v[name] = []string{strconv.FormatInt(value, 10)}
+	}
+}
+
+func (v values) time(name string, value time.Time) {
+	if !value.IsZero() {
+		v[name] = []string{value.Format(time.RFC3339)}
+	}
+}
+
+// Block returns the block at a height with its decoded txs
+func (c *Client) Block(ctx context.Context, height int64) (*Block, error) {
+	var res struct {
+		Result Block `json:"result"`
+	}
+	if err := c.get(ctx, "/block/"+strconv.FormatInt(height, 10), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res.Result, nil
+}
+
+// Blocks returns the headers of the blocks from..to, at most 20 of them.
+// The latest blocks are returned if both are 0.
+func (c *Client) Blocks(ctx context.Context, from, to int64) (list BlockList, err error) {
+	q := values{}
+	q.int("from", from)
+	q.int("to", to)
+	err = c.get(ctx, "/blocks", url.Values(q), &list)
+	return list, err
+}
+
+// Tx returns the tx with the given hex hash
+func (c *Client) Tx(ctx context.Context, hash string) (*Tx, error) {
+	var tx Tx
+	if err := c.get(ctx, "/tx/"+url.PathEscape(hash), nil, &tx); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// Account returns the account at a bech32 address
+func (c *Client) Account(ctx context.Context, address string) (*Account, error) {
+	var account Account
+	if err := c.get(ctx, "/account/"+url.PathEscape(address), nil, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Validators returns the current validator set
+func (c *Client) Validators(ctx context.Context) (list ValidatorList, err error) {
+	err = c.get(ctx, "/validators", nil, &list)
+	return list, err
+}
+
+// Status returns the state of the chain and of the nodes
+func (c *Client) Status(ctx context.Context) (status Status, err error) {
+	err = c.get(ctx, "/status", nil, &status)
+	return status, err
+}
+
+// Search returns the block, tx or account for a height, tx hash or bech32
+// address
+func (c *Client) Search(ctx context.Context, q string) (res SearchResult, err error) {
+	err = c.get(ctx, "/search", url.Values{"q": {q}}, &res)
+	return res, err
+}
+
+// Health returns the state of the nodes, with an error if none is healthy
+func (c *Client) Health(ctx context.Context) (nodes []Node, err error) {
+	err = c.get(ctx, "/health", nil, &nodes)
+	return nodes, err
+}
+
+// OrderFilter selects orders, zero fields match everything
+type OrderFilter struct {
+	Buyer    string
+	Seller   string
+	Status   string // open or finalized
+	From, To int64  // init height range
+	Limit    int
+	Offset   int
+}
+
+// Orders returns indexed orders, newest first
+func (c *Client) Orders(ctx context.Context, f OrderFilter) (orders []Order, err error) {
+	q := values{}
+	q.str("buyer", f.Buyer)
+	q.str("seller", f.Seller)
+	q.str("status", f.Status)
+	q.int("from", f.From)
+	q.int("to", f.To)
+	q.int("limit", int64(f.Limit))
+	q.int("offset", int64(f.Offset))
+	err = c.get(ctx, "/orders", url.Values(q), &orders)
+	return orders, err
+}
+
+// Order returns an indexed order by its buyer and number
+func (c *Client) Order(ctx context.Context, buyer string, number uint64) (*Order, error) {
+	var order Order
+	path := "/orders/" + url.PathEscape(buyer) + "/" + strconv.FormatUint(number, 10)
+	if err := c.get(ctx, path, nil, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// PaymentFilter selects payments, zero fields match everything
+type PaymentFilter struct {
+	Address  string // sender or recipient
+	From, To int64  // height range
+	Limit    int
+	Offset   int
+}
+
+// Payments returns indexed payments, newest first
+func (c *Client) Payments(ctx context.Context, f PaymentFilter) (payments []Payment, err error) {
+	q := values{}
+	q.str("address", f.Address)
+	q.int("from", f.From)
+	q.int("to", f.To)
+	q.int("limit", int64(f.Limit))
+	q.int("offset", int64(f.Offset))
+	err = c.get(ctx, "/payments", url.Values(q), &payments)
+	return payments, err
+}
+
+// Activity returns the indexed activity of an address
+func (c *Client) Activity(ctx context.Context, address string) (*Activity, error) {
+	var activity Activity
+	if err := c.get(ctx, "/activity/"+url.PathEscape(address), nil, &activity); err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+// IndexStatus returns how far the order index is behind the chain
+func (c *Client) IndexStatus(ctx context.Context) (status IndexStatus, err error) {
+	err = c.get(ctx, "/index/status", nil, &status)
+	return status, err
+}
+
+// AnalyticsFilter selects the finalized orders of the reports, zero fields
+// match everything
+type AnalyticsFilter struct {
+	Station string
+	Since   time.Time // included
+	Until   time.Time // excluded
+	Denom   string    // of the revenue, byndcoin by default
+}
+
+func (f AnalyticsFilter) values() values {
+	q := values{}
+	q.str("station", f.Station)
+	q.time("since", f.Since)
+	q.time("until", f.Until)
+	q.str("denom", f.Denom)
+	return q
+}
+
+// EnergyReports returns the energy sold per station and bucket, one of
+// hour, day, week or month. An empty bucket is a day.
+func (c *Client) EnergyReports(ctx context.Context, bucket string, f AnalyticsFilter) (reports []EnergyReport, err error) {
+	q := f.values()
+	q.str("bucket", bucket)
+	err = c.get(ctx, "/analytics/energy", url.Values(q), &reports)
+	return reports, err
+}
+
+// TopBuyers returns at most limit buyers by energy bought
+func (c *Client) TopBuyers(ctx context.Context, limit int, f AnalyticsFilter) (buyers []BuyerReport, err error) {
+	q := f.values()
+	q.int("limit", int64(limit))
+	err = c.get(ctx, "/analytics/top-buyers", url.Values(q), &buyers)
+	return buyers, err
+}
+
+// BalanceFilter selects the balance changes of an address, zero fields
+// match everything
+type BalanceFilter struct {
+	Denom    string
+	From, To int64     // height range
+	Since    time.Time // included
+	Until    time.Time // excluded
+	Limit    int
+	Offset   int
+}
+
+// BalanceHistory returns the indexed balance changes of an address,
+// newest first
+func (c *Client) BalanceHistory(ctx context.Context, address string, f BalanceFilter) (changes []BalanceChange, err error) {
+	q := values{}
+	q.str("denom", f.Denom)
+	q.int("from", f.From)
+	q.int("to", f.To)
+	q.time("since", f.Since)
+	q.time("until", f.Until)
+	q.int("limit", int64(f.Limit))
+	q.int("offset", int64(f.Offset))
+	err = c.get(ctx, "/balances/"+url.PathEscape(address)+"/history", url.Values(q), &changes)
+	return changes, err
+}
+
+// RichList returns a page of the holders of a denom, byndcoin if empty
+func (c *Client) RichList(ctx context.Context, denom string, limit, offset int) (list RichList, err error) {
+	q := values{}
+	q.str("denom", denom)
+	q.int("limit", int64(limit))
+	q.int("offset", int64(offset))
+	err = c.get(ctx, "/richlist", url.Values(q), &list)
+	return list, err
+}
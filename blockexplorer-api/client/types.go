@@ -0,0 +1,363 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Msg types with a typed value, see Msg
+const (
+	MsgTypeInitOrder     = "initOrder"
+	MsgTypeFinalizeOrder = "finalizeOrder"
+	MsgTypeSend          = "send"
+)
+
+// Search result types
+const (
+	SearchBlock   = "block"
+	SearchTx      = "tx"
+	SearchAccount = "account"
+)
+
+// Block is a block with its decoded txs
+type Block struct {
+	BlockMeta BlockMeta `json:"block_meta"`
+	Block     struct {
+		Data struct {
+			Txs []Tx `json:"txs"`
+		} `json:"data"`
+	} `json:"block"`
+}
+
+// Txs returns the txs of the block
+func (b Block) Txs() []Tx { return b.Block.Data.Txs }
+
+// BlockMeta is the ID and header of a block
+type BlockMeta struct {
+	BlockID struct {
+		Hash string `json:"hash"`
+	} `json:"block_id"`
+	Header Header `json:"header"`
+}
+
+// Header is a block header, counts and heights are decimal strings as
+// returned by Tendermint
+type Header struct {
+	ChainID        string    `json:"chain_id"`
+	Height         string    `json:"height"`
+	Time           time.Time `json:"time"`
+	NumTxs         string    `json:"num_txs"`
+	TotalTxs       string    `json:"total_txs"`
+	AppHash        string    `json:"app_hash"`
+	ValidatorsHash string    `json:"validators_hash"`
+	LastCommitHash string    `json:"last_commit_hash"`
+	ConsensusHash  string    `json:"consensus_hash"`
+}
+
+// BlockList is a page of block headers, newest first
+type BlockList struct {
+	LastHeight int64       `json:"lastHeight"`
+	From       int64       `json:"from"`
+	To         int64       `json:"to"`
+	Blocks     []BlockMeta `json:"blocks"`
+}
+
+// Tx is a decoded tx with its result
+type Tx struct {
+	Hash      string `json:"hash"`
+	Height    int64  `json:"height"`
+	Index     int    `json:"index"`
+	Code      uint32 `json:"code"`      // 0 if the tx was applied
+	GasWanted int64  `json:"gasWanted"` // 0 if the ante handler rejected the tx
+	Log       string `json:"log,omitempty"`
+	Msgs      []Msg  `json:"msgs"`
+	Fee       Fee    `json:"fee"`
+	FeePayer  string `json:"feePayer,omitempty"` // first signer, the fee is deducted from its account
+	Memo      string `json:"memo,omitempty"`
+	Tags      []Tag  `json:"tags"`
+	Error     string `json:"error,omitempty"` // set if the tx could not be decoded
+}
+
+// Msg is a msg of a tx. The value of initOrder, finalizeOrder and send
+// msgs is decoded by InitOrder, FinalizeOrder and Send, other msgs are
+// encoded by the app codec.
+type Msg struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (m Msg) decode(msgType string, v interface{}) error {
+	if m.Type != msgType {
+		return fmt.Errorf("msg is a %s, not a %s", m.Type, msgType)
+	}
+	return json.Unmarshal(m.Value, v)
+}
+
+// InitOrder returns the value of an initOrder msg
+func (m Msg) InitOrder() (msg InitOrder, err error) {
+	return msg, m.decode(MsgTypeInitOrder, &msg)
+}
+
+// FinalizeOrder returns the value of a finalizeOrder msg
+func (m Msg) FinalizeOrder() (msg FinalizeOrder, err error) {
+	return msg, m.decode(MsgTypeFinalizeOrder, &msg)
+}
+
+// Send returns the value of a send msg
+func (m Msg) Send() (msg Send, err error) {
+	return msg, m.decode(MsgTypeSend, &msg)
+}
+
+// InitOrder starts a charging order of the initiator at the recipient
+type InitOrder struct {
+	Initiator       string `json:"initiator"`
+	Recipient       string `json:"recipient"`
+	AgreedPrice     uint64 `json:"agreedPrice"`
+	EstimatedCharge uint64 `json:"estimatedCharge"`
+}
+
+// FinalizeOrder closes the last order of the initiator at the recipient
+type FinalizeOrder struct {
+	Initiator   string `json:"initiator"`
+	Recipient   string `json:"recipient"`
+	TotalAmount uint64 `json:"totalAmount"`
+	TotalCharge uint64 `json:"totalCharge"`
+}
+
+// Send moves coins from the inputs to the outputs
+type Send struct {
+	Inputs  []Transfer `json:"inputs"`
+	Outputs []Transfer `json:"outputs"`
+}
+
+// Transfer is an input or output of a send msg
+type Transfer struct {
+	Address string `json:"address"`
+	Coins   []Coin `json:"coins"`
+}
+
+// Fee is the fee paid by a tx
+type Fee struct {
+	Amount []Coin `json:"amount"`
+	Gas    int64  `json:"gas"`
+}
+
+// Coin is an amount of a denomination, the amount is a decimal integer
+type Coin struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
+// Tag is a result tag of a tx
+type Tag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Account is an account with the fields of the app
+type Account struct {
+	Address       string  `json:"address"`
+	Coins         []Coin  `json:"coins"`
+	PubKey        string  `json:"pubKey,omitempty"`
+	AccountNumber int64   `json:"accountNumber"`
+	Sequence      int64   `json:"sequence"`
+	Name          string  `json:"name"`
+	MacAddress    string  `json:"macAddress"`
+	Price         string  `json:"price"`
+	HsmInfo       HsmInfo `json:"hsmInfo"`
+}
+
+// HsmInfo is the secure element of an account
+type HsmInfo struct {
+	RomID     []byte `json:"romId"`
+	ManID     []byte `json:"manId"`
+	PubKey    []byte `json:"pubKey"`
+	Page      int    `json:"page"`
+	PageData  []byte `json:"pageData"`
+	Authority string `json:"authority"`
+	Verified  bool   `json:"verified"`
+}
+
+// Validator is a member of the validator set
+type Validator struct {
+	Address string `json:"address"`
+	PubKey  struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	} `json:"pubKey"`
+	VotingPower int64 `json:"votingPower"`
+}
+
+// ValidatorList is the validator set at a height
+type ValidatorList struct {
+	Height     int64       `json:"height"`
+	Validators []Validator `json:"validators"`
+}
+
+// Node is the state of a node the explorer reads from
+type Node struct {
+	URL       string    `json:"url"`
+	Healthy   bool      `json:"healthy"`
+	LastCheck time.Time `json:"lastCheck"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+// Status is the state of the chain and of the nodes
+type Status struct {
+	ChainID           string    `json:"chainId"`
+	Moniker           string    `json:"moniker"`
+	Version           string    `json:"version"`
+	LatestBlockHeight int64     `json:"latestBlockHeight"`
+	LatestBlockHash   string    `json:"latestBlockHash"`
+	LatestBlockTime   time.Time `json:"latestBlockTime"`
+	CatchingUp        bool      `json:"catchingUp"`
+	Nodes             []Node    `json:"nodes"`
+}
+
+// SearchResult is the block, tx or account found by a search, as told by
+// Type
+type SearchResult struct {
+	Type    string
+	Block   *Block
+	Tx      *Tx
+	Account *Account
+}
+
+// UnmarshalJSON decodes the value by the type of the result
+func (r *SearchResult) UnmarshalJSON(bz []byte) error {
+	var res struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(bz, &res); err != nil {
+		return err
+	}
+	*r = SearchResult{Type: res.Type}
+	var v interface{}
+	switch res.Type {
+	case SearchBlock:
+		r.Block = new(Block)
+		v = r.Block
+	case SearchTx:
+		r.Tx = new(Tx)
+		v = r.Tx
+	case SearchAccount:
+		r.Account = new(Account)
+		v = r.Account
+	default:
+		return fmt.Errorf("unknown search result type %q", res.Type)
+	}
+	return json.Unmarshal(res.Value, v)
+}
+
+// Order is an indexed order, the finalize fields are nil while it is open
+type Order struct {
+	Buyer           string     `json:"buyer"`
+	Number          uint64     `json:"number"`
+	Seller          string     `json:"seller"`
+	AgreedPrice     uint64     `json:"agreedPrice"`
+	EstimatedCharge uint64     `json:"estimatedCharge"`
+	InitHeight      int64      `json:"initHeight"`
+	InitTime        time.Time  `json:"initTime"`
+	InitTx          string     `json:"initTx"`
+	TotalAmount     *uint64    `json:"totalAmount,omitempty"`
+	TotalCharge     *uint64    `json:"totalCharge,omitempty"`
+	FinalizeHeight  *int64     `json:"finalizeHeight,omitempty"`
+	FinalizeTime    *time.Time `json:"finalizeTime,omitempty"`
+	FinalizeTx      *string    `json:"finalizeTx,omitempty"`
+}
+
+// Payment is a coin transfer of a send msg, linked to the order finalized
+// in the same tx
+type Payment struct {
+	Tx          string    `json:"tx"`
+	Height      int64     `json:"height"`
+	Time        time.Time `json:"time"`
+	Sender      string    `json:"sender"`
+	Recipient   string    `json:"recipient"`
+	Denom       string    `json:"denom"`
+	Amount      int64     `json:"amount"`
+	OrderBuyer  *string   `json:"orderBuyer,omitempty"`
+	OrderNumber *uint64   `json:"orderNumber,omitempty"`
+}
+
+// Activity is the indexed activity of an address
+type Activity struct {
+	Address     string    `json:"address"`
+	FirstHeight int64     `json:"firstHeight"`
+	FirstTime   time.Time `json:"firstTime"`
+	LastHeight  int64     `json:"lastHeight"`
+	LastTime    time.Time `json:"lastTime"`
+	TxCount     int64     `json:"txCount"`
+}
+
+// IndexStatus is the progress of the order index. Balances are tracked
+// from the genesis if BalancesFrom is 0.
+type IndexStatus struct {
+	Checkpoint        int64 `json:"checkpoint"`
+	LatestBlockHeight int64 `json:"latestBlockHeight"`
+	BalancesFrom      int64 `json:"balancesFrom"`
+}
+
+// EnergyReport is the energy a station sold in a time bucket
+type EnergyReport struct {
+	Station         string    `json:"station"`
+	Bucket          time.Time `json:"bucket"`
+	Orders          int64     `json:"orders"`
+	Energy          int64     `json:"energy"`
+	AverageSession  float64   `json:"averageSession"`
+	Revenue         int64     `json:"revenue"`
+	EstimatedCharge int64     `json:"estimatedCharge"`
+	ChargeAccuracy  float64   `json:"chargeAccuracy"`
+}
+
+// BuyerReport is the energy bought by a buyer
+type BuyerReport struct {
+	Buyer  string `json:"buyer"`
+	Orders int64  `json:"orders"`
+	Energy int64  `json:"energy"`
+	Spent  int64  `json:"spent"`
+}
+
+// Kinds of balance changes
+const (
+	BalanceGenesis  = "genesis"
+	BalanceFee      = "fee"
+	BalanceTransfer = "transfer"
+)
+
+// BalanceChange is a change of the balance of an address in a denom,
+// Balance is the balance after it. Tx is nil for the genesis.
+type BalanceChange struct {
+	Height  int64     `json:"height"`
+	Time    time.Time `json:"time"`
+	Tx      *string   `json:"tx,omitempty"`
+	Kind    string    `json:"kind"`
+	Address string    `json:"address"`
+	Denom   string    `json:"denom"`
+	Delta   int64     `json:"delta"`
+	Balance int64     `json:"balance"`
+}
+
+// RichList is a page of the holders of a denom, richest first
+type RichList struct {
+	Denom   string   `json:"denom"`
+	Supply  int64    `json:"supply"`
+	Holders []Holder `json:"holders"`
+}
+
+// Holder is an address ranked by its balance, Share is its part of the
+// supply
+type Holder struct {
+	Rank    int     `json:"rank"`
+	Address string  `json:"address"`
+	Amount  int64   `json:"amount"`
+	Share   float64 `json:"share"`
+}
@@ -0,0 +1,87 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vincepg13/bp-sdk/beyond/types"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	flagLaddr          = "laddr"
+	flagNodes          = "nodes"
+	flagTimeout        = "timeout"
+	flagHealthInterval = "health-interval"
+	flagIndexDB        = "index-db"
+	flagIndexFrom      = "index-from"
+	flagIndexInterval  = "index-interval"
+	flagCacheSize      = "cache-size"
+	flagStatusTTL      = "status-ttl"
+)
+
+// rootCmd runs the API server. Every flag can also be set in the
+// environment, e.g. EXPLORER_NODES for --nodes.
+var rootCmd = &cobra.Command{
+	Use:   "blockexplorer-api",
+	Short: "REST API for the Beyond block explorer",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nodes, err := NewNodePool(strings.Split(viper.GetString(flagNodes), ","), viper.GetDuration(flagTimeout))
+		if err != nil {
+			return err
+		}
+		go nodes.Run(viper.GetDuration(flagHealthInterval), nil)
+
+		server := NewServer(nodes).WithCache(viper.GetInt(flagCacheSize)<<20, viper.GetDuration(flagStatusTTL))
+		go server.feed.Run(nil)
+		if path := viper.GetString(flagIndexDB); path != "" {
+			store, err := OpenIndexStore(path)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+			server.WithIndex(store)
+			go NewIndexer(store, server, viper.GetInt64(flagIndexFrom)).Run(viper.GetDuration(flagIndexInterval), nil)
+		}
+
+		laddr := viper.GetString(flagLaddr)
+		log.Printf("serving the explorer API on %s", laddr)
+		return http.ListenAndServe(laddr, server.Router())
+	},
+}
+
+func init() {
+	rootCmd.Flags().String(flagLaddr, ":8000", "Address the API listens on")
+	rootCmd.Flags().String(flagNodes, "tcp://localhost:26657", "Comma separated Tendermint RPC endpoints, in order of preference")
+	rootCmd.Flags().Duration(flagTimeout, 10*time.Second, "Timeout of a request to a node")
+	rootCmd.Flags().Duration(flagHealthInterval, 10*time.Second, "Interval between node health checks")
+	rootCmd.Flags().Int(flagCacheSize, DefaultCacheSize>>20, "Size of the response cache in MB")
+	rootCmd.Flags().Duration(flagStatusTTL, DefaultStatusTTL, "How long /status responses are cached")
+	rootCmd.Flags().String(flagIndexDB, "", "SQLite database of the order index, the index is disabled if empty")
+	rootCmd.Flags().Int64(flagIndexFrom, 1, "Height to start indexing an empty database from")
+	rootCmd.Flags().Duration(flagIndexInterval, 5*time.Second, "Interval between index catch-ups")
+	viper.BindPFlags(rootCmd.Flags())
+	viper.SetEnvPrefix("explorer")
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	viper.AutomaticEnv()
+}
+
+// our main function
+func main() {
+	types.SetBech32Prefixes()
+	if err := rootCmd.Execute(); err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,336 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GraphQL limits, queries beyond them are rejected before they run
+const (
+	GraphQLMaxDepth       = 10 // nesting of selections
+	GraphQLMaxParallelism = 10 // fields resolved concurrently per query
+	GraphQLMaxAliases     = 20
+	GraphQLMaxComplexity  = 50000
+)
+
+// graphQLListSize is the number of elements assumed for lists without a
+// page size, such as the txs of a block
+const graphQLListSize = 5
+
+// graphQLLists holds the schema fields returning lists
+var graphQLLists = func() map[string]bool {
+	lists := map[string]bool{}
+	for _, m := range regexp.MustCompile(`(\w+)(\([^)]*\))?: \[`).FindAllStringSubmatch(graphQLSchema, -1) {
+		lists[m[1]] = true
+	}
+	return lists
+}()
+
+// checkQueryLimits rejects queries with too many aliases or too complex to
+// run. The complexity is the number of fields the query resolves, the
+// fields below a list are counted once per element of the list: the page
+// size for blocks and orders, graphQLListSize for other lists. Queries that
+// do not parse are left to the GraphQL executor to report.
+func checkQueryLimits(query string) error {
+	doc, err := parseQueryShape(query)
+	if err != nil {
+		return nil
+	}
+	if doc.aliases > GraphQLMaxAliases {
+		return fmt.Errorf("query has %d aliases, at most %d are allowed", doc.aliases, GraphQLMaxAliases)
+	}
+	for _, op := range doc.operations {
+		if doc.complexity(op, 0) > GraphQLMaxComplexity {
+			return fmt.Errorf("query complexity is above the limit of %d", GraphQLMaxComplexity)
+		}
+	}
+	return nil
+}
+
+// selection is a field, fragment spread or inline fragment of a query
+type selection struct {
+	field    string
+	limit    int    // value of a literal limit argument, 0 if not set
+	fragment string // name of a spread fragment
+	children []selection
+}
+
+// queryShape is what checkQueryLimits needs to know of a query document
+type queryShape struct {
+	operations [][]selection
+	fragments  map[string][]selection
+	aliases    int
+}
+
+// complexity returns the complexity of a selection set, at most one above
+// GraphQLMaxComplexity. nesting counts the fields and fragments it is in.
+func (q *queryShape) complexity(set []selection, nesting int) int {
+	if nesting > 2*GraphQLMaxDepth {
+		// deeper queries and cyclic fragments fail validation
+		return 0
+	}
+	total := 0
+	for _, sel := range set {
+		switch {
+		case sel.fragment != "":
+			total += q.complexity(q.fragments[sel.fragment], nesting+1)
+		case sel.field == "":
+			total += q.complexity(sel.children, nesting+1)
+		default:
+			n := listSize(sel)
+			if n > GraphQLMaxComplexity {
+				n = GraphQLMaxComplexity
+			}
+			total += 1 + n*q.complexity(sel.children, nesting+1)
+		}
+		if total > GraphQLMaxComplexity {
+			return GraphQLMaxComplexity + 1
+		}
+	}
+	return total
+}
+
+// listSize returns how many times the children of a field are resolved
+func listSize(sel selection) int {
+	switch {
+	case !graphQLLists[sel.field]:
+		return 1
+	case sel.limit > 0:
+		return sel.limit
+	case sel.field == "blocks":
+		return MaxBlocksPage
+	case sel.field == "orders":
+		return DefaultPageLimit
+	default:
+		return graphQLListSize
+	}
+}
+
+// queryParser reads the selection sets of a GraphQL document, skipping
+// arguments, variables and directives
+type queryParser struct {
+	tokens []string
+	pos    int
+	shape  *queryShape
+}
+
+var errQuerySyntax = fmt.Errorf("invalid query")
+
+func parseQueryShape(query string) (*queryShape, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens, shape: &queryShape{fragments: map[string][]selection{}}}
+	for p.peek() != "" {
+		if err := p.definition(); err != nil {
+			return nil, err
+		}
+	}
+	return p.shape, nil
+}
+
+func (p *queryParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *queryParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *queryParser) definition() error {
+	switch p.peek() {
+	case "{":
+		set, err := p.selectionSet()
+		p.shape.operations = append(p.shape.operations, set)
+		return err
+	case "fragment":
+		p.next()
+		name := p.next()
+		p.next() // on
+		p.next() // type
+		p.directives()
+		set, err := p.selectionSet()
+		p.shape.fragments[name] = set
+		return err
+	case "query", "mutation", "subscription":
+		p.next()
+		for t := p.peek(); t != "{" && t != ""; t = p.peek() {
+			if t == "(" {
+				p.skipBalanced("(", ")")
+			} else {
+				p.next()
+			}
+		}
+		set, err := p.selectionSet()
+		p.shape.operations = append(p.shape.operations, set)
+		return err
+	default:
+		return errQuerySyntax
+	}
+}
+
+func (p *queryParser) selectionSet() ([]selection, error) {
+	if p.next() != "{" {
+		return nil, errQuerySyntax
+	}
+	var set []selection
+	for p.peek() != "}" {
+		if p.peek() == "" {
+			return nil, errQuerySyntax
+		}
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, sel)
+	}
+	p.next()
+	return set, nil
+}
+
+func (p *queryParser) selection() (sel selection, err error) {
+	if p.peek() == "..." {
+		p.next()
+		if p.peek() == "on" || p.peek() == "@" || p.peek() == "{" {
+			if p.peek() == "on" {
+				p.next()
+				p.next()
+			}
+			p.directives()
+			sel.children, err = p.selectionSet()
+			return sel, err
+		}
+		sel.fragment = p.next()
+		p.directives()
+		return sel, nil
+	}
+
+	sel.field = p.next()
+	if !isName(sel.field) {
+		return sel, errQuerySyntax
+	}
+	if p.peek() == ":" {
+		p.next()
+		p.shape.aliases++
+		sel.field = p.next()
+	}
+	if p.peek() == "(" {
+		sel.limit = p.arguments()
+	}
+	p.directives()
+	if p.peek() == "{" {
+		sel.children, err = p.selectionSet()
+	}
+	return sel, err
+}
+
+// arguments skips the arguments of a field and returns its literal limit
+func (p *queryParser) arguments() (limit int) {
+	start := p.pos
+	p.skipBalanced("(", ")")
+	args := p.tokens[start:p.pos]
+	for i := 0; i+2 < len(args); i++ {
+		if args[i] == "limit" && args[i+1] == ":" {
+			limit, _ = strconv.Atoi(args[i+2])
+		}
+	}
+	return limit
+}
+
+func (p *queryParser) directives() {
+	for p.peek() == "@" {
+		p.next()
+		p.next()
+		if p.peek() == "(" {
+			p.skipBalanced("(", ")")
+		}
+	}
+}
+
+func (p *queryParser) skipBalanced(open, close string) {
+	depth := 0
+	for t := p.peek(); t != ""; t = p.peek() {
+		p.next()
+		switch t {
+		case open:
+			depth++
+		case close:
+			depth--
+		}
+		if depth == 0 {
+			return
+		}
+	}
+}
+
+func isName(t string) bool {
+	return t != "" && (t[0] == '_' || 'a' <= t[0] && t[0] <= 'z' || 'A' <= t[0] && t[0] <= 'Z')
+}
+
+// lexQuery splits a GraphQL document into names, numbers, punctuators and
+// strings, dropping white space, commas and comments
+func lexQuery(query string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(query[i:], `"""`):
+			end := strings.Index(query[i+3:], `"""`)
+			if end < 0 {
+				return nil, errQuerySyntax
+			}
+			tokens = append(tokens, query[i:i+end+6])
+			i += end + 6
+		case c == '"':
+			j := i + 1
+			for j < len(query) && query[j] != '"' {
+				if query[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(query) {
+				return nil, errQuerySyntax
+			}
+			tokens = append(tokens, query[i:j+1])
+			i = j + 1
+		case strings.HasPrefix(query[i:], "..."):
+			tokens = append(tokens, "...")
+			i += 3
+		case strings.IndexByte("!$()[]{}:=@|&", c) >= 0:
+			tokens = append(tokens, string(c))
+			i++
+		default:
+			j := i
+			for j < len(query) && (isName(query[j:j+1]) || query[j] == '-' || query[j] == '.' || query[j] == '+' || '0' <= query[j] && query[j] <= '9') {
+				j++
+			}
+			if j == i {
+				return nil, errQuerySyntax
+			}
+			tokens = append(tokens, query[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
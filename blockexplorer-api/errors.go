@@ -0,0 +1,66 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// JSON-RPC error codes of Tendermint
+const (
+	// rpcInvalidParams is returned for parameters that do not parse
+	rpcInvalidParams = -32602
+	// rpcInternalError is returned for every error of an RPC handler, such
+	// as a height the node does not have
+	rpcInternalError = -32603
+)
+
+// ErrorResponse is the body of every failed request
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON writes v with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("writing response: %v", err)
+	}
+}
+
+// writeError writes a JSON error body with the given status
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, ErrorResponse{Error: msg})
+}
+
+// errorStatus maps an error of the node pool to an HTTP status. Objects
+// known not to exist are reported as errNotFound by the server, other
+// errors of the nodes are passed on as a bad gateway.
+func errorStatus(err error) int {
+	switch err := err.(type) {
+	case errNotFound:
+		return http.StatusNotFound
+	case *RPCError:
+		if err.Code == rpcInvalidParams {
+			return http.StatusBadRequest
+		}
+		return http.StatusBadGateway
+	default:
+		if err == ErrNoNodes {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusBadGateway
+	}
+}
+
+// writeUpstreamError writes an error of the node pool with its status
+func writeUpstreamError(w http.ResponseWriter, err error) {
+	writeError(w, errorStatus(err), err.Error())
+}
@@ -0,0 +1,411 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Feed event types
+const (
+	EventBlock = "block"
+	EventOrder = "order"
+)
+
+const (
+	// feedBuffer is the number of events queued for a client, slower
+	// clients are disconnected
+	feedBuffer = 64
+	// pingPeriod keeps idle connections to clients and nodes alive
+	pingPeriod = 30 * time.Second
+	// nodeReadTimeout is how long the node may stay silent, it sends pings
+	// and a block every few seconds
+	nodeReadTimeout  = 3 * pingPeriod
+	maxReconnectWait = 30 * time.Second
+)
+
+// FeedEvent is a message pushed to feed clients
+type FeedEvent struct {
+	Type  string      `json:"type"`
+	Block *Result     `json:"block,omitempty"`
+	Order *OrderEvent `json:"order,omitempty"`
+}
+
+// OrderEvent is an initOrder or finalizeOrder applied in a block
+type OrderEvent struct {
+	Action string      `json:"action"`
+	Buyer  string      `json:"buyer"`
+	Seller string      `json:"seller"`
+	Number uint64      `json:"number"`
+	Height int64       `json:"height"`
+	Time   time.Time   `json:"time"`
+	Tx     string      `json:"tx"`
+	Value  interface{} `json:"value"`
+}
+
+// feedFilter selects the events sent to a client
+type feedFilter struct {
+	blocks, orders bool
+	buyer, seller  string
+	action         string
+}
+
+func (f feedFilter) match(e FeedEvent) bool {
+	switch e.Type {
+	case EventBlock:
+		return f.blocks
+	case EventOrder:
+		return f.orders &&
+			(f.buyer == "" || f.buyer == e.Order.Buyer) &&
+			(f.seller == "" || f.seller == e.Order.Seller) &&
+			(f.action == "" || f.action == e.Order.Action)
+	}
+	return false
+}
+
+type feedClient struct {
+	filter feedFilter
+	send   chan []byte
+}
+
+// Feed follows new blocks through a WebSocket subscription to the event
+// bus of a node and pushes them, decoded, to its clients together with the
+// order events they contain. When the connection to the node drops it
+// reconnects, to the next node if needed, and publishes the blocks it
+// missed in between.
+type Feed struct {
+	server   *Server
+	upgrader websocket.Upgrader
+
+	mtx     sync.Mutex
+	clients map[*feedClient]bool
+	last    int64 // height of the last published block
+}
+
+// NewFeed returns a feed fetching blocks through the server
+func NewFeed(server *Server) *Feed {
+	return &Feed{
+		server: server,
+		upgrader: websocket.Upgrader{
+			// dashboards are served from other origins
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		clients: make(map[*feedClient]bool),
+	}
+}
+
+// Run follows the chain until stop is closed
+func (f *Feed) Run(stop <-chan struct{}) {
+	wait := time.Second
+	for {
+		started := time.Now()
+		err := f.follow(stop)
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		// back off while the nodes are unreachable
+		if time.Since(started) > maxReconnectWait {
+			wait = time.Second
+		}
+		log.Printf("feed disconnected, reconnecting in %v: %v", wait, err)
+		select {
+		case <-time.After(wait):
+		case <-stop:
+			return
+		}
+		if wait *= 2; wait > maxReconnectWait {
+			wait = maxReconnectWait
+		}
+	}
+}
+
+// dial connects to the event bus of the first reachable node
+func (f *Feed) dial() (*websocket.Conn, error) {
+	err := ErrNoNodes
+	for _, endpoint := range f.server.nodes.Endpoints() {
+		var conn *websocket.Conn
+		wsURL := "ws" + strings.TrimPrefix(endpoint, "http") + "/websocket"
+		conn, _, err = websocket.DefaultDialer.Dial(wsURL, nil)
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// follow subscribes to new blocks and publishes them until the connection
+// fails or stop is closed
+func (f *Feed) follow(stop <-chan struct{}) error {
+	conn, err := f.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	subscribe := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      "feed",
+		"method":  "subscribe",
+		"params":  map[string]string{"query": "tm.event='NewBlock'"},
+	}
+	if err := conn.WriteJSON(subscribe); err != nil {
+		return err
+	}
+	conn.SetReadDeadline(time.Now().Add(nodeReadTimeout))
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(nodeReadTimeout))
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+
+	// blocks committed while disconnected
+	status, err := f.server.status()
+	if err != nil {
+		return err
+	}
+	if err := f.publishUpTo(status.LatestBlockHeight); err != nil {
+		return err
+	}
+
+	for {
+		var msg struct {
+			Result struct {
+				Data struct {
+					Value struct {
+						Block struct {
+							Header struct {
+								Height string `json:"height"`
+							} `json:"header"`
+						} `json:"block"`
+					} `json:"value"`
+				} `json:"data"`
+			} `json:"result"`
+			Error *RPCError `json:"error"`
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			return err
+		}
+		conn.SetReadDeadline(time.Now().Add(nodeReadTimeout))
+		if msg.Error != nil {
+			return msg.Error
+		}
+		// the subscription is confirmed with an empty result
+		height := msg.Result.Data.Value.Block.Header.Height
+		if height == "" {
+			continue
+		}
+		h, err := strconv.ParseInt(height, 10, 64)
+		if err != nil {
+			return err
+		}
+		if err := f.publishUpTo(h); err != nil {
+			return err
+		}
+	}
+}
+
+// publishUpTo publishes the blocks after the last published one up to
+// height. The first call only records the height, clients get live blocks.
+func (f *Feed) publishUpTo(height int64) error {
+	f.mtx.Lock()
+	last := f.last
+	if last == 0 {
+		f.last = height
+	}
+	f.mtx.Unlock()
+	if last == 0 {
+		return nil
+	}
+
+	for h := last + 1; h <= height; h++ {
+		block, err := f.server.block(h)
+		if err != nil {
+			return fmt.Errorf("block %d: %v", h, err)
+		}
+		f.publish(block)
+
+		f.mtx.Lock()
+		f.last = h
+		f.mtx.Unlock()
+	}
+	return nil
+}
+
+// orderEvents returns the order events of the applied txs of a block
+func orderEvents(block Result) (events []OrderEvent) {
+	height, _ := strconv.ParseInt(block.Block_meta.Header.Height, 10, 64)
+	for _, tx := range block.Block.Data.Txs {
+		if tx.Code != 0 || tx.Error != "" {
+			continue
+		}
+		numbers, err := orderNumbers(tx)
+		if err != nil {
+			continue
+		}
+		for _, msg := range tx.Msgs {
+			event := OrderEvent{Action: msg.Type, Height: height, Time: block.Block_meta.Header.Time, Tx: tx.Hash, Value: msg.Value}
+			switch v := msg.Value.(type) {
+			case InitOrder:
+				event.Buyer, event.Seller = v.Initiator, v.Recipient
+			case FinalizeOrder:
+				event.Buyer, event.Seller = v.Initiator, v.Recipient
+			default:
+				continue
+			}
+			if len(numbers) > 0 {
+				event.Number, numbers = numbers[0], numbers[1:]
+			}
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// publish queues the events of a block to the matching clients
+func (f *Feed) publish(block Result) {
+	events := []FeedEvent{{Type: EventBlock, Block: &block}}
+	for _, order := range orderEvents(block) {
+		order := order
+		events = append(events, FeedEvent{Type: EventOrder, Order: &order})
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, e := range events {
+		bz, err := json.Marshal(e)
+		if err != nil {
+			log.Printf("encoding feed event: %v", err)
+			continue
+		}
+		for c := range f.clients {
+			if !c.filter.match(e) {
+				continue
+			}
+			select {
+			case c.send <- bz:
+			default:
+				// the client does not keep up, drop it
+				delete(f.clients, c)
+				close(c.send)
+			}
+		}
+	}
+}
+
+// parseFeedFilter reads the events, buyer, seller and action parameters
+func parseFeedFilter(r *http.Request) (filter feedFilter, err error) {
+	q := r.URL.Query()
+	events := q.Get("events")
+	if events == "" {
+		events = "blocks,orders"
+	}
+	for _, e := range strings.Split(events, ",") {
+		switch e {
+		case "blocks":
+			filter.blocks = true
+		case "orders":
+			filter.orders = true
+		default:
+			return filter, fmt.Errorf("invalid event %q, expected blocks or orders", e)
+		}
+	}
+	if filter.buyer, err = queryAddress(r, "buyer"); err != nil {
+		return filter, err
+	}
+	if filter.seller, err = queryAddress(r, "seller"); err != nil {
+		return filter, err
+	}
+	switch filter.action = q.Get("action"); filter.action {
+	case "", MsgTypeInitOrder, MsgTypeFinalizeOrder:
+	default:
+		return filter, errors.New("invalid action, expected initOrder or finalizeOrder")
+	}
+	return filter, nil
+}
+
+// ServeWS upgrades the request to a WebSocket and streams the events
+// matching its filter until the client disconnects
+func (f *Feed) ServeWS(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFeedFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	conn, err := f.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader already replied
+		return
+	}
+	defer conn.Close()
+
+	client := &feedClient{filter: filter, send: make(chan []byte, feedBuffer)}
+	f.mtx.Lock()
+	f.clients[client] = true
+	f.mtx.Unlock()
+	defer func() {
+		f.mtx.Lock()
+		if f.clients[client] {
+			delete(f.clients, client)
+			close(client.send)
+		}
+		f.mtx.Unlock()
+	}()
+
+	// the client only sends control messages, reading detects the close
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(pingPeriod)
+	defer ping.Stop()
+	for {
+		select {
+		case bz, ok := <-client.send:
+			if !ok {
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "too slow"), time.Now().Add(time.Second))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(pingPeriod))
+			if err := conn.WriteMessage(websocket.TextMessage, bz); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingPeriod)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
@@ -0,0 +1,859 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// graphQLSchema is served on /graphql. Heights, indexes and order numbers
+// are Ints, 64 bit amounts are decimal Strings and times are RFC3339.
+// Orders and activity come from the order index and fail while it is
+// disabled, everything else is read from the nodes.
+const graphQLSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	# block at a height, null above the latest block
+	block(height: Int!): Block
+	# headers of the blocks from..to, at most 20 of them, latest if unset
+	blocks(from: Int, to: Int): [Block!]!
+	tx(hash: String!): Tx
+	account(address: String!): Account
+	order(buyer: String!, number: Int!): Order
+	orders(buyer: String, seller: String, status: OrderStatus, from: Int, to: Int, limit: Int, offset: Int): [Order!]!
+	validators: [Validator!]!
+	status: Status!
+}
+
+enum OrderStatus {
+	OPEN
+	FINALIZED
+}
+
+type Block {
+	height: Int!
+	hash: String!
+	chainId: String!
+	time: String!
+	numTxs: Int!
+	totalTxs: Int!
+	appHash: String!
+	txs: [Tx!]!
+}
+
+type Tx {
+	hash: String!
+	height: Int!
+	index: Int!
+	# 0 if the tx was applied
+	code: Int!
+	log: String
+	memo: String
+	# set if the tx could not be decoded
+	error: String
+	fee: Fee!
+	# first signer, the fee is deducted from its account
+	feePayer: String
+	msgs: [Msg!]!
+	tags: [Tag!]!
+	# accounts of the signers, parties and recipients of the msgs
+	accounts: [Account!]!
+	# orders initiated or finalized by the tx
+	orders: [Order!]!
+}
+
+type Fee {
+	amount: [Coin!]!
+	gas: String!
+}
+
+type Coin {
+	denom: String!
+	amount: String!
+}
+
+type Tag {
+	key: String!
+	value: String!
+}
+
+type Msg {
+	type: String!
+	# the msg as returned by the REST routes
+	value: String!
+	initOrder: InitOrder
+	finalizeOrder: FinalizeOrder
+	send: Send
+}
+
+type InitOrder {
+	initiator: Account
+	recipient: Account
+	agreedPrice: String!
+	estimatedCharge: String!
+}
+
+type FinalizeOrder {
+	initiator: Account
+	recipient: Account
+	totalAmount: String!
+	totalCharge: String!
+}
+
+type Send {
+	inputs: [Transfer!]!
+	outputs: [Transfer!]!
+}
+
+type Transfer {
+	address: String!
+	account: Account
+	coins: [Coin!]!
+}
+
+type Account {
+	address: String!
+	name: String!
+	macAddress: String!
+	price: String!
+	coins: [Coin!]!
+	pubKey: String
+	accountNumber: String!
+	sequence: String!
+	hsmInfo: HsmInfo!
+	# orders the account bought or sold, newest first
+	orders(status: OrderStatus, limit: Int, offset: Int): [Order!]!
+	activity: Activity
+}
+
+type HsmInfo {
+	romId: String!
+	manId: String!
+	pubKey: String!
+	page: Int!
+	authority: String!
+}
+
+type Activity {
+	firstHeight: Int!
+	firstTime: String!
+	lastHeight: Int!
+	lastTime: String!
+	txCount: Int!
+}
+
+type Order {
+	buyer: String!
+	number: Int!
+	seller: String!
+	buyerAccount: Account
+	sellerAccount: Account
+	agreedPrice: String!
+	estimatedCharge: String!
+	initHeight: Int!
+	initTime: String!
+	initTx: Tx
+	finalized: Boolean!
+	totalAmount: String
+	totalCharge: String
+	finalizeHeight: Int
+	finalizeTime: String
+	finalizeTx: Tx
+}
+
+type Validator {
+	address: String!
+	# amino JSON of the public key
+	pubKey: String!
+	votingPower: String!
+}
+
+type Status {
+	chainId: String!
+	moniker: String!
+	version: String!
+	latestBlockHeight: Int!
+	latestBlockHash: String!
+	latestBlockTime: String!
+	catchingUp: Boolean!
+}
+`
+
+// GraphQLRequest is the body of a POST to /graphql, GET requests pass the
+// same fields as query parameters with the variables JSON encoded
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// ServeGraphQL executes a GraphQL query. Errors of single fields are
+// returned next to the data as GraphQL does, only invalid requests and
+// queries beyond the limits fail.
+func (s *Server) ServeGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req GraphQLRequest
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+			return
+		}
+	} else {
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				writeError(w, http.StatusBadRequest, "invalid variables: "+err.Error())
+				return
+			}
+		}
+	}
+	if req.Query == "" {
+		writeError(w, http.StatusBadRequest, "missing query")
+		return
+	}
+	if err := checkQueryLimits(req.Query); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), loaderKey{}, newLoader(s))
+	writeJSON(w, http.StatusOK, s.graphql.Exec(ctx, req.Query, req.OperationName, req.Variables))
+}
+
+// loader caches the accounts read while executing one query, as the same
+// parties show up in many txs and orders
+type loader struct {
+	s        *Server
+	mtx      sync.Mutex
+	accounts map[string]*accountLoad
+}
+
+type accountLoad struct {
+	once    sync.Once
+	account *Account
+	err     error
+}
+
+type loaderKey struct{}
+
+func newLoader(s *Server) *loader {
+	return &loader{s: s, accounts: make(map[string]*accountLoad)}
+}
+
+func loaderFrom(ctx context.Context, s *Server) *loader {
+	if l, ok := ctx.Value(loaderKey{}).(*loader); ok {
+		return l
+	}
+	return newLoader(s)
+}
+
+// account returns the account at a bech32 address, nil if there is none
+func (l *loader) account(address string) (*Account, error) {
+	l.mtx.Lock()
+	load, ok := l.accounts[address]
+	if !ok {
+		load = &accountLoad{}
+		l.accounts[address] = load
+	}
+	l.mtx.Unlock()
+
+	load.once.Do(func() {
+		addr, err := sdk.AccAddressFromBech32(address)
+		if err != nil {
+			load.err = err
+			return
+		}
+		account, err := l.s.account(addr)
+		switch err.(type) {
+		case nil:
+			load.account = &account
+		case errNotFound:
+		default:
+			load.err = err
+		}
+	})
+	return load.account, load.err
+}
+
+func (l *loader) accountResolver(address string) (*accountResolver, error) {
+	account, err := l.account(address)
+	if account == nil || err != nil {
+		return nil, err
+	}
+	return &accountResolver{s: l.s, account: *account}, nil
+}
+
+func optString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func optInt32(n *int32) int64 {
+	if n == nil {
+		return 0
+	}
+	return int64(*n)
+}
+
+// orderPage returns the page of a list field, the limits are the ones of
+// the REST routes
+func orderPage(limit, offset *int32) (Page, error) {
+	page := Page{Limit: DefaultPageLimit, Offset: int(optInt32(offset))}
+	if limit != nil {
+		page.Limit = int(*limit)
+	}
+	if page.Limit < 0 || page.Offset < 0 {
+		return page, fmt.Errorf("limit and offset must not be negative")
+	}
+	if page.Limit > MaxPageLimit {
+		return page, fmt.Errorf("limit must be at most %d", MaxPageLimit)
+	}
+	return page, nil
+}
+
+func orderStatus(status *string) *bool {
+	if status == nil {
+		return nil
+	}
+	finalized := *status == "FINALIZED"
+	return &finalized
+}
+
+//_______________________________________________________________________
+
+type rootResolver struct {
+	s *Server
+}
+
+func (r *rootResolver) Block(args struct{ Height int32 }) (*blockResolver, error) {
+	if args.Height < 1 {
+		return nil, fmt.Errorf("invalid block height %d", args.Height)
+	}
+	_, block, err := r.s.cachedBlock(int64(args.Height))
+	if err != nil {
+		if _, ok := err.(errNotFound); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &blockResolver{s: r.s, meta: block.Block_meta, txs: block.Block.Data.Txs}, nil
+}
+
+func (r *rootResolver) Blocks(args struct{ From, To *int32 }) ([]*blockResolver, error) {
+	from, to, err := blockRange(optInt32(args.From), optInt32(args.To))
+	if err != nil {
+		return nil, err
+	}
+	list, err := r.s.blocks(from, to)
+	if err != nil {
+		return nil, err
+	}
+	blocks := []*blockResolver{}
+	for _, meta := range list.Blocks {
+		blocks = append(blocks, &blockResolver{s: r.s, meta: meta})
+	}
+	return blocks, nil
+}
+
+func (r *rootResolver) Tx(args struct{ Hash string }) (*txResolver, error) {
+	if !txHashRegexp.MatchString(args.Hash) {
+		return nil, fmt.Errorf("invalid tx hash %q", args.Hash)
+	}
+	return r.s.txResolver(args.Hash)
+}
+
+func (r *rootResolver) Account(ctx context.Context, args struct{ Address string }) (*accountResolver, error) {
+	return loaderFrom(ctx, r.s).accountResolver(args.Address)
+}
+
+func (r *rootResolver) Order(args struct {
+	Buyer  string
+	Number int32
+}) (*orderResolver, error) {
+	if r.s.index == nil {
+		return nil, errIndexDisabled
+	}
+	order, found, err := r.s.index.Order(args.Buyer, uint64(args.Number))
+	if !found || err != nil {
+		return nil, err
+	}
+	return &orderResolver{s: r.s, order: order}, nil
+}
+
+func (r *rootResolver) Orders(args struct {
+	Buyer, Seller *string
+	Status        *string
+	From, To      *int32
+	Limit, Offset *int32
+}) ([]*orderResolver, error) {
+	f := OrderFilter{Finalized: orderStatus(args.Status), From: optInt32(args.From), To: optInt32(args.To)}
+	if args.Buyer != nil {
+		f.Buyer = *args.Buyer
+	}
+	if args.Seller != nil {
+		f.Seller = *args.Seller
+	}
+	var err error
+	if f.Page, err = orderPage(args.Limit, args.Offset); err != nil {
+		return nil, err
+	}
+	return r.s.orderResolvers(f)
+}
+
+func (r *rootResolver) Validators() ([]*validatorResolver, error) {
+	list, err := r.s.validators()
+	if err != nil {
+		return nil, err
+	}
+	validators := []*validatorResolver{}
+	for _, v := range list.Validators {
+		validators = append(validators, &validatorResolver{v})
+	}
+	return validators, nil
+}
+
+func (r *rootResolver) Status() (*statusResolver, error) {
+	status, err := r.s.status()
+	if err != nil {
+		return nil, err
+	}
+	return &statusResolver{status}, nil
+}
+
+// txResolver returns the tx with the given hash, nil if there is none
+func (s *Server) txResolver(hash string) (*txResolver, error) {
+	tx, err := s.tx(hash)
+	if err != nil {
+		if _, ok := err.(errNotFound); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &txResolver{s: s, tx: tx}, nil
+}
+
+func (s *Server) orderResolvers(f OrderFilter) ([]*orderResolver, error) {
+	if s.index == nil {
+		return nil, errIndexDisabled
+	}
+	orders, err := s.index.Orders(f)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := []*orderResolver{}
+	for _, order := range orders {
+		resolvers = append(resolvers, &orderResolver{s: s, order: order})
+	}
+	return resolvers, nil
+}
+
+//_______________________________________________________________________
+
+// blockResolver reads the txs of blocks listed by their header only once
+// they are asked for
+type blockResolver struct {
+	s    *Server
+	meta BlockMeta
+	txs  []Tx // nil until read
+}
+
+func parseCount(s string) int32 {
+	n, _ := strconv.ParseInt(s, 10, 32)
+	return int32(n)
+}
+
+func (r *blockResolver) Height() int32   { return parseCount(r.meta.Header.Height) }
+func (r *blockResolver) Hash() string    { return r.meta.Block_id.Hash }
+func (r *blockResolver) ChainId() string { return r.meta.Header.Chain_id }
+func (r *blockResolver) Time() string    { return formatTime(r.meta.Header.Time) }
+func (r *blockResolver) NumTxs() int32   { return parseCount(r.meta.Header.Num_txs) }
+func (r *blockResolver) TotalTxs() int32 { return parseCount(r.meta.Header.Total_txs) }
+func (r *blockResolver) AppHash() string { return r.meta.Header.App_hash }
+
+func (r *blockResolver) Txs() ([]*txResolver, error) {
+	if r.txs == nil {
+		_, block, err := r.s.cachedBlock(int64(r.Height()))
+		if err != nil {
+			return nil, err
+		}
+		r.txs = block.Block.Data.Txs
+	}
+	txs := []*txResolver{}
+	for _, tx := range r.txs {
+		txs = append(txs, &txResolver{s: r.s, tx: tx})
+	}
+	return txs, nil
+}
+
+type txResolver struct {
+	s  *Server
+	tx Tx
+}
+
+func (r *txResolver) Hash() string      { return r.tx.Hash }
+func (r *txResolver) Height() int32     { return int32(r.tx.Height) }
+func (r *txResolver) Index() int32      { return int32(r.tx.Index) }
+func (r *txResolver) Code() int32       { return int32(r.tx.Code) }
+func (r *txResolver) Log() *string      { return optString(r.tx.Log) }
+func (r *txResolver) Memo() *string     { return optString(r.tx.Memo) }
+func (r *txResolver) Error() *string    { return optString(r.tx.Error) }
+func (r *txResolver) Fee() *feeResolver { return &feeResolver{r.tx.Fee} }
+func (r *txResolver) FeePayer() *string { return optString(r.tx.FeePayer) }
+
+func (r *txResolver) Msgs() []*msgResolver {
+	msgs := []*msgResolver{}
+	for _, msg := range r.tx.Msgs {
+		msgs = append(msgs, &msgResolver{s: r.s, msg: msg})
+	}
+	return msgs
+}
+
+func (r *txResolver) Tags() []*tagResolver {
+	tags := []*tagResolver{}
+	for _, tag := range r.tx.Tags {
+		tags = append(tags, &tagResolver{tag})
+	}
+	return tags
+}
+
+// Accounts returns the accounts of the addresses in the decoded msgs, in
+// the order they first appear
+func (r *txResolver) Accounts(ctx context.Context) ([]*accountResolver, error) {
+	var addresses []string
+	seen := make(map[string]bool)
+	add := func(address string) {
+		if !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+	for _, msg := range r.tx.Msgs {
+		switch v := msg.Value.(type) {
+		case InitOrder:
+			add(v.Initiator)
+			add(v.Recipient)
+		case FinalizeOrder:
+			add(v.Initiator)
+			add(v.Recipient)
+		case Send:
+			for _, t := range append(v.Inputs, v.Outputs...) {
+				add(t.Address)
+			}
+		}
+	}
+
+	l := loaderFrom(ctx, r.s)
+	accounts := []*accountResolver{}
+	for _, address := range addresses {
+		account, err := l.accountResolver(address)
+		if err != nil {
+			return nil, err
+		}
+		if account != nil {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+func (r *txResolver) Orders() ([]*orderResolver, error) {
+	return r.s.orderResolvers(OrderFilter{Tx: r.tx.Hash, Page: Page{Limit: MaxPageLimit}})
+}
+
+type feeResolver struct{ fee Fee }
+
+func (r *feeResolver) Amount() []*coinResolver { return coinResolvers(r.fee.Amount) }
+func (r *feeResolver) Gas() string             { return strconv.FormatInt(r.fee.Gas, 10) }
+
+type coinResolver struct{ coin sdk.Coin }
+
+func coinResolvers(coins sdk.Coins) []*coinResolver {
+	resolvers := []*coinResolver{}
+	for _, coin := range coins {
+		resolvers = append(resolvers, &coinResolver{coin})
+	}
+	return resolvers
+}
+
+func (r *coinResolver) Denom() string  { return r.coin.Denom }
+func (r *coinResolver) Amount() string { return r.coin.Amount.String() }
+
+type tagResolver struct{ tag Tag }
+
+func (r *tagResolver) Key() string   { return r.tag.Key }
+func (r *tagResolver) Value() string { return r.tag.Value }
+
+type msgResolver struct {
+	s   *Server
+	msg Msg
+}
+
+func (r *msgResolver) Type() string { return r.msg.Type }
+
+func (r *msgResolver) Value() (string, error) {
+	bz, err := json.Marshal(r.msg.Value)
+	return string(bz), err
+}
+
+func (r *msgResolver) InitOrder() *initOrderResolver {
+	if v, ok := r.msg.Value.(InitOrder); ok {
+		return &initOrderResolver{s: r.s, msg: v}
+	}
+	return nil
+}
+
+func (r *msgResolver) FinalizeOrder() *finalizeOrderResolver {
+	if v, ok := r.msg.Value.(FinalizeOrder); ok {
+		return &finalizeOrderResolver{s: r.s, msg: v}
+	}
+	return nil
+}
+
+func (r *msgResolver) Send() *sendResolver {
+	if v, ok := r.msg.Value.(Send); ok {
+		return &sendResolver{s: r.s, msg: v}
+	}
+	return nil
+}
+
+type initOrderResolver struct {
+	s   *Server
+	msg InitOrder
+}
+
+func (r *initOrderResolver) Initiator(ctx context.Context) (*accountResolver, error) {
+	return loaderFrom(ctx, r.s).accountResolver(r.msg.Initiator)
+}
+
+func (r *initOrderResolver) Recipient(ctx context.Context) (*accountResolver, error) {
+	return loaderFrom(ctx, r.s).accountResolver(r.msg.Recipient)
+}
+
+func (r *initOrderResolver) AgreedPrice() string {
+	return strconv.FormatUint(r.msg.AgreedPrice, 10)
+}
+
+func (r *initOrderResolver) EstimatedCharge() string {
+	return strconv.FormatUint(r.msg.EstimatedCharge, 10)
+}
+
+type finalizeOrderResolver struct {
+	s   *Server
+	msg FinalizeOrder
+}
+
+func (r *finalizeOrderResolver) Initiator(ctx context.Context) (*accountResolver, error) {
+	return loaderFrom(ctx, r.s).accountResolver(r.msg.Initiator)
+}
+
+func (r *finalizeOrderResolver) Recipient(ctx context.Context) (*accountResolver, error) {
+	return loaderFrom(ctx, r.s).accountResolver(r.msg.Recipient)
+}
+
+func (r *finalizeOrderResolver) TotalAmount() string {
+	return strconv.FormatUint(r.msg.TotalAmount, 10)
+}
+
+func (r *finalizeOrderResolver) TotalCharge() string {
+	return strconv.FormatUint(r.msg.TotalCharge, 10)
+}
+
+type sendResolver struct {
+	s   *Server
+	msg Send
+}
+
+func (r *sendResolver) transfers(transfers []Transfer) []*transferResolver {
+	resolvers := []*transferResolver{}
+	for _, t := range transfers {
+		resolvers = append(resolvers, &transferResolver{s: r.s, transfer: t})
+	}
+	return resolvers
+}
+
+func (r *sendResolver) Inputs() []*transferResolver  { return r.transfers(r.msg.Inputs) }
+func (r *sendResolver) Outputs() []*transferResolver { return r.transfers(r.msg.Outputs) }
+
+type transferResolver struct {
+	s        *Server
+	transfer Transfer
+}
+
+func (r *transferResolver) Address() string        { return r.transfer.Address }
+func (r *transferResolver) Coins() []*coinResolver { return coinResolvers(r.transfer.Coins) }
+
+func (r *transferResolver) Account(ctx context.Context) (*accountResolver, error) {
+	return loaderFrom(ctx, r.s).accountResolver(r.transfer.Address)
+}
+
+type accountResolver struct {
+	s       *Server
+	account Account
+}
+
+func (r *accountResolver) Address() string        { return r.account.Address }
+func (r *accountResolver) Name() string           { return r.account.Name }
+func (r *accountResolver) MacAddress() string     { return r.account.MacAddress }
+func (r *accountResolver) Price() string          { return r.account.Price }
+func (r *accountResolver) Coins() []*coinResolver { return coinResolvers(r.account.Coins) }
+func (r *accountResolver) PubKey() *string        { return optString(r.account.PubKey) }
+func (r *accountResolver) HsmInfo() *hsmResolver  { return &hsmResolver{r.account} }
+
+func (r *accountResolver) AccountNumber() string {
+	return strconv.FormatInt(r.account.AccountNumber, 10)
+}
+
+func (r *accountResolver) Sequence() string {
+	return strconv.FormatInt(r.account.Sequence, 10)
+}
+
+func (r *accountResolver) Orders(args struct {
+	Status        *string
+	Limit, Offset *int32
+}) ([]*orderResolver, error) {
+	f := OrderFilter{Party: r.account.Address, Finalized: orderStatus(args.Status)}
+	var err error
+	if f.Page, err = orderPage(args.Limit, args.Offset); err != nil {
+		return nil, err
+	}
+	return r.s.orderResolvers(f)
+}
+
+func (r *accountResolver) Activity() (*activityResolver, error) {
+	if r.s.index == nil {
+		return nil, errIndexDisabled
+	}
+	acc, found, err := r.s.index.Account(r.account.Address)
+	if !found || err != nil {
+		return nil, err
+	}
+	return &activityResolver{acc}, nil
+}
+
+type hsmResolver struct{ account Account }
+
+func (r *hsmResolver) RomId() string     { return hex.EncodeToString(r.account.HsmInfo.RomID) }
+func (r *hsmResolver) ManId() string     { return hex.EncodeToString(r.account.HsmInfo.ManID) }
+func (r *hsmResolver) PubKey() string    { return hex.EncodeToString(r.account.HsmInfo.PubKey) }
+func (r *hsmResolver) Page() int32       { return int32(r.account.HsmInfo.Page) }
+func (r *hsmResolver) Authority() string { return r.account.HsmInfo.Authority }
+
+type activityResolver struct{ acc IndexedAccount }
+
+func (r *activityResolver) FirstHeight() int32 { return int32(r.acc.FirstHeight) }
+func (r *activityResolver) FirstTime() string  { return formatTime(r.acc.FirstTime) }
+func (r *activityResolver) LastHeight() int32  { return int32(r.acc.LastHeight) }
+func (r *activityResolver) LastTime() string   { return formatTime(r.acc.LastTime) }
+func (r *activityResolver) TxCount() int32     { return int32(r.acc.TxCount) }
+
+type orderResolver struct {
+	s     *Server
+	order Order
+}
+
+func (r *orderResolver) Buyer() string     { return r.order.Buyer }
+func (r *orderResolver) Number() int32     { return int32(r.order.Number) }
+func (r *orderResolver) Seller() string    { return r.order.Seller }
+func (r *orderResolver) InitHeight() int32 { return int32(r.order.InitHeight) }
+func (r *orderResolver) InitTime() string  { return formatTime(r.order.InitTime) }
+func (r *orderResolver) Finalized() bool   { return r.order.FinalizeHeight != nil }
+
+func (r *orderResolver) BuyerAccount(ctx context.Context) (*accountResolver, error) {
+	return loaderFrom(ctx, r.s).accountResolver(r.order.Buyer)
+}
+
+func (r *orderResolver) SellerAccount(ctx context.Context) (*accountResolver, error) {
+	return loaderFrom(ctx, r.s).accountResolver(r.order.Seller)
+}
+
+func (r *orderResolver) AgreedPrice() string {
+	return strconv.FormatUint(r.order.AgreedPrice, 10)
+}
+
+func (r *orderResolver) EstimatedCharge() string {
+	return strconv.FormatUint(r.order.EstimatedCharge, 10)
+}
+
+func (r *orderResolver) InitTx() (*txResolver, error) {
+	return r.s.txResolver(r.order.InitTx)
+}
+
+func optUint(n *uint64) *string {
+	if n == nil {
+		return nil
+	}
+	s := strconv.FormatUint(*n, 10)
+	return &s
+}
+
+func (r *orderResolver) TotalAmount() *string { return optUint(r.order.TotalAmount) }
+func (r *orderResolver) TotalCharge() *string { return optUint(r.order.TotalCharge) }
+
+func (r *orderResolver) FinalizeHeight() *int32 {
+	if r.order.FinalizeHeight == nil {
+		return nil
+	}
+	height := int32(*r.order.FinalizeHeight)
+	return &height
+}
+
+func (r *orderResolver) FinalizeTime() *string {
+	if r.order.FinalizeTime == nil {
+		return nil
+	}
+	t := formatTime(*r.order.FinalizeTime)
+	return &t
+}
+
+func (r *orderResolver) FinalizeTx() (*txResolver, error) {
+	if r.order.FinalizeTx == nil {
+		return nil, nil
+	}
+	return r.s.txResolver(*r.order.FinalizeTx)
+}
+
+type validatorResolver struct{ v Validator }
+
+func (r *validatorResolver) Address() string     { return r.v.Address }
+func (r *validatorResolver) PubKey() string      { return string(r.v.PubKey) }
+func (r *validatorResolver) VotingPower() string { return strconv.FormatInt(r.v.VotingPower, 10) }
+
+type statusResolver struct{ status Status }
+
+func (r *statusResolver) ChainId() string          { return r.status.ChainID }
+func (r *statusResolver) Moniker() string          { return r.status.Moniker }
+func (r *statusResolver) Version() string          { return r.status.Version }
+func (r *statusResolver) LatestBlockHeight() int32 { return int32(r.status.LatestBlockHeight) }
+func (r *statusResolver) LatestBlockHash() string  { return r.status.LatestBlockHash }
+func (r *statusResolver) LatestBlockTime() string  { return formatTime(r.status.LatestBlockTime) }
+func (r *statusResolver) CatchingUp() bool         { return r.status.CatchingUp }
+
+// parseGraphQLSchema binds the schema to the resolvers of a server
+func parseGraphQLSchema(s *Server) *graphql.Schema {
+	return graphql.MustParseSchema(graphQLSchema, &rootResolver{s},
+		graphql.MaxDepth(GraphQLMaxDepth), graphql.MaxParallelism(GraphQLMaxParallelism))
+}
@@ -0,0 +1,411 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vincepg13/bp-sdk/beyond/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/gorilla/mux"
+)
+
+// MaxBlocksPage is the number of blocks the blockchain RPC returns at most
+const MaxBlocksPage = 20
+
+// Search result types
+const (
+	SearchBlock   = "block"
+	SearchTx      = "tx"
+	SearchAccount = "account"
+)
+
+var txHashRegexp = regexp.MustCompile("^(0[xX])?[0-9a-fA-F]{40}$")
+
+// Account is an account with the fields of AppAccount
+type Account struct {
+	Address       string        `json:"address"`
+	Coins         sdk.Coins     `json:"coins"`
+	PubKey        string        `json:"pubKey,omitempty"`
+	AccountNumber int64         `json:"accountNumber"`
+	Sequence      int64         `json:"sequence"`
+	Name          string        `json:"name"`
+	MacAddress    string        `json:"macAddress"`
+	Price         string        `json:"price"`
+	HsmInfo       types.HsmInfo `json:"hsmInfo"`
+}
+
+// BlockList is a page of block headers, newest first
+type BlockList struct {
+	LastHeight int64       `json:"lastHeight"`
+	From       int64       `json:"from"`
+	To         int64       `json:"to"`
+	Blocks     []BlockMeta `json:"blocks"`
+}
+
+// Validator is a validator of the latest block
+type Validator struct {
+	Address     string          `json:"address"`
+	PubKey      json.RawMessage `json:"pubKey"`
+	VotingPower int64           `json:"votingPower"`
+}
+
+// ValidatorList is the validator set at a height
+type ValidatorList struct {
+	Height     int64       `json:"height"`
+	Validators []Validator `json:"validators"`
+}
+
+// Status is the state of the chain as seen by the nodes
+type Status struct {
+	ChainID           string    `json:"chainId"`
+	Moniker           string    `json:"moniker"`
+	Version           string    `json:"version"`
+	LatestBlockHeight int64     `json:"latestBlockHeight"`
+	LatestBlockHash   string    `json:"latestBlockHash"`
+	LatestBlockTime   time.Time `json:"latestBlockTime"`
+	CatchingUp        bool      `json:"catchingUp"`
+	Nodes             []Node    `json:"nodes"`
+}
+
+// SearchResult is the object a search query resolved to
+type SearchResult struct {
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// nodeTx is a tx as returned by the tx RPC
+type nodeTx struct {
+	Height   int64     `json:"height,string"`
+	Index    int       `json:"index"`
+	TxResult deliverTx `json:"tx_result"`
+	Tx       []byte    `json:"tx"`
+}
+
+// nodeABCIQuery is the result of the abci_query RPC
+type nodeABCIQuery struct {
+	Response struct {
+		Code  uint32 `json:"code"`
+		Log   string `json:"log"`
+		Value []byte `json:"value"`
+	} `json:"response"`
+}
+
+// nodeBlockchain is the result of the blockchain RPC
+type nodeBlockchain struct {
+	LastHeight int64       `json:"last_height,string"`
+	BlockMetas []BlockMeta `json:"block_metas"`
+}
+
+// nodeValidators is the result of the validators RPC
+type nodeValidators struct {
+	BlockHeight int64 `json:"block_height,string"`
+	Validators  []struct {
+		Address     string          `json:"address"`
+		PubKey      json.RawMessage `json:"pub_key"`
+		VotingPower int64           `json:"voting_power,string"`
+	} `json:"validators"`
+}
+
+// nodeStatus is the result of the status RPC
+type nodeStatus struct {
+	NodeInfo struct {
+		Network string `json:"network"`
+		Version string `json:"version"`
+		Moniker string `json:"moniker"`
+	} `json:"node_info"`
+	SyncInfo struct {
+		LatestBlockHash   string    `json:"latest_block_hash"`
+		LatestBlockHeight int64     `json:"latest_block_height,string"`
+		LatestBlockTime   time.Time `json:"latest_block_time"`
+		CatchingUp        bool      `json:"catching_up"`
+	} `json:"sync_info"`
+}
+
+// errNotFound is returned for objects the node does not know
+type errNotFound string
+
+func (e errNotFound) Error() string { return string(e) }
+
+// writeResult writes v, or the error of the request for it
+func writeResult(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+func (s *Server) call(method string, params url.Values, v interface{}) error {
+	bz, err := s.nodes.Call(method, params)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bz, v)
+}
+
+// tx returns the tx with the given hex hash
+func (s *Server) tx(hash string) (tx Tx, err error) {
+	_, tx, err = s.cachedTx(hash)
+	return tx, err
+}
+
+// cachedTx returns the tx with the given hex hash and its /tx response from
+// the response cache
+func (s *Server) cachedTx(hash string) (*cachedResponse, Tx, error) {
+	hash = strings.ToUpper(strings.TrimPrefix(strings.TrimPrefix(hash, "0x"), "0X"))
+	res, err := s.cachedObject("/tx/"+hash, func() (interface{}, error) {
+		return s.fetchTx(hash)
+	})
+	if err != nil {
+		return nil, Tx{}, err
+	}
+	return res, res.value.(Tx), nil
+}
+
+// fetchTx reads the tx with the given hex hash from the nodes
+func (s *Server) fetchTx(hash string) (tx Tx, err error) {
+	var res nodeTx
+	if err := s.call("tx", url.Values{"hash": {"0x" + hash}}, &res); err != nil {
+		if _, ok := err.(*RPCError); ok {
+			return tx, errNotFound(fmt.Sprintf("tx %s not found", strings.ToUpper(hash)))
+		}
+		return tx, err
+	}
+	return s.decoder.DecodeTx(res.Tx, res.Height, res.Index, res.TxResult), nil
+}
+
+// account returns the account stored at the address
+func (s *Server) account(addr sdk.AccAddress) (account Account, err error) {
+	var res nodeABCIQuery
+	params := url.Values{
+		"path": {`"/store/acc/key"`},
+		"data": {"0x" + hex.EncodeToString(auth.AddressStoreKey(addr))},
+	}
+	if err := s.call("abci_query", params, &res); err != nil {
+		return account, err
+	}
+	if res.Response.Code != 0 {
+		return account, &RPCError{Message: "query failed", Data: res.Response.Log}
+	}
+	if len(res.Response.Value) == 0 {
+		return account, errNotFound(fmt.Sprintf("account %s not found", addr))
+	}
+
+	var acc auth.Account
+	if err := s.cdc.UnmarshalBinaryBare(res.Response.Value, &acc); err != nil {
+		return account, err
+	}
+	account = Account{
+		Address:       acc.GetAddress().String(),
+		Coins:         acc.GetCoins(),
+		AccountNumber: acc.GetAccountNumber(),
+		Sequence:      acc.GetSequence(),
+	}
+	if pub := acc.GetPubKey(); pub != nil {
+		account.PubKey, err = sdk.Bech32ifyAccPub(pub)
+		if err != nil {
+			return account, err
+		}
+	}
+	if appAcc, ok := acc.(*types.AppAccount); ok {
+		account.Name = appAcc.Name
+		account.MacAddress = appAcc.MacAddress
+		account.Price = appAcc.ElectricityPrice
+		account.HsmInfo = appAcc.HsmInfo
+	}
+	return account, nil
+}
+
+func (s *Server) status() (status Status, err error) {
+	var res nodeStatus
+	if err := s.call("status", nil, &res); err != nil {
+		return status, err
+	}
+	return Status{
+		ChainID:           res.NodeInfo.Network,
+		Moniker:           res.NodeInfo.Moniker,
+		Version:           res.NodeInfo.Version,
+		LatestBlockHeight: res.SyncInfo.LatestBlockHeight,
+		LatestBlockHash:   res.SyncInfo.LatestBlockHash,
+		LatestBlockTime:   res.SyncInfo.LatestBlockTime,
+		CatchingUp:        res.SyncInfo.CatchingUp,
+		Nodes:             s.nodes.Nodes(),
+	}, nil
+}
+
+// GetTx returns a tx by its hash
+func (s *Server) GetTx(w http.ResponseWriter, r *http.Request) {
+	hash := mux.Vars(r)["hash"]
+	if !txHashRegexp.MatchString(hash) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid tx hash %q", hash))
+		return
+	}
+	res, _, err := s.cachedTx(hash)
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+	res.serve(w, r, immutableCacheControl)
+}
+
+// GetAccount returns an account by its bech32 address
+func (s *Server) GetAccount(w http.ResponseWriter, r *http.Request) {
+	addr, err := sdk.AccAddressFromBech32(mux.Vars(r)["address"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	account, err := s.account(addr)
+	writeResult(w, account, err)
+}
+
+// blockRange completes and checks a from..to range of at most
+// MaxBlocksPage heights, zero heights are unset
+func blockRange(from, to int64) (int64, int64, error) {
+	switch {
+	case from > 0 && to == 0:
+		to = from + MaxBlocksPage - 1
+	case to > 0 && from == 0:
+		from = to - MaxBlocksPage + 1
+	}
+	if from > 0 && to-from+1 > MaxBlocksPage {
+		return from, to, fmt.Errorf("at most %d blocks are returned at once", MaxBlocksPage)
+	}
+	if from > to {
+		return from, to, fmt.Errorf("from must not be above to")
+	}
+	if to > 0 && from < 1 {
+		from = 1
+	}
+	return from, to, nil
+}
+
+// blocks returns the headers of the blocks from..to, newest first, or of
+// the latest blocks if to is zero
+func (s *Server) blocks(from, to int64) (list BlockList, err error) {
+	params := url.Values{}
+	if to > 0 {
+		params.Set("minHeight", strconv.FormatInt(from, 10))
+		params.Set("maxHeight", strconv.FormatInt(to, 10))
+	}
+	var res nodeBlockchain
+	if err := s.call("blockchain", params, &res); err != nil {
+		return list, err
+	}
+
+	list = BlockList{LastHeight: res.LastHeight, Blocks: res.BlockMetas}
+	if list.Blocks == nil {
+		list.Blocks = []BlockMeta{}
+	}
+	if n := len(list.Blocks); n > 0 {
+		list.To, _ = strconv.ParseInt(list.Blocks[0].Header.Height, 10, 64)
+		list.From, _ = strconv.ParseInt(list.Blocks[n-1].Header.Height, 10, 64)
+	}
+	return list, nil
+}
+
+// validators returns the current validator set
+func (s *Server) validators() (list ValidatorList, err error) {
+	var res nodeValidators
+	if err := s.call("validators", nil, &res); err != nil {
+		return list, err
+	}
+	list = ValidatorList{Height: res.BlockHeight, Validators: []Validator{}}
+	for _, v := range res.Validators {
+		list.Validators = append(list.Validators, Validator{Address: v.Address, PubKey: v.PubKey, VotingPower: v.VotingPower})
+	}
+	return list, nil
+}
+
+// GetBlocks returns the headers of the blocks from..to, at most
+// MaxBlocksPage of them. Without parameters the latest blocks are returned.
+func (s *Server) GetBlocks(w http.ResponseWriter, r *http.Request) {
+	var from, to int64
+	for _, p := range []struct {
+		name string
+		dst  *int64
+	}{{"from", &from}, {"to", &to}} {
+		v := r.URL.Query().Get(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid %s height %q", p.name, v))
+			return
+		}
+		*p.dst = n
+	}
+	from, to, err := blockRange(from, to)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	list, err := s.blocks(from, to)
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// GetValidators returns the current validator set
+func (s *Server) GetValidators(w http.ResponseWriter, r *http.Request) {
+	list, err := s.validators()
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// GetStatus returns the state of the chain and of the nodes
+func (s *Server) GetStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.status()
+	writeResult(w, status, err)
+}
+
+// Search resolves a block height, a tx hash or an account address
+func (s *Server) Search(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	switch {
+	case q == "":
+		writeError(w, http.StatusBadRequest, "missing query q")
+	case isHeight(q):
+		height, _ := strconv.ParseInt(q, 10, 64)
+		_, block, err := s.cachedBlock(height)
+		writeResult(w, SearchResult{Type: SearchBlock, Value: block}, err)
+	case txHashRegexp.MatchString(q):
+		tx, err := s.tx(q)
+		writeResult(w, SearchResult{Type: SearchTx, Value: tx}, err)
+	default:
+		addr, err := sdk.AccAddressFromBech32(q)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("%q is not a block height, tx hash or account address", q))
+			return
+		}
+		account, err := s.account(addr)
+		writeResult(w, SearchResult{Type: SearchAccount, Value: account}, err)
+	}
+}
+
+func isHeight(q string) bool {
+	n, err := strconv.ParseInt(q, 10, 64)
+	return err == nil && n > 0
+}
@@ -0,0 +1,232 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gorilla/mux"
+)
+
+// Pagination of the index queries
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+// IndexStatus is the progress of the indexer. Balances are tracked from
+// the genesis if BalancesFrom is 0.
+type IndexStatus struct {
+	Checkpoint        int64 `json:"checkpoint"`
+	LatestBlockHeight int64 `json:"latestBlockHeight"`
+	BalancesFrom      int64 `json:"balancesFrom"`
+}
+
+// queryInt parses an optional positive integer query parameter
+func queryInt(r *http.Request, name string) (int64, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, v)
+	}
+	return n, nil
+}
+
+// queryAddress parses an optional bech32 address query parameter
+func queryAddress(r *http.Request, name string) (string, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return "", nil
+	}
+	if _, err := sdk.AccAddressFromBech32(v); err != nil {
+		return "", fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return v, nil
+}
+
+// queryPage parses the limit and offset parameters
+func queryPage(r *http.Request) (page Page, err error) {
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		return page, err
+	}
+	offset, err := queryInt(r, "offset")
+	if err != nil {
+		return page, err
+	}
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		return page, fmt.Errorf("limit must be at most %d", MaxPageLimit)
+	}
+	return Page{Limit: int(limit), Offset: int(offset)}, nil
+}
+
+// queryHeights parses the from and to height range
+func queryHeights(r *http.Request) (from, to int64, err error) {
+	if from, err = queryInt(r, "from"); err != nil {
+		return
+	}
+	if to, err = queryInt(r, "to"); err != nil {
+		return
+	}
+	if to > 0 && from > to {
+		err = fmt.Errorf("from must not be above to")
+	}
+	return
+}
+
+var errIndexDisabled = errors.New("the order index is not enabled")
+
+// withIndex rejects requests while no index is configured
+func (s *Server) withIndex(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.index == nil {
+			writeError(w, http.StatusServiceUnavailable, errIndexDisabled.Error())
+			return
+		}
+		h(w, r)
+	}
+}
+
+// GetOrders lists indexed orders, newest first
+func (s *Server) GetOrders(w http.ResponseWriter, r *http.Request) {
+	var f OrderFilter
+	var err error
+	if f.Buyer, err = queryAddress(r, "buyer"); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if f.Seller, err = queryAddress(r, "seller"); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	switch status := r.URL.Query().Get("status"); status {
+	case "":
+	case "open", "finalized":
+		finalized := status == "finalized"
+		f.Finalized = &finalized
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid status %q, expected open or finalized", status))
+		return
+	}
+	if f.From, f.To, err = queryHeights(r); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if f.Page, err = queryPage(r); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	orders, err := s.index.Orders(f)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, orders)
+}
+
+// GetOrder returns an indexed order by its buyer and number
+func (s *Server) GetOrder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if _, err := sdk.AccAddressFromBech32(vars["buyer"]); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	number, err := strconv.ParseUint(vars["number"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid order number %q", vars["number"]))
+		return
+	}
+
+	order, found, err := s.index.Order(vars["buyer"], number)
+	switch {
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	case !found:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("order %d of %s not found", number, vars["buyer"]))
+	default:
+		writeJSON(w, http.StatusOK, order)
+	}
+}
+
+// GetPayments lists indexed payments, newest first
+func (s *Server) GetPayments(w http.ResponseWriter, r *http.Request) {
+	var f PaymentFilter
+	var err error
+	if f.Address, err = queryAddress(r, "address"); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if f.From, f.To, err = queryHeights(r); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if f.Page, err = queryPage(r); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	payments, err := s.index.Payments(f)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, payments)
+}
+
+// GetActivity returns the indexed activity of an address
+func (s *Server) GetActivity(w http.ResponseWriter, r *http.Request) {
+	address := mux.Vars(r)["address"]
+	if _, err := sdk.AccAddressFromBech32(address); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	acc, found, err := s.index.Account(address)
+	switch {
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	case !found:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no indexed activity of %s", address))
+	default:
+		writeJSON(w, http.StatusOK, acc)
+	}
+}
+
+// GetIndexStatus returns how far the index is behind the chain
+func (s *Server) GetIndexStatus(w http.ResponseWriter, r *http.Request) {
+	checkpoint, err := s.index.Checkpoint()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	balancesFrom, err := s.index.BalancesFrom()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	status, err := s.status()
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, IndexStatus{
+		Checkpoint:        checkpoint,
+		LatestBlockHeight: status.LatestBlockHeight,
+		BalancesFrom:      balancesFrom,
+	})
+}
@@ -0,0 +1,89 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package main
+
+import (
+	"log"
+	"time"
+)
+
+// Indexer follows the committed blocks of the chain and applies them to the
+// index store. Tendermint blocks are final once committed, so the indexer
+// only catches up and never rolls back.
+type Indexer struct {
+	store  *IndexStore
+	server *Server
+	start  int64 // first height to index into an empty store
+}
+
+// NewIndexer returns an indexer fetching blocks through the server. An
+// empty store is filled from the start height, genesis if 0.
+func NewIndexer(store *IndexStore, server *Server, start int64) *Indexer {
+	if start < 1 {
+		start = 1
+	}
+	return &Indexer{store: store, server: server, start: start}
+}
+
+// next returns the height of the next block to index
+func (ix *Indexer) next() (int64, error) {
+	checkpoint, err := ix.store.Checkpoint()
+	if err != nil || checkpoint == 0 {
+		return ix.start, err
+	}
+	return checkpoint + 1, nil
+}
+
+// CatchUp indexes the blocks up to the latest one and returns the height
+// of the last indexed block
+func (ix *Indexer) CatchUp() (int64, error) {
+	status, err := ix.server.status()
+	if err != nil {
+		return 0, err
+	}
+	height, err := ix.next()
+	if err != nil {
+		return 0, err
+	}
+	// balances start from the genesis accounts
+	if height == 1 && status.LatestBlockHeight > 0 {
+		genesisTime, accounts, err := ix.server.genesis()
+		if err != nil {
+			return 0, err
+		}
+		if err := ix.store.ApplyGenesis(genesisTime, accounts); err != nil {
+			return 0, err
+		}
+	}
+	for ; height <= status.LatestBlockHeight; height++ {
+		block, err := ix.server.block(height)
+		if err != nil {
+			return height - 1, err
+		}
+		if err := ix.store.ApplyBlock(block); err != nil {
+			return height - 1, err
+		}
+	}
+	return height - 1, nil
+}
+
+// Run catches up every interval until stop is closed. Errors are logged and
+// retried, indexing resumes from the checkpoint.
+func (ix *Indexer) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if height, err := ix.CatchUp(); err != nil {
+			log.Printf("indexer stopped at block %d: %v", height, err)
+		}
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
@@ -8,11 +8,17 @@ package main
 
 import (
 	"encoding/json"
-	"log"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
+	"github.com/vincepg13/bp-sdk/beyond/app"
+
+	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/gorilla/mux"
+	graphql "github.com/graph-gophers/graphql-go"
 )
 
 type JsonBlock struct {
@@ -51,36 +57,198 @@ type Block struct {
 }
 
 type Data struct {
+	Txs []Tx `json:"txs"`
+}
+
+// nodeBlock is a block as returned by the block RPC
+type nodeBlock struct {
+	Block_meta BlockMeta `json:"block_meta"`
+	Block      struct {
+		Data struct {
+			Txs [][]byte `json:"txs"`
+		} `json:"data"`
+	} `json:"block"`
+}
+
+// nodeBlockResults is the result of the block_results RPC
+type nodeBlockResults struct {
+	Results struct {
+		DeliverTx []deliverTx `json:"DeliverTx"`
+	} `json:"results"`
+}
+
+// Server serves the explorer API from a pool of Tendermint nodes
+type Server struct {
+	nodes   *NodePool
+	cdc     *codec.Codec
+	decoder TxDecoder
+	index   *IndexStore // nil unless the order index is enabled
+	feed    *Feed
+	graphql *graphql.Schema
+
+	cache     *ResponseCache
+	statusTTL time.Duration
+}
+
+// NewServer returns a server querying the given nodes and decoding txs
+// with the app codec. The live feed follows the chain once it is Run.
+func NewServer(nodes *NodePool) *Server {
+	cdc := app.MakeCodec()
+	s := &Server{
+		nodes:     nodes,
+		cdc:       cdc,
+		decoder:   NewTxDecoder(cdc),
+		cache:     NewResponseCache(DefaultCacheSize),
+		statusTTL: DefaultStatusTTL,
+	}
+	s.feed = NewFeed(s)
+	s.graphql = parseGraphQLSchema(s)
+	return s
 }
 
-// our main function
-func main() {
+// WithCache sets the response cache size in bytes and how long the status
+// is cached
+func (s *Server) WithCache(maxBytes int, statusTTL time.Duration) *Server {
+	s.cache = NewResponseCache(maxBytes)
+	s.statusTTL = statusTTL
+	return s
+}
+
+// WithIndex makes the server answer the order index queries from store
+func (s *Server) WithIndex(store *IndexStore) *Server {
+	s.index = store
+	return s
+}
+
+// block returns the block at a height with its decoded txs
+func (s *Server) block(height int64) (result Result, err error) {
+	params := url.Values{"height": {strconv.FormatInt(height, 10)}}
+	bz, err := s.nodes.Call("block", params)
+	if err != nil {
+		return result, s.blockError(height, err)
+	}
+	var block nodeBlock
+	if err := json.Unmarshal(bz, &block); err != nil {
+		return result, err
+	}
+	result.Block_meta = block.Block_meta
+	result.Block.Data.Txs = []Tx{}
+
+	txs := block.Block.Data.Txs
+	if len(txs) == 0 {
+		return result, nil
+	}
+	bz, err = s.nodes.Call("block_results", params)
+	if err != nil {
+		return result, err
+	}
+	var results nodeBlockResults
+	if err := json.Unmarshal(bz, &results); err != nil {
+		return result, err
+	}
+	if len(results.Results.DeliverTx) != len(txs) {
+		return result, fmt.Errorf("block %d has %d txs but %d results", height, len(txs), len(results.Results.DeliverTx))
+	}
+	for i, tx := range txs {
+		result.Block.Data.Txs = append(result.Block.Data.Txs, s.decoder.DecodeTx(tx, height, i, results.Results.DeliverTx[i]))
+	}
+	return result, nil
+}
+
+// cachedBlock returns the block at a height and its /block response from
+// the response cache
+func (s *Server) cachedBlock(height int64) (*cachedResponse, Result, error) {
+	res, err := s.cachedObject("/block/"+strconv.FormatInt(height, 10), func() (interface{}, error) {
+		result, err := s.block(height)
+		if err != nil {
+			return nil, err
+		}
+		return &JsonBlock{Result: result}, nil
+	})
+	if err != nil {
+		return nil, Result{}, err
+	}
+	return res, res.value.(*JsonBlock).Result, nil
+}
+
+// blockError returns errNotFound for a height above the latest block, which
+// no node has. Other errors are returned unchanged.
+func (s *Server) blockError(height int64, err error) error {
+	if rpcErr, ok := err.(*RPCError); !ok || rpcErr.Code != rpcInternalError {
+		return err
+	}
+	if status, serr := s.status(); serr == nil && height > status.LatestBlockHeight {
+		return errNotFound(fmt.Sprintf("block %d not found", height))
+	}
+	return err
+}
+
+// Router returns the API routes
+func (s *Server) Router() *mux.Router {
 	router := mux.NewRouter()
-	// API routes
-	router.HandleFunc("/block/{id}", GetBlock).Methods("GET")
-	log.Fatal(http.ListenAndServe(":8000", router))
+	get := func(path string, h http.HandlerFunc) {
+		router.HandleFunc(path, conditional(h)).Methods("GET")
+	}
+	// committed blocks and txs never change, they are served from the
+	// response cache by their handlers
+	router.HandleFunc("/block/{id}", s.GetBlock).Methods("GET")
+	router.HandleFunc("/tx/{hash}", s.GetTx).Methods("GET")
+	router.HandleFunc("/status", s.cached(s.statusTTL, s.GetStatus)).Methods("GET")
+	get("/blocks", s.GetBlocks)
+	get("/account/{address}", s.GetAccount)
+	get("/validators", s.GetValidators)
+	get("/search", s.Search)
+	get("/orders", s.withIndex(s.GetOrders))
+	get("/orders/{buyer}/{number}", s.withIndex(s.GetOrder))
+	get("/payments", s.withIndex(s.GetPayments))
+	get("/activity/{address}", s.withIndex(s.GetActivity))
+	get("/index/status", s.withIndex(s.GetIndexStatus))
+	get("/analytics/energy", s.withIndex(s.GetEnergyReports))
+	get("/analytics/top-buyers", s.withIndex(s.GetTopBuyers))
+	get("/balances/{address}/history", s.withIndex(s.GetBalanceHistory))
+	get("/richlist", s.withIndex(s.GetRichList))
+	get("/health", s.GetHealth)
+	get("/openapi.yaml", s.GetOpenAPI)
+	// HTML pages
+	get("/explorer", s.BlocksPage)
+	get("/explorer/block/{height}", s.BlockPage)
+	get("/explorer/tx/{hash}", s.TxPage)
+	get("/explorer/account/{address}", s.AccountPage)
+	get("/explorer/search", s.SearchPage)
+	router.Handle("/", http.RedirectHandler("/explorer", http.StatusFound)).Methods("GET")
+	router.HandleFunc("/graphql", s.ServeGraphQL).Methods("GET", "POST")
+	router.HandleFunc("/ws", s.feed.ServeWS).Methods("GET")
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "unknown endpoint "+r.URL.Path)
+	})
+	return router
 }
 
 // get a single block by ID
-func GetBlock(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	id := params["id"]
-	if id != "" {
-		var netClient = &http.Client{
-			Timeout: time.Second * 10,
-		}
+func (s *Server) GetBlock(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	height, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || height < 1 {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid block height %q", id))
+		return
+	}
 
-		response, err := netClient.Get("http://ec2-3-16-60-36.us-east-2.compute.amazonaws.com:26657/block?height=" + id)
-		if err != nil {
-			log.Fatal(err)
-			json.NewEncoder(w).Encode(&Block{})
-		} else {
-			defer response.Body.Close()
-			{
-				blockData := &JsonBlock{}
-				json.NewDecoder(response.Body).Decode(blockData)
-				json.NewEncoder(w).Encode(blockData)
-			}
+	res, _, err := s.cachedBlock(height)
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+	res.serve(w, r, immutableCacheControl)
+}
+
+// GetHealth reports the state of the nodes, it fails if none is healthy
+func (s *Server) GetHealth(w http.ResponseWriter, r *http.Request) {
+	nodes := s.nodes.Nodes()
+	status := http.StatusServiceUnavailable
+	for _, node := range nodes {
+		if node.Healthy {
+			status = http.StatusOK
 		}
 	}
+	writeJSON(w, status, nodes)
 }
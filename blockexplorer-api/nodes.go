@@ -0,0 +1,236 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoNodes is returned when none of the configured nodes could be reached
+var ErrNoNodes = errors.New("no Tendermint node is reachable")
+
+// RPCError is an error returned by a node that was reached, such as a
+// block height that does not exist yet. It only triggers a failover for
+// height-addressed calls, see NodePool.Call.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	if e.Data != "" {
+		return fmt.Sprintf("%s: %s", e.Message, e.Data)
+	}
+	return e.Message
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// Node is a Tendermint RPC endpoint and its last known health
+type Node struct {
+	URL       string    `json:"url"`
+	Healthy   bool      `json:"healthy"`
+	LastCheck time.Time `json:"lastCheck"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+// NodePool sends RPC requests to the first healthy node and fails over to
+// the next one when a node can not be reached. Unhealthy nodes are tried
+// again once a health check succeeds, or as a last resort.
+type NodePool struct {
+	client *http.Client
+
+	mtx   sync.RWMutex
+	nodes []Node
+}
+
+// NewNodePool returns a pool of the given RPC endpoints. Endpoints may use
+// the tcp:// scheme of the Tendermint configuration, they are queried over
+// HTTP.
+func NewNodePool(endpoints []string, timeout time.Duration) (*NodePool, error) {
+	pool := &NodePool{client: &http.Client{Timeout: timeout}}
+	for _, endpoint := range endpoints {
+		endpoint = strings.TrimSpace(endpoint)
+		if endpoint == "" {
+			continue
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid node endpoint %q", endpoint)
+		}
+		if u.Scheme == "tcp" {
+			u.Scheme = "http"
+		}
+		pool.nodes = append(pool.nodes, Node{URL: strings.TrimRight(u.String(), "/"), Healthy: true})
+	}
+	if len(pool.nodes) == 0 {
+		return nil, errors.New("at least one node endpoint is required")
+	}
+	return pool, nil
+}
+
+// Nodes returns the state of all nodes
+func (p *NodePool) Nodes() []Node {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	return append([]Node{}, p.nodes...)
+}
+
+// candidates returns the node indexes in the order they should be tried,
+// healthy nodes first
+func (p *NodePool) candidates() []int {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	var healthy, unhealthy []int
+	for i, node := range p.nodes {
+		if node.Healthy {
+			healthy = append(healthy, i)
+		} else {
+			unhealthy = append(unhealthy, i)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// Endpoints returns the node URLs in the order they should be tried
+func (p *NodePool) Endpoints() []string {
+	var endpoints []string
+	for _, i := range p.candidates() {
+		p.mtx.RLock()
+		endpoints = append(endpoints, p.nodes[i].URL)
+		p.mtx.RUnlock()
+	}
+	return endpoints
+}
+
+func (p *NodePool) setHealth(i int, err error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	node := &p.nodes[i]
+	if err != nil && node.Healthy {
+		log.Printf("node %s is unhealthy: %v", node.URL, err)
+	} else if err == nil && !node.Healthy {
+		log.Printf("node %s is healthy again", node.URL)
+	}
+	node.Healthy = err == nil
+	node.LastCheck = time.Now()
+	node.LastError = ""
+	if err != nil {
+		node.LastError = err.Error()
+	}
+}
+
+// Call sends the RPC request to the nodes until one of them answers and
+// returns its result. The error is an *RPCError if the node answered with
+// an error and ErrNoNodes if no node could be reached.
+//
+// A node that is behind or has pruned its blocks answers a request for a
+// height with an internal error while other nodes may have it, such
+// requests are sent to the next node. The error of the last node is
+// returned if none of them has the height.
+func (p *NodePool) Call(method string, params url.Values) (json.RawMessage, error) {
+	var rpcErr *RPCError
+	for _, i := range p.candidates() {
+		p.mtx.RLock()
+		endpoint := p.nodes[i].URL
+		p.mtx.RUnlock()
+
+		result, err := p.call(endpoint, method, params)
+		if err, ok := err.(*RPCError); ok {
+			p.setHealth(i, nil)
+			if err.Code == rpcInternalError && heightAddressed(params) {
+				rpcErr = err
+				continue
+			}
+			return nil, err
+		}
+		if err == nil {
+			p.setHealth(i, nil)
+			return result, nil
+		}
+		p.setHealth(i, err)
+	}
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return nil, ErrNoNodes
+}
+
+// heightAddressed reports whether the request asks for data at given
+// heights
+func heightAddressed(params url.Values) bool {
+	for _, key := range []string{"height", "minHeight", "maxHeight"} {
+		if params.Get(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *NodePool) call(endpoint, method string, params url.Values) (json.RawMessage, error) {
+	u := endpoint + "/" + method
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	response, err := p.client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	// Tendermint answers RPC errors with a 500 status and an error body
+	var res rpcResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("%s: invalid response (status %d)", u, response.StatusCode)
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: status %d", u, response.StatusCode)
+	}
+	return res.Result, nil
+}
+
+// CheckHealth queries the health endpoint of every node
+func (p *NodePool) CheckHealth() {
+	for i, node := range p.Nodes() {
+		_, err := p.call(node.URL, "health", nil)
+		p.setHealth(i, err)
+	}
+}
+
+// Run checks the health of the nodes every interval until stop is closed
+func (p *NodePool) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		p.CheckHealth()
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vincepg13/bp-sdk/beyond/app"
+	"github.com/vincepg13/bp-sdk/beyond/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/stretchr/testify/require"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// fakeGenesisTime is the genesis time of fake chains, block h is committed
+// h minutes later
+var fakeGenesisTime = time.Date(2018, 10, 20, 10, 0, 0, 0, time.UTC)
+
+// fakeTx is a tx of a fake chain with its DeliverTx result
+type fakeTx struct {
+	tx     []byte
+	result deliverTx
+}
+
+// tagged returns a result with the given tag keys and values
+func tagged(kv ...string) deliverTx {
+	var res deliverTx
+	for i := 0; i+1 < len(kv); i += 2 {
+		res.Tags = append(res.Tags, struct {
+			Key   []byte `json:"key"`
+			Value []byte `json:"value"`
+		}{[]byte(kv[i]), []byte(kv[i+1])})
+	}
+	return res
+}
+
+// fakeChain is the chain a fakeNode serves. Blocks up to latest exist,
+// those missing in blocks have no txs. latest defaults to the highest block.
+type fakeChain struct {
+	name     string
+	latest   int64
+	blocks   map[int64][]fakeTx
+	accounts []auth.Account
+	genesis  []*types.GenesisAccount
+	failures map[int64]string // internal errors of the block RPC by height
+}
+
+// fakeNode serves the Tendermint RPC endpoints used by the explorer from a
+// fakeChain. It counts the calls of each RPC and keeps their last params.
+type fakeNode struct {
+	*httptest.Server
+	chain  fakeChain
+	latest int64 // read and set atomically
+
+	mtx      sync.Mutex
+	calls    map[string]int
+	params   map[string]url.Values
+	handlers map[string]http.HandlerFunc
+}
+
+func newFakeNode(t *testing.T, chain fakeChain) *fakeNode {
+	n := &fakeNode{
+		chain:    chain,
+		latest:   chain.latest,
+		calls:    map[string]int{},
+		params:   map[string]url.Values{},
+		handlers: map[string]http.HandlerFunc{},
+	}
+	for height := range chain.blocks {
+		if height > n.latest {
+			n.latest = height
+		}
+	}
+	cdc := app.MakeCodec()
+	n.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := strings.TrimPrefix(r.URL.Path, "/")
+		n.mtx.Lock()
+		n.calls[method]++
+		n.params[method] = r.URL.Query()
+		handler := n.handlers[r.URL.Path]
+		n.mtx.Unlock()
+		if handler != nil {
+			handler(w, r)
+			return
+		}
+		result, rpcErr := n.serve(t, cdc, method, r.URL.Query())
+		res := map[string]interface{}{"jsonrpc": "2.0", "id": ""}
+		if rpcErr != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			res["error"] = rpcErr
+		} else if result != nil {
+			res["result"] = result
+		} else {
+			http.NotFound(w, r)
+			return
+		}
+		require.Nil(t, json.NewEncoder(w).Encode(res))
+	}))
+	return n
+}
+
+// Latest returns the height of the latest block
+func (n *fakeNode) Latest() int64 { return atomic.LoadInt64(&n.latest) }
+
+// SetLatest commits the blocks up to height
+func (n *fakeNode) SetLatest(height int64) { atomic.StoreInt64(&n.latest, height) }
+
+// Handle serves path with handler instead of the chain
+func (n *fakeNode) Handle(path string, handler http.HandlerFunc) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.handlers[path] = handler
+}
+
+// Calls returns the number of calls of an RPC
+func (n *fakeNode) Calls(method string) int {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return n.calls[method]
+}
+
+// ResetCalls clears the call counts
+func (n *fakeNode) ResetCalls() {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.calls = map[string]int{}
+}
+
+// Params returns the params of the last call of an RPC
+func (n *fakeNode) Params(method string) url.Values {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return n.params[method]
+}
+
+func (n *fakeNode) blockMeta(height int64) BlockMeta {
+	hash := sha256.Sum256([]byte(strconv.FormatInt(height, 10)))
+	return BlockMeta{
+		Block_id: BlockId{Hash: fmt.Sprintf("%X", hash[:20])},
+		Header: Header{
+			Chain_id: n.chain.name,
+			Height:   strconv.FormatInt(height, 10),
+			Time:     fakeGenesisTime.Add(time.Duration(height) * time.Minute),
+			Num_txs:  strconv.Itoa(len(n.chain.blocks[height])),
+		},
+	}
+}
+
+// serve returns the result of an RPC, nil for unknown methods
+func (n *fakeNode) serve(t *testing.T, cdc *codec.Codec, method string, params url.Values) (interface{}, *RPCError) {
+	latest := n.Latest()
+	height := latest
+	if params.Get("height") != "" {
+		height, _ = strconv.ParseInt(params.Get("height"), 10, 64)
+	}
+	internal := func(data string) *RPCError {
+		return &RPCError{Code: rpcInternalError, Message: "Internal error", Data: data}
+	}
+
+	switch method {
+	case "health":
+		return struct{}{}, nil
+	case "status":
+		var res nodeStatus
+		res.NodeInfo.Network = n.chain.name
+		res.SyncInfo.LatestBlockHeight = latest
+		meta := n.blockMeta(latest)
+		res.SyncInfo.LatestBlockHash = meta.Block_id.Hash
+		res.SyncInfo.LatestBlockTime = meta.Header.Time
+		return res, nil
+	case "block", "block_results":
+		if height < 1 || height > latest {
+			return nil, internal("Height must be less than or equal to the current blockchain height")
+		}
+		if data, ok := n.chain.failures[height]; ok {
+			return nil, internal(data)
+		}
+		if method == "block_results" {
+			var res nodeBlockResults
+			res.Results.DeliverTx = []deliverTx{}
+			for _, tx := range n.chain.blocks[height] {
+				res.Results.DeliverTx = append(res.Results.DeliverTx, tx.result)
+			}
+			return res, nil
+		}
+		res := nodeBlock{Block_meta: n.blockMeta(height)}
+		res.Block.Data.Txs = [][]byte{}
+		for _, tx := range n.chain.blocks[height] {
+			res.Block.Data.Txs = append(res.Block.Data.Txs, tx.tx)
+		}
+		return res, nil
+	case "tx":
+		hash := strings.ToUpper(strings.TrimPrefix(params.Get("hash"), "0x"))
+		for h, txs := range n.chain.blocks {
+			for i, tx := range txs {
+				if h <= latest && fmt.Sprintf("%X", tmtypes.Tx(tx.tx).Hash()) == hash {
+					return nodeTx{Height: h, Index: i, TxResult: tx.result, Tx: tx.tx}, nil
+				}
+			}
+		}
+		return nil, internal(fmt.Sprintf("Tx (%s) not found", hash))
+	case "abci_query":
+		var res nodeABCIQuery
+		for _, acc := range n.chain.accounts {
+			if params.Get("data") == "0x"+hex.EncodeToString(auth.AddressStoreKey(acc.GetAddress())) {
+				bz, err := cdc.MarshalBinaryBare(acc)
+				require.Nil(t, err)
+				res.Response.Value = bz
+			}
+		}
+		return res, nil
+	case "blockchain":
+		from, to := latest-MaxBlocksPage+1, latest
+		if params.Get("maxHeight") != "" {
+			from, _ = strconv.ParseInt(params.Get("minHeight"), 10, 64)
+			to, _ = strconv.ParseInt(params.Get("maxHeight"), 10, 64)
+		}
+		res := nodeBlockchain{LastHeight: latest, BlockMetas: []BlockMeta{}}
+		for h := to; h >= from && h >= 1; h-- {
+			if h <= latest {
+				res.BlockMetas = append(res.BlockMetas, n.blockMeta(h))
+			}
+		}
+		return res, nil
+	case "validators":
+		return json.RawMessage(`{"block_height":"` + strconv.FormatInt(latest, 10) + `","validators":[` +
+			`{"address":"AB","pub_key":{"type":"tendermint/PubKeyEd25519","value":"AAAA"},"voting_power":"10"}]}`), nil
+	case "genesis":
+		state, err := cdc.MarshalJSON(types.GenesisState{Accounts: n.chain.genesis})
+		require.Nil(t, err)
+		var res nodeGenesis
+		res.Genesis.GenesisTime = fakeGenesisTime
+		res.Genesis.AppState = state
+		return res, nil
+	}
+	return nil, nil
+}
+
+func getBlock(t *testing.T, router http.Handler, id string) (int, JsonBlock) {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/block/"+id, nil))
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var block JsonBlock
+	if rec.Code == http.StatusOK {
+		require.Nil(t, json.Unmarshal(rec.Body.Bytes(), &block))
+	} else {
+		var res ErrorResponse
+		require.Nil(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		require.NotEmpty(t, res.Error)
+	}
+	return rec.Code, block
+}
+
+func TestNodeFailover(t *testing.T) {
+	primary := newFakeNode(t, fakeChain{name: "primary", latest: 100})
+	backup := newFakeNode(t, fakeChain{name: "backup", latest: 100})
+	defer backup.Close()
+
+	_, err := NewNodePool([]string{" ", ""}, time.Second)
+	require.NotNil(t, err)
+
+	nodes, err := NewNodePool([]string{primary.URL, backup.URL}, time.Second)
+	require.Nil(t, err)
+	router := NewServer(nodes).Router()
+
+	code, block := getBlock(t, router, "5")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "primary", block.Result.Block_meta.Header.Chain_id)
+	require.Equal(t, "5", block.Result.Block_meta.Header.Height)
+
+	// errors of a reachable node are not a reason to fail over
+	code, _ = getBlock(t, router, "1000")
+	require.Equal(t, http.StatusNotFound, code)
+	code, _ = getBlock(t, router, "abc")
+	require.Equal(t, http.StatusBadRequest, code)
+	require.True(t, nodes.Nodes()[0].Healthy)
+
+	// the backup answers once the primary is down
+	primary.Close()
+	code, block = getBlock(t, router, "6")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "backup", block.Result.Block_meta.Header.Chain_id)
+	require.False(t, nodes.Nodes()[0].Healthy)
+	require.True(t, nodes.Nodes()[1].Healthy)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// no reachable node is reported instead of exiting
+	backup.Close()
+	nodes.CheckHealth()
+	code, _ = getBlock(t, router, "7")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestNodeFailoverHeight(t *testing.T) {
+	// block 666 can not be read by either node
+	failures := map[int64]string{666: "leveldb: closed"}
+	lagging := newFakeNode(t, fakeChain{name: "lagging", latest: 700, failures: failures})
+	defer lagging.Close()
+	synced := newFakeNode(t, fakeChain{name: "synced", latest: 1000, failures: failures})
+	defer synced.Close()
+
+	nodes, err := NewNodePool([]string{lagging.URL, synced.URL}, time.Second)
+	require.Nil(t, err)
+	router := NewServer(nodes).Router()
+
+	code, block := getBlock(t, router, "700")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "lagging", block.Result.Block_meta.Header.Chain_id)
+
+	// a height the first node does not have yet is fetched from the next
+	code, block = getBlock(t, router, "701")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "synced", block.Result.Block_meta.Header.Chain_id)
+	require.True(t, nodes.Nodes()[0].Healthy)
+
+	// no node has a height above the latest block
+	code, _ = getBlock(t, router, "1001")
+	require.Equal(t, http.StatusNotFound, code)
+
+	// other internal errors of the nodes are not a missing block
+	code, _ = getBlock(t, router, "666")
+	require.Equal(t, http.StatusBadGateway, code)
+	require.True(t, nodes.Nodes()[0].Healthy)
+	require.True(t, nodes.Nodes()[1].Healthy)
+
+	require.True(t, heightAddressed(url.Values{"minHeight": {"1"}, "maxHeight": {"20"}}))
+	require.False(t, heightAddressed(url.Values{"hash": {"0xAB"}}))
+	require.False(t, heightAddressed(nil))
+}
@@ -0,0 +1,999 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package main
+
+import (
+	"net/http"
+)
+
+// openAPISpec describes the REST endpoints and is served on /openapi.yaml.
+// The types of the client package are written by hand after its schemas,
+// TestOpenAPIExamples decodes the example of every schema into them and
+// the tests validate the responses of the server against it, so change all
+// three together.
+const openAPISpec = `openapi: 3.0.0
+info:
+  title: Beyond block explorer API
+  version: 1.0.0
+  description: >
+    Blocks, txs, accounts and validators are read from the configured
+    Tendermint nodes. Orders, payments, activity and analytics are read from
+    the order index and return 503 while it is not enabled. Successful
+    responses carry an ETag and requests with a matching If-None-Match get a
+    304 without a body. GET /ws and POST /graphql are described in the
+    README.
+paths:
+  /block/{height}:
+    get:
+      operationId: getBlock
+      summary: Block at a height with its decoded txs
+      parameters:
+        - name: height
+          in: path
+          required: true
+          schema: {type: integer, format: int64, minimum: 1}
+      responses:
+        '200':
+          description: The block
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/BlockResponse'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        default: {$ref: '#/components/responses/Error'}
+  /blocks:
+    get:
+      operationId: getBlocks
+      summary: Headers of at most 20 blocks, newest first
+      description: The latest blocks are returned without from and to.
+      parameters:
+        - {$ref: '#/components/parameters/from'}
+        - {$ref: '#/components/parameters/to'}
+      responses:
+        '200':
+          description: The block headers
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/BlockList'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        default: {$ref: '#/components/responses/Error'}
+  /tx/{hash}:
+    get:
+      operationId: getTx
+      summary: Decoded tx with its result
+      parameters:
+        - name: hash
+          in: path
+          required: true
+          schema: {type: string, pattern: '^(0[xX])?[0-9a-fA-F]{40}$'}
+      responses:
+        '200':
+          description: The tx
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Tx'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        default: {$ref: '#/components/responses/Error'}
+  /account/{address}:
+    get:
+      operationId: getAccount
+      summary: Account with its coins, name, MAC address, price and secure element
+      parameters:
+        - {$ref: '#/components/parameters/addressPath'}
+      responses:
+        '200':
+          description: The account
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Account'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        default: {$ref: '#/components/responses/Error'}
+  /validators:
+    get:
+      operationId: getValidators
+      summary: Current validator set
+      responses:
+        '200':
+          description: The validators
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/ValidatorList'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        default: {$ref: '#/components/responses/Error'}
+  /status:
+    get:
+      operationId: getStatus
+      summary: Chain ID, latest block and the state of the configured nodes
+      responses:
+        '200':
+          description: The status
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Status'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        default: {$ref: '#/components/responses/Error'}
+  /search:
+    get:
+      operationId: search
+      summary: Block, tx or account for a height, tx hash or bech32 address
+      parameters:
+        - name: q
+          in: query
+          required: true
+          schema: {type: string}
+      responses:
+        '200':
+          description: The object found
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/SearchResult'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        default: {$ref: '#/components/responses/Error'}
+  /health:
+    get:
+      operationId: getHealth
+      summary: State of the configured nodes
+      responses:
+        '200':
+          description: At least one node is healthy
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/NodeList'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        '503':
+          description: No node is healthy
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/NodeList'}
+  /orders:
+    get:
+      operationId: getOrders
+      summary: Indexed orders, newest first
+      parameters:
+        - {name: buyer, in: query, schema: {type: string}}
+        - {name: seller, in: query, schema: {type: string}}
+        - {name: status, in: query, schema: {type: string, enum: [open, finalized]}}
+        - {$ref: '#/components/parameters/from'}
+        - {$ref: '#/components/parameters/to'}
+        - {$ref: '#/components/parameters/limit'}
+        - {$ref: '#/components/parameters/offset'}
+      responses:
+        '200':
+          description: The orders
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Order'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        default: {$ref: '#/components/responses/Error'}
+  /orders/{buyer}/{number}:
+    get:
+      operationId: getOrder
+      summary: Indexed order by its buyer and order number
+      parameters:
+        - {name: buyer, in: path, required: true, schema: {type: string}}
+        - {name: number, in: path, required: true, schema: {type: integer, format: int64, minimum: 0}}
+      responses:
+        '200':
+          description: The order
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Order'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        default: {$ref: '#/components/responses/Error'}
+  /payments:
+    get:
+      operationId: getPayments
+      summary: Coin transfers of send msgs, newest first
+      parameters:
+        - {name: address, in: query, description: Sender or recipient, schema: {type: string}}
+        - {$ref: '#/components/parameters/from'}
+        - {$ref: '#/components/parameters/to'}
+        - {$ref: '#/components/parameters/limit'}
+        - {$ref: '#/components/parameters/offset'}
+      responses:
+        '200':
+          description: The payments
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Payment'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        default: {$ref: '#/components/responses/Error'}
+  /activity/{address}:
+    get:
+      operationId: getActivity
+      summary: First and last height and tx count of an address
+      parameters:
+        - {$ref: '#/components/parameters/addressPath'}
+      responses:
+        '200':
+          description: The activity
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Activity'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        default: {$ref: '#/components/responses/Error'}
+  /index/status:
+    get:
+      operationId: getIndexStatus
+      summary: Last indexed height and latest block height
+      responses:
+        '200':
+          description: The progress of the index
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/IndexStatus'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        default: {$ref: '#/components/responses/Error'}
+  /analytics/energy:
+    get:
+      operationId: getEnergyReports
+      summary: Energy sold and revenue per station and time bucket
+      parameters:
+        - {name: bucket, in: query, schema: {type: string, enum: [hour, day, week, month], default: day}}
+        - {$ref: '#/components/parameters/station'}
+        - {$ref: '#/components/parameters/since'}
+        - {$ref: '#/components/parameters/until'}
+        - {$ref: '#/components/parameters/denom'}
+      responses:
+        '200':
+          description: The reports, by bucket and station
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/EnergyReport'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        default: {$ref: '#/components/responses/Error'}
+  /analytics/top-buyers:
+    get:
+      operationId: getTopBuyers
+      summary: Buyers by energy bought
+      parameters:
+        - {$ref: '#/components/parameters/station'}
+        - {$ref: '#/components/parameters/since'}
+        - {$ref: '#/components/parameters/until'}
+        - {$ref: '#/components/parameters/denom'}
+        - {$ref: '#/components/parameters/limit'}
+      responses:
+        '200':
+          description: The buyers
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/BuyerReport'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        default: {$ref: '#/components/responses/Error'}
+  /balances/{address}/history:
+    get:
+      operationId: getBalanceHistory
+      summary: Balance changes of an address by fees and send msgs, newest first
+      parameters:
+        - {$ref: '#/components/parameters/addressPath'}
+        - {name: denom, in: query, description: Denomination, all if empty, schema: {type: string}}
+        - {$ref: '#/components/parameters/from'}
+        - {$ref: '#/components/parameters/to'}
+        - {$ref: '#/components/parameters/since'}
+        - {$ref: '#/components/parameters/until'}
+        - {$ref: '#/components/parameters/limit'}
+        - {$ref: '#/components/parameters/offset'}
+      responses:
+        '200':
+          description: The balance changes
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/BalanceChange'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        default: {$ref: '#/components/responses/Error'}
+  /richlist:
+    get:
+      operationId: getRichList
+      summary: Addresses by balance in a denomination, richest first
+      parameters:
+        - {name: denom, in: query, description: Denomination, schema: {type: string, default: byndcoin}}
+        - {$ref: '#/components/parameters/limit'}
+        - {$ref: '#/components/parameters/offset'}
+      responses:
+        '200':
+          description: The holders
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/RichList'}
+        '304': {$ref: '#/components/responses/NotModified'}
+        default: {$ref: '#/components/responses/Error'}
+  /openapi.yaml:
+    get:
+      operationId: getOpenAPI
+      summary: This document
+      responses:
+        '200':
+          description: The OpenAPI document
+          content:
+            application/yaml:
+              schema: {type: string}
+        '304': {$ref: '#/components/responses/NotModified'}
+components:
+  parameters:
+    addressPath:
+      name: address
+      in: path
+      required: true
+      description: Bech32 account address
+      schema: {type: string}
+    from:
+      name: from
+      in: query
+      description: First block height
+      schema: {type: integer, format: int64, minimum: 1}
+    to:
+      name: to
+      in: query
+      description: Last block height
+      schema: {type: integer, format: int64, minimum: 1}
+    limit:
+      name: limit
+      in: query
+      schema: {type: integer, minimum: 0, maximum: 500, default: 50}
+    offset:
+      name: offset
+      in: query
+      schema: {type: integer, minimum: 0, default: 0}
+    station:
+      name: station
+      in: query
+      description: Bech32 address of the seller
+      schema: {type: string}
+    since:
+      name: since
+      in: query
+      description: RFC3339 time or YYYY-MM-DD date, included
+      schema: {type: string}
+    until:
+      name: until
+      in: query
+      description: RFC3339 time or YYYY-MM-DD date, excluded
+      schema: {type: string}
+    denom:
+      name: denom
+      in: query
+      description: Denomination of the revenue
+      schema: {type: string, default: byndcoin}
+  responses:
+    NotModified:
+      description: The resource matches the If-None-Match ETag
+    Error:
+      description: >
+        400 for invalid parameters, 404 for unknown objects, 502 for invalid
+        node responses and 503 when no node is reachable or the index is not
+        enabled
+      content:
+        application/json:
+          schema: {$ref: '#/components/schemas/Error'}
+  schemas:
+    Error:
+      type: object
+      required: [error]
+      properties:
+        error: {type: string}
+    BlockResponse:
+      type: object
+      required: [result]
+      properties:
+        result: {$ref: '#/components/schemas/Block'}
+    Block:
+      type: object
+      required: [block_meta, block]
+      properties:
+        block_meta: {$ref: '#/components/schemas/BlockMeta'}
+        block:
+          type: object
+          required: [data]
+          properties:
+            data:
+              type: object
+              required: [txs]
+              properties:
+                txs:
+                  type: array
+                  items: {$ref: '#/components/schemas/Tx'}
+      example:
+        block_meta:
+          block_id:
+            hash: 3A7F1C9E5B2D8A4F6C0E3B9D7A1F5C8E2B6D4A0F
+          header:
+            chain_id: beyond-testnet
+            height: '42'
+            time: '2019-03-04T10:15:00Z'
+            num_txs: '1'
+            total_txs: '57'
+            app_hash: 7C6F2E0A5B9D4C1E8F3A6B2D9C4E1F7A0B5D8C3E
+            validators_hash: 2E9B4F7A1C8D3E6B0F5A9C2D7E4B1F8A3C6D0E5B
+            last_commit_hash: 5D0C8B3A6F1E9D4C7B2A5F0E8D3C6B1A4F9E2D7C
+            consensus_hash: 0F4E8D2C6B1A5F9E3D7C1B5A9F3E7D1C5B9A3F7E
+        block:
+          data:
+            txs:
+              - hash: 9F0A4B1C7E2D3A5B6C8D9E0F1A2B3C4D5E6F7A8B
+                height: 42
+                index: 0
+                code: 0
+                gasWanted: 200000
+                log: Msg 0
+                msgs:
+                  - type: initOrder
+                    value:
+                      initiator: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+                      recipient: byndaddr1mv2e82j0sxrg5c7dr7t5nc8cc6zvhgyqs9phrd
+                      agreedPrice: 2
+                      estimatedCharge: 30
+                fee:
+                  amount: [{denom: byndcoin, amount: '1'}]
+                  gas: 200000
+                feePayer: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+                memo: charging bay 3
+                tags:
+                  - {key: action, value: initOrder}
+    BlockMeta:
+      type: object
+      required: [block_id, header]
+      properties:
+        block_id:
+          type: object
+          properties:
+            hash: {type: string}
+        header: {$ref: '#/components/schemas/Header'}
+      example:
+        block_id:
+          hash: 3A7F1C9E5B2D8A4F6C0E3B9D7A1F5C8E2B6D4A0F
+        header:
+          chain_id: beyond-testnet
+          height: '42'
+          time: '2019-03-04T10:15:00Z'
+          num_txs: '1'
+          total_txs: '57'
+          app_hash: 7C6F2E0A5B9D4C1E8F3A6B2D9C4E1F7A0B5D8C3E
+          validators_hash: 2E9B4F7A1C8D3E6B0F5A9C2D7E4B1F8A3C6D0E5B
+          last_commit_hash: 5D0C8B3A6F1E9D4C7B2A5F0E8D3C6B1A4F9E2D7C
+          consensus_hash: 0F4E8D2C6B1A5F9E3D7C1B5A9F3E7D1C5B9A3F7E
+    Header:
+      type: object
+      description: Counts and heights are decimal strings as returned by Tendermint
+      required: [time]
+      properties:
+        chain_id: {type: string}
+        height: {type: string}
+        time: {type: string, format: date-time}
+        num_txs: {type: string}
+        total_txs: {type: string}
+        app_hash: {type: string}
+        validators_hash: {type: string}
+        last_commit_hash: {type: string}
+        consensus_hash: {type: string}
+      example:
+        chain_id: beyond-testnet
+        height: '42'
+        time: '2019-03-04T10:15:00Z'
+        num_txs: '1'
+        total_txs: '57'
+        app_hash: 7C6F2E0A5B9D4C1E8F3A6B2D9C4E1F7A0B5D8C3E
+        validators_hash: 2E9B4F7A1C8D3E6B0F5A9C2D7E4B1F8A3C6D0E5B
+        last_commit_hash: 5D0C8B3A6F1E9D4C7B2A5F0E8D3C6B1A4F9E2D7C
+        consensus_hash: 0F4E8D2C6B1A5F9E3D7C1B5A9F3E7D1C5B9A3F7E
+    BlockList:
+      type: object
+      required: [lastHeight, from, to, blocks]
+      properties:
+        lastHeight: {type: integer, format: int64}
+        from: {type: integer, format: int64}
+        to: {type: integer, format: int64}
+        blocks:
+          type: array
+          items: {$ref: '#/components/schemas/BlockMeta'}
+      example:
+        lastHeight: 57
+        from: 42
+        to: 42
+        blocks:
+          - block_id:
+              hash: 3A7F1C9E5B2D8A4F6C0E3B9D7A1F5C8E2B6D4A0F
+            header:
+              chain_id: beyond-testnet
+              height: '42'
+              time: '2019-03-04T10:15:00Z'
+              num_txs: '1'
+              total_txs: '57'
+              app_hash: 7C6F2E0A5B9D4C1E8F3A6B2D9C4E1F7A0B5D8C3E
+              validators_hash: 2E9B4F7A1C8D3E6B0F5A9C2D7E4B1F8A3C6D0E5B
+              last_commit_hash: 5D0C8B3A6F1E9D4C7B2A5F0E8D3C6B1A4F9E2D7C
+              consensus_hash: 0F4E8D2C6B1A5F9E3D7C1B5A9F3E7D1C5B9A3F7E
+    Tx:
+      type: object
+      required: [hash, height, index, code, msgs, fee, tags]
+      properties:
+        hash: {type: string}
+        height: {type: integer, format: int64}
+        index: {type: integer}
+        code: {type: integer, description: 0 if the tx was applied}
+        gasWanted: {type: integer, format: int64, description: 0 if the ante handler rejected the tx, which then paid no fee}
+        log: {type: string}
+        msgs:
+          type: array
+          items: {$ref: '#/components/schemas/Msg'}
+        fee: {$ref: '#/components/schemas/Fee'}
+        feePayer: {type: string, description: First signer, the fee is deducted from its account}
+        memo: {type: string}
+        tags:
+          type: array
+          items: {$ref: '#/components/schemas/Tag'}
+        error: {type: string, description: Set if the tx could not be decoded}
+      example:
+        hash: 9F0A4B1C7E2D3A5B6C8D9E0F1A2B3C4D5E6F7A8B
+        height: 42
+        index: 0
+        code: 0
+        gasWanted: 200000
+        log: Msg 0
+        msgs:
+          - type: initOrder
+            value:
+              initiator: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+              recipient: byndaddr1mv2e82j0sxrg5c7dr7t5nc8cc6zvhgyqs9phrd
+              agreedPrice: 2
+              estimatedCharge: 30
+        fee:
+          amount: [{denom: byndcoin, amount: '1'}]
+          gas: 200000
+        feePayer: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+        memo: charging bay 3
+        tags:
+          - {key: action, value: initOrder}
+    Msg:
+      type: object
+      required: [type, value]
+      properties:
+        type:
+          type: string
+          description: initOrder, finalizeOrder, send or route/type of other msgs
+        value:
+          description: >
+            InitOrderMsg, FinalizeOrderMsg or SendMsg by type, other msgs as
+            encoded by the app codec
+          nullable: true
+      example:
+        type: initOrder
+        value:
+          initiator: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+          recipient: byndaddr1mv2e82j0sxrg5c7dr7t5nc8cc6zvhgyqs9phrd
+          agreedPrice: 2
+          estimatedCharge: 30
+    InitOrderMsg:
+      type: object
+      required: [initiator, recipient, agreedPrice, estimatedCharge]
+      properties:
+        initiator: {type: string}
+        recipient: {type: string}
+        agreedPrice: {type: integer, format: int64, minimum: 0}
+        estimatedCharge: {type: integer, format: int64, minimum: 0}
+      example:
+        initiator: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+        recipient: byndaddr1mv2e82j0sxrg5c7dr7t5nc8cc6zvhgyqs9phrd
+        agreedPrice: 2
+        estimatedCharge: 30
+    FinalizeOrderMsg:
+      type: object
+      required: [initiator, recipient, totalAmount, totalCharge]
+      properties:
+        initiator: {type: string}
+        recipient: {type: string}
+        totalAmount: {type: integer, format: int64, minimum: 0}
+        totalCharge: {type: integer, format: int64, minimum: 0}
+      example:
+        initiator: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+        recipient: byndaddr1mv2e82j0sxrg5c7dr7t5nc8cc6zvhgyqs9phrd
+        totalAmount: 60
+        totalCharge: 30
+    SendMsg:
+      type: object
+      required: [inputs, outputs]
+      properties:
+        inputs:
+          type: array
+          items: {$ref: '#/components/schemas/Transfer'}
+        outputs:
+          type: array
+          items: {$ref: '#/components/schemas/Transfer'}
+      example:
+        inputs:
+          - address: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+            coins: [{denom: byndcoin, amount: '60'}]
+        outputs:
+          - address: byndaddr1mv2e82j0sxrg5c7dr7t5nc8cc6zvhgyqs9phrd
+            coins: [{denom: byndcoin, amount: '60'}]
+    Transfer:
+      type: object
+      required: [address, coins]
+      properties:
+        address: {type: string}
+        coins: {$ref: '#/components/schemas/Coins'}
+      example:
+        address: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+        coins: [{denom: byndcoin, amount: '60'}]
+    Fee:
+      type: object
+      required: [amount, gas]
+      properties:
+        amount: {$ref: '#/components/schemas/Coins'}
+        gas: {type: integer, format: int64}
+      example:
+        amount: [{denom: byndcoin, amount: '1'}]
+        gas: 200000
+    Coins:
+      type: array
+      nullable: true
+      items: {$ref: '#/components/schemas/Coin'}
+      example:
+        - {denom: byndcoin, amount: '100'}
+    Coin:
+      type: object
+      required: [denom, amount]
+      properties:
+        denom: {type: string}
+        amount: {type: string, description: Decimal integer}
+      example:
+        denom: byndcoin
+        amount: '100'
+    Tag:
+      type: object
+      required: [key, value]
+      properties:
+        key: {type: string}
+        value: {type: string}
+      example:
+        key: action
+        value: initOrder
+    Account:
+      type: object
+      required: [address, coins, accountNumber, sequence, name, macAddress, price, hsmInfo]
+      properties:
+        address: {type: string}
+        coins: {$ref: '#/components/schemas/Coins'}
+        pubKey: {type: string, description: Bech32 public key}
+        accountNumber: {type: integer, format: int64}
+        sequence: {type: integer, format: int64}
+        name: {type: string}
+        macAddress: {type: string}
+        price: {type: string}
+        hsmInfo: {$ref: '#/components/schemas/HsmInfo'}
+      example:
+        address: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+        coins: [{denom: byndcoin, amount: '100'}]
+        pubKey: byndpub1addwnpepqdw295n2flsgzeldl4hggpkeunlpaqhwv03mwpxz62uelhc0k6ny7au7g56
+        accountNumber: 3
+        sequence: 7
+        name: car
+        macAddress: 00-05-9A-3C-7A-00
+        price: '1'
+        hsmInfo:
+          romId: TBI0VniaAFM=
+          manId: EjQ=
+          pubKey: BC1xFkK3JrBEAWJ8qfusMvXIUw+xkDzE2wIlhxeSGkiBofzkNjhU/4iM/0uOeHXWAMJoI5BBKoz3mzfQsRFIsPo=
+          page: 0
+          pageData: mLiBbVqfhYZ1/PhuCnAEgbEF4/97cAk0xzG4q2Z7Ewg=
+          authority: manufacturer
+          verified: true
+    HsmInfo:
+      type: object
+      description: Secure element of the account, bytes are base64
+      properties:
+        romId: {type: string, format: byte, nullable: true}
+        manId: {type: string, format: byte, nullable: true}
+        pubKey: {type: string, format: byte, nullable: true}
+        page: {type: integer}
+        pageData: {type: string, format: byte, nullable: true}
+        authority: {type: string}
+        verified: {type: boolean}
+      example:
+        romId: TBI0VniaAFM=
+        manId: EjQ=
+        pubKey: BC1xFkK3JrBEAWJ8qfusMvXIUw+xkDzE2wIlhxeSGkiBofzkNjhU/4iM/0uOeHXWAMJoI5BBKoz3mzfQsRFIsPo=
+        page: 0
+        pageData: mLiBbVqfhYZ1/PhuCnAEgbEF4/97cAk0xzG4q2Z7Ewg=
+        authority: manufacturer
+        verified: true
+    Validator:
+      type: object
+      required: [address, pubKey, votingPower]
+      properties:
+        address: {type: string}
+        pubKey:
+          type: object
+          description: Amino JSON of the public key
+          properties:
+            type: {type: string}
+            value: {type: string}
+        votingPower: {type: integer, format: int64}
+      example:
+        address: 6F2A9C1E4B7D0A3F8C5E2B9D6A1F4C7E0B3D8A5F
+        pubKey:
+          type: tendermint/PubKeyEd25519
+          value: +CrzIWC8UxEsoRirv1f6b+1H65ApGh0dkvQ4ri7XTvY=
+        votingPower: 10
+    ValidatorList:
+      type: object
+      required: [height, validators]
+      properties:
+        height: {type: integer, format: int64}
+        validators:
+          type: array
+          items: {$ref: '#/components/schemas/Validator'}
+      example:
+        height: 42
+        validators:
+          - address: 6F2A9C1E4B7D0A3F8C5E2B9D6A1F4C7E0B3D8A5F
+            pubKey:
+              type: tendermint/PubKeyEd25519
+              value: +CrzIWC8UxEsoRirv1f6b+1H65ApGh0dkvQ4ri7XTvY=
+            votingPower: 10
+    Node:
+      type: object
+      required: [url, healthy, lastCheck]
+      properties:
+        url: {type: string}
+        healthy: {type: boolean}
+        lastCheck: {type: string, format: date-time}
+        lastError: {type: string}
+      example:
+        url: http://localhost:26657
+        healthy: false
+        lastCheck: '2019-03-04T10:15:05Z'
+        lastError: connection refused
+    NodeList:
+      type: array
+      items: {$ref: '#/components/schemas/Node'}
+      example:
+        - url: http://localhost:26657
+          healthy: false
+          lastCheck: '2019-03-04T10:15:05Z'
+          lastError: connection refused
+    Status:
+      type: object
+      required: [chainId, moniker, version, latestBlockHeight, latestBlockHash, latestBlockTime, catchingUp, nodes]
+      properties:
+        chainId: {type: string}
+        moniker: {type: string}
+        version: {type: string}
+        latestBlockHeight: {type: integer, format: int64}
+        latestBlockHash: {type: string}
+        latestBlockTime: {type: string, format: date-time}
+        catchingUp: {type: boolean}
+        nodes: {$ref: '#/components/schemas/NodeList'}
+      example:
+        chainId: beyond-testnet
+        moniker: node0
+        version: 0.25.0
+        latestBlockHeight: 57
+        latestBlockHash: 3A7F1C9E5B2D8A4F6C0E3B9D7A1F5C8E2B6D4A0F
+        latestBlockTime: '2019-03-04T10:19:40Z'
+        catchingUp: false
+        nodes:
+          - url: http://localhost:26657
+            healthy: false
+            lastCheck: '2019-03-04T10:15:05Z'
+            lastError: connection refused
+    SearchResult:
+      type: object
+      required: [type, value]
+      properties:
+        type: {type: string, enum: [block, tx, account]}
+        value:
+          description: Block, Tx or Account by type
+          type: object
+      example:
+        type: tx
+        value:
+          hash: 9F0A4B1C7E2D3A5B6C8D9E0F1A2B3C4D5E6F7A8B
+          height: 42
+          index: 0
+          code: 0
+          gasWanted: 200000
+          log: Msg 0
+          msgs:
+            - type: initOrder
+              value:
+                initiator: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+                recipient: byndaddr1mv2e82j0sxrg5c7dr7t5nc8cc6zvhgyqs9phrd
+                agreedPrice: 2
+                estimatedCharge: 30
+          fee:
+            amount: [{denom: byndcoin, amount: '1'}]
+            gas: 200000
+          feePayer: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+          memo: charging bay 3
+          tags:
+            - {key: action, value: initOrder}
+    Order:
+      type: object
+      required: [buyer, number, seller, agreedPrice, estimatedCharge, initHeight, initTime, initTx]
+      properties:
+        buyer: {type: string}
+        number: {type: integer, format: int64, minimum: 0}
+        seller: {type: string}
+        agreedPrice: {type: integer, format: int64, minimum: 0}
+        estimatedCharge: {type: integer, format: int64, minimum: 0}
+        initHeight: {type: integer, format: int64}
+        initTime: {type: string, format: date-time}
+        initTx: {type: string}
+        totalAmount: {type: integer, format: int64, minimum: 0, description: Set once finalized}
+        totalCharge: {type: integer, format: int64, minimum: 0, description: Set once finalized}
+        finalizeHeight: {type: integer, format: int64}
+        finalizeTime: {type: string, format: date-time}
+        finalizeTx: {type: string}
+      example:
+        buyer: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+        number: 4
+        seller: byndaddr1mv2e82j0sxrg5c7dr7t5nc8cc6zvhgyqs9phrd
+        agreedPrice: 2
+        estimatedCharge: 30
+        initHeight: 42
+        initTime: '2019-03-04T10:15:00Z'
+        initTx: 9F0A4B1C7E2D3A5B6C8D9E0F1A2B3C4D5E6F7A8B
+        totalAmount: 60
+        totalCharge: 30
+        finalizeHeight: 49
+        finalizeTime: '2019-03-04T10:16:10Z'
+        finalizeTx: 1B2C3D4E5F60718293A4B5C6D7E8F90A1B2C3D4E
+    Payment:
+      type: object
+      required: [tx, height, time, sender, recipient, denom, amount]
+      properties:
+        tx: {type: string}
+        height: {type: integer, format: int64}
+        time: {type: string, format: date-time}
+        sender: {type: string}
+        recipient: {type: string}
+        denom: {type: string}
+        amount: {type: integer, format: int64}
+        orderBuyer: {type: string, description: Set for payments of a finalizeOrder tx}
+        orderNumber: {type: integer, format: int64, minimum: 0}
+      example:
+        tx: 1B2C3D4E5F60718293A4B5C6D7E8F90A1B2C3D4E
+        height: 49
+        time: '2019-03-04T10:16:10Z'
+        sender: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+        recipient: byndaddr1mv2e82j0sxrg5c7dr7t5nc8cc6zvhgyqs9phrd
+        denom: byndcoin
+        amount: 60
+        orderBuyer: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+        orderNumber: 4
+    Activity:
+      type: object
+      required: [address, firstHeight, firstTime, lastHeight, lastTime, txCount]
+      properties:
+        address: {type: string}
+        firstHeight: {type: integer, format: int64}
+        firstTime: {type: string, format: date-time}
+        lastHeight: {type: integer, format: int64}
+        lastTime: {type: string, format: date-time}
+        txCount: {type: integer, format: int64}
+      example:
+        address: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+        firstHeight: 3
+        firstTime: '2019-03-04T10:05:20Z'
+        lastHeight: 49
+        lastTime: '2019-03-04T10:16:10Z'
+        txCount: 12
+    IndexStatus:
+      type: object
+      required: [checkpoint, latestBlockHeight, balancesFrom]
+      properties:
+        checkpoint: {type: integer, format: int64}
+        latestBlockHeight: {type: integer, format: int64}
+        balancesFrom:
+          type: integer
+          format: int64
+          description: Height from which balances are tracked, 0 if from the genesis
+      example:
+        checkpoint: 57
+        latestBlockHeight: 57
+        balancesFrom: 0
+    BalanceChange:
+      type: object
+      required: [height, time, kind, address, denom, delta, balance]
+      properties:
+        height: {type: integer, format: int64, description: 0 for the genesis}
+        time: {type: string, format: date-time}
+        tx: {type: string, description: Unset for the genesis}
+        kind: {type: string, enum: [genesis, fee, transfer]}
+        address: {type: string}
+        denom: {type: string}
+        delta: {type: integer, format: int64}
+        balance: {type: integer, format: int64, description: Balance after the change}
+      example:
+        height: 49
+        time: '2019-03-04T10:16:10Z'
+        tx: 1B2C3D4E5F60718293A4B5C6D7E8F90A1B2C3D4E
+        kind: transfer
+        address: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+        denom: byndcoin
+        delta: -60
+        balance: 40
+    RichList:
+      type: object
+      required: [denom, supply, holders]
+      properties:
+        denom: {type: string}
+        supply: {type: integer, format: int64, description: Sum of the positive indexed balances}
+        holders:
+          type: array
+          items: {$ref: '#/components/schemas/Holder'}
+      example:
+        denom: byndcoin
+        supply: 400
+        holders:
+          - {rank: 1, address: byndaddr1mv2e82j0sxrg5c7dr7t5nc8cc6zvhgyqs9phrd, amount: 300, share: 0.75}
+          - {rank: 2, address: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27, amount: 100, share: 0.25}
+    Holder:
+      type: object
+      required: [rank, address, amount, share]
+      properties:
+        rank: {type: integer}
+        address: {type: string}
+        amount: {type: integer, format: int64}
+        share: {type: number, description: Part of the supply}
+      example:
+        rank: 1
+        address: byndaddr1mv2e82j0sxrg5c7dr7t5nc8cc6zvhgyqs9phrd
+        amount: 300
+        share: 0.75
+    EnergyReport:
+      type: object
+      required: [station, bucket, orders, energy, averageSession, revenue, estimatedCharge, chargeAccuracy]
+      properties:
+        station: {type: string}
+        bucket: {type: string, format: date-time, description: Start of the bucket}
+        orders: {type: integer, format: int64}
+        energy: {type: integer, format: int64, description: kWh sold}
+        averageSession: {type: number, description: Average kWh per order}
+        revenue: {type: integer, format: int64}
+        estimatedCharge: {type: integer, format: int64}
+        chargeAccuracy:
+          type: number
+          description: Actual charge over the estimated charge, 0 if nothing was estimated
+      example:
+        station: byndaddr1mv2e82j0sxrg5c7dr7t5nc8cc6zvhgyqs9phrd
+        bucket: '2019-03-04T00:00:00Z'
+        orders: 4
+        energy: 50
+        averageSession: 12.5
+        revenue: 100
+        estimatedCharge: 40
+        chargeAccuracy: 1.25
+    BuyerReport:
+      type: object
+      required: [buyer, orders, energy, spent]
+      properties:
+        buyer: {type: string}
+        orders: {type: integer, format: int64}
+        energy: {type: integer, format: int64}
+        spent: {type: integer, format: int64}
+      example:
+        buyer: byndaddr1svf6jrfvfed33avtza9y9h8ckmcylpqs049j27
+        orders: 4
+        energy: 50
+        spent: 100
+`
+
+// GetOpenAPI returns the OpenAPI document of the REST endpoints
+func (s *Server) GetOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write([]byte(openAPISpec))
+}
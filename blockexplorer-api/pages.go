@@ -0,0 +1,413 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gorilla/mux"
+)
+
+// OrdersPerPage is the number of orders on an account page
+const OrdersPerPage = 20
+
+// pageTemplates are the HTML pages served under /explorer. Every page
+// starts with the header and search form and ends with the footer.
+const pageTemplates = `
+{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - Beyond explorer</title>
+<style>
+body { font-family: sans-serif; margin: 0 auto; max-width: 1100px; padding: 0 1em; color: #222; }
+header { display: flex; justify-content: space-between; align-items: center; border-bottom: 1px solid #ccc; }
+header a { color: #222; text-decoration: none; font-weight: bold; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5em; }
+th, td { text-align: left; padding: .3em .6em; border-bottom: 1px solid #eee; vertical-align: top; }
+th { background: #f5f5f5; }
+dl { display: grid; grid-template-columns: max-content auto; gap: .3em 1em; }
+dt { font-weight: bold; }
+dd { margin: 0; }
+.hash { font-family: monospace; word-break: break-all; }
+.failed { color: #b00; }
+.msg { border: 1px solid #ddd; padding: .5em 1em; margin-bottom: 1em; }
+pre { background: #f5f5f5; padding: .5em; overflow-x: auto; }
+nav.pages a { margin-right: 1em; }
+</style>
+</head>
+<body>
+<header>
+<h2><a href="/explorer">Beyond explorer</a></h2>
+<form action="/explorer/search"><input name="q" size="50" placeholder="Block height, tx hash or address"> <button>Search</button></form>
+</header>
+<h1>{{.Title}}</h1>
+{{end}}
+
+{{define "footer"}}
+<footer><p><a href="/openapi.yaml">JSON API</a></p></footer>
+</body>
+</html>
+{{end}}
+
+{{define "orders"}}
+<table>
+<tr><th>Buyer</th><th>#</th><th>Seller</th><th>Initiated</th><th>Agreed price</th><th>Estimated kWh</th><th>Charged kWh</th><th>Amount</th></tr>
+{{range .}}<tr>
+<td class="hash"><a href="/explorer/account/{{.Buyer}}">{{.Buyer}}</a></td>
+<td>{{.Number}}</td>
+<td class="hash"><a href="/explorer/account/{{.Seller}}">{{.Seller}}</a></td>
+<td><a href="/explorer/tx/{{.InitTx}}">{{formatTime .InitTime}}</a></td>
+<td>{{.AgreedPrice}}</td>
+<td>{{.EstimatedCharge}}</td>
+{{if .FinalizeTx}}<td><a href="/explorer/tx/{{.FinalizeTx}}">{{.TotalCharge}}</a></td><td>{{.TotalAmount}}</td>
+{{else}}<td colspan="2">open</td>{{end}}
+</tr>{{end}}
+</table>
+{{end}}
+
+{{define "pages"}}<nav class="pages">
+{{with .Newer}}<a href="{{.}}">Newer</a>{{end}}
+{{with .Older}}<a href="{{.}}">Older</a>{{end}}
+</nav>{{end}}
+
+{{define "blocks"}}{{template "header" .}}
+<table>
+<tr><th>Height</th><th>Time</th><th>Txs</th><th>Hash</th></tr>
+{{range .Blocks.Blocks}}<tr>
+<td><a href="/explorer/block/{{.Header.Height}}">{{.Header.Height}}</a></td>
+<td>{{formatTime .Header.Time}}</td>
+<td>{{.Header.Num_txs}}</td>
+<td class="hash">{{.Block_id.Hash}}</td>
+</tr>{{end}}
+</table>
+{{template "pages" .}}
+{{template "footer" .}}{{end}}
+
+{{define "block"}}{{template "header" .}}
+{{with .Block.Block_meta}}<dl>
+<dt>Chain</dt><dd>{{.Header.Chain_id}}</dd>
+<dt>Time</dt><dd>{{formatTime .Header.Time}}</dd>
+<dt>Hash</dt><dd class="hash">{{.Block_id.Hash}}</dd>
+<dt>App hash</dt><dd class="hash">{{.Header.App_hash}}</dd>
+<dt>Txs</dt><dd>{{.Header.Num_txs}} of {{.Header.Total_txs}}</dd>
+</dl>{{end}}
+<nav class="pages">
+{{if gt .Height 1}}<a href="/explorer/block/{{add .Height -1}}">Previous block</a>{{end}}
+<a href="/explorer/block/{{add .Height 1}}">Next block</a>
+</nav>
+<h2>Txs</h2>
+<table>
+<tr><th>Hash</th><th>Msgs</th><th>Result</th><th>Memo</th></tr>
+{{range .Block.Block.Data.Txs}}<tr>
+<td class="hash"><a href="/explorer/tx/{{.Hash}}">{{.Hash}}</a></td>
+<td>{{range $i, $msg := .Msgs}}{{if $i}}, {{end}}{{$msg.Type}}{{end}}</td>
+<td>{{template "result" .}}</td>
+<td>{{.Memo}}</td>
+</tr>{{else}}<tr><td colspan="4">No txs</td></tr>{{end}}
+</table>
+{{template "footer" .}}{{end}}
+
+{{define "result"}}{{if .Error}}<span class="failed">not decoded: {{.Error}}</span>{{else if .Code}}<span class="failed">failed ({{.Code}}) {{.Log}}</span>{{else}}ok{{end}}{{end}}
+
+{{define "tx"}}{{template "header" .}}
+{{with .Tx}}<dl>
+<dt>Hash</dt><dd class="hash">{{.Hash}}</dd>
+<dt>Block</dt><dd><a href="/explorer/block/{{.Height}}">{{.Height}}</a>, tx {{.Index}}</dd>
+<dt>Result</dt><dd>{{template "result" .}}</dd>
+<dt>Fee</dt><dd>{{.Fee.Amount}} for {{.Fee.Gas}} gas</dd>
+{{if .Memo}}<dt>Memo</dt><dd>{{.Memo}}</dd>{{end}}
+</dl>
+<h2>Msgs</h2>
+{{range .Msgs}}<div class="msg">
+<h3>{{.Type}}</h3>
+{{with initOrder .}}<dl>
+<dt>Buyer</dt><dd class="hash"><a href="/explorer/account/{{.Initiator}}">{{.Initiator}}</a></dd>
+<dt>Station</dt><dd class="hash"><a href="/explorer/account/{{.Recipient}}">{{.Recipient}}</a></dd>
+<dt>Agreed price</dt><dd>{{.AgreedPrice}}</dd>
+<dt>Estimated charge</dt><dd>{{.EstimatedCharge}} kWh</dd>
+</dl>{{else}}{{with finalizeOrder .}}<dl>
+<dt>Buyer</dt><dd class="hash"><a href="/explorer/account/{{.Initiator}}">{{.Initiator}}</a></dd>
+<dt>Station</dt><dd class="hash"><a href="/explorer/account/{{.Recipient}}">{{.Recipient}}</a></dd>
+<dt>Total charge</dt><dd>{{.TotalCharge}} kWh</dd>
+<dt>Total amount</dt><dd>{{.TotalAmount}}</dd>
+</dl>{{else}}{{with send .}}<table>
+<tr><th>From</th><th>To</th><th>Coins</th></tr>
+{{range .Inputs}}<tr><td class="hash"><a href="/explorer/account/{{.Address}}">{{.Address}}</a></td><td></td><td>{{.Coins}}</td></tr>{{end}}
+{{range .Outputs}}<tr><td></td><td class="hash"><a href="/explorer/account/{{.Address}}">{{.Address}}</a></td><td>{{.Coins}}</td></tr>{{end}}
+</table>{{else}}<pre>{{msgJSON .}}</pre>{{end}}{{end}}{{end}}
+</div>{{end}}
+<h2>Tags</h2>
+<table>
+{{range .Tags}}<tr><th>{{.Key}}</th><td>{{.Value}}</td></tr>{{end}}
+</table>
+{{end}}
+{{if .Orders}}<h2>Orders</h2>{{template "orders" .Orders}}{{end}}
+{{template "footer" .}}{{end}}
+
+{{define "account"}}{{template "header" .}}
+{{with .Account}}<dl>
+<dt>Address</dt><dd class="hash">{{.Address}}</dd>
+<dt>Name</dt><dd>{{.Name}}</dd>
+<dt>MAC address</dt><dd>{{.MacAddress}}</dd>
+<dt>Electricity price</dt><dd>{{.Price}}</dd>
+<dt>Coins</dt><dd>{{.Coins}}</dd>
+<dt>Account number</dt><dd>{{.AccountNumber}}, sequence {{.Sequence}}</dd>
+{{if .PubKey}}<dt>Public key</dt><dd class="hash">{{.PubKey}}</dd>{{end}}
+{{with .HsmInfo}}{{if .RomID}}<dt>Secure element</dt><dd class="hash">ROM ID {{hex .RomID}}, manufacturer ID {{hex .ManID}}, page {{.Page}}{{if .Verified}}, verified by {{.Authority}}{{end}}</dd>{{end}}{{end}}
+</dl>{{end}}
+{{with .Activity}}<p>{{.TxCount}} txs between <a href="/explorer/block/{{.FirstHeight}}">{{formatTime .FirstTime}}</a> and <a href="/explorer/block/{{.LastHeight}}">{{formatTime .LastTime}}</a>.</p>{{end}}
+<h2>Orders</h2>
+{{if .IndexDisabled}}<p>The order index is not enabled.</p>
+{{else}}{{template "orders" .Orders}}
+{{template "pages" .}}{{end}}
+{{template "footer" .}}{{end}}
+
+{{define "error"}}{{template "header" .}}
+<p>{{.Message}}</p>
+{{template "footer" .}}{{end}}
+`
+
+var pages = template.Must(template.New("pages").Funcs(template.FuncMap{
+	"formatTime": func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04:05 UTC") },
+	"hex":        func(bz []byte) string { return strings.ToUpper(hex.EncodeToString(bz)) },
+	"add":        func(a interface{}, b int) int64 { return toInt64(a) + int64(b) },
+	"initOrder": func(msg Msg) *InitOrder {
+		if v, ok := msg.Value.(InitOrder); ok {
+			return &v
+		}
+		return nil
+	},
+	"finalizeOrder": func(msg Msg) *FinalizeOrder {
+		if v, ok := msg.Value.(FinalizeOrder); ok {
+			return &v
+		}
+		return nil
+	},
+	"send": func(msg Msg) *Send {
+		if v, ok := msg.Value.(Send); ok {
+			return &v
+		}
+		return nil
+	},
+	"msgJSON": func(msg Msg) string {
+		bz, _ := json.MarshalIndent(msg.Value, "", "  ")
+		return string(bz)
+	},
+}).Parse(pageTemplates))
+
+// toInt64 converts the numbers and height strings the templates add to
+func toInt64(v interface{}) int64 {
+	switch v := v.(type) {
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	}
+	return 0
+}
+
+// renderPage writes an HTML page with the given status
+func renderPage(w http.ResponseWriter, status int, name string, data interface{}) {
+	var buf bytes.Buffer
+	if err := pages.ExecuteTemplate(&buf, name, data); err != nil {
+		log.Printf("rendering %s: %v", name, err)
+		status = http.StatusInternalServerError
+		buf.Reset()
+		pages.ExecuteTemplate(&buf, "error", errorPage{Title: "Error", Message: "The page could not be rendered."})
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+type errorPage struct {
+	Title   string
+	Message string
+}
+
+func renderError(w http.ResponseWriter, status int, msg string) {
+	renderPage(w, status, "error", errorPage{Title: http.StatusText(status), Message: msg})
+}
+
+func renderUpstreamError(w http.ResponseWriter, err error) {
+	renderError(w, errorStatus(err), err.Error())
+}
+
+// BlocksPage lists the latest blocks, or the ones up to ?to=
+func (s *Server) BlocksPage(w http.ResponseWriter, r *http.Request) {
+	var to int64
+	if v := r.URL.Query().Get("to"); v != "" {
+		var err error
+		if to, err = strconv.ParseInt(v, 10, 64); err != nil || to < 1 {
+			renderError(w, http.StatusBadRequest, fmt.Sprintf("Invalid height %q.", v))
+			return
+		}
+	}
+	from, to, err := blockRange(0, to)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	list, err := s.blocks(from, to)
+	if err != nil {
+		renderUpstreamError(w, err)
+		return
+	}
+	data := struct {
+		Title        string
+		Blocks       BlockList
+		Newer, Older string
+	}{Title: "Latest blocks", Blocks: list}
+	if list.To > 0 && list.To < list.LastHeight {
+		data.Newer = fmt.Sprintf("/explorer?to=%d", list.To+MaxBlocksPage)
+	}
+	if list.From > 1 {
+		data.Older = fmt.Sprintf("/explorer?to=%d", list.From-1)
+	}
+	renderPage(w, http.StatusOK, "blocks", data)
+}
+
+// BlockPage shows a block and its txs
+func (s *Server) BlockPage(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["height"]
+	height, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || height < 1 {
+		renderError(w, http.StatusBadRequest, fmt.Sprintf("Invalid block height %q.", id))
+		return
+	}
+	_, block, err := s.cachedBlock(height)
+	if err != nil {
+		renderUpstreamError(w, err)
+		return
+	}
+	renderPage(w, http.StatusOK, "block", struct {
+		Title  string
+		Height int64
+		Block  Result
+	}{fmt.Sprintf("Block %d", height), height, block})
+}
+
+// TxPage shows a tx with its decoded msgs and the orders it initiated or
+// finalized
+func (s *Server) TxPage(w http.ResponseWriter, r *http.Request) {
+	hash := mux.Vars(r)["hash"]
+	if !txHashRegexp.MatchString(hash) {
+		renderError(w, http.StatusBadRequest, fmt.Sprintf("Invalid tx hash %q.", hash))
+		return
+	}
+	tx, err := s.tx(hash)
+	if err != nil {
+		renderUpstreamError(w, err)
+		return
+	}
+	var orders []Order
+	if s.index != nil {
+		if orders, err = s.index.Orders(OrderFilter{Tx: tx.Hash, Page: Page{Limit: MaxPageLimit}}); err != nil {
+			renderError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	renderPage(w, http.StatusOK, "tx", struct {
+		Title  string
+		Tx     Tx
+		Orders []Order
+	}{"Tx", tx, orders})
+}
+
+// AccountPage shows an account and, with the order index, its activity and
+// the orders it bought or sold
+func (s *Server) AccountPage(w http.ResponseWriter, r *http.Request) {
+	address := mux.Vars(r)["address"]
+	addr, err := sdk.AccAddressFromBech32(address)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, fmt.Sprintf("Invalid address %q.", address))
+		return
+	}
+	var offset int64
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if offset, err = strconv.ParseInt(v, 10, 64); err != nil || offset < 0 {
+			renderError(w, http.StatusBadRequest, fmt.Sprintf("Invalid offset %q.", v))
+			return
+		}
+	}
+	account, err := s.account(addr)
+	if err != nil {
+		renderUpstreamError(w, err)
+		return
+	}
+
+	data := struct {
+		Title         string
+		Account       Account
+		IndexDisabled bool
+		Activity      *IndexedAccount
+		Orders        []Order
+		Newer, Older  string
+	}{Title: "Account", Account: account, IndexDisabled: s.index == nil}
+	if account.Name != "" {
+		data.Title = account.Name
+	}
+	if s.index != nil {
+		acc, found, err := s.index.Account(address)
+		if err != nil {
+			renderError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if found {
+			data.Activity = &acc
+		}
+		// one more order tells if there is a next page
+		f := OrderFilter{Party: address, Page: Page{Limit: OrdersPerPage + 1, Offset: int(offset)}}
+		if data.Orders, err = s.index.Orders(f); err != nil {
+			renderError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if len(data.Orders) > OrdersPerPage {
+			data.Orders = data.Orders[:OrdersPerPage]
+			data.Older = fmt.Sprintf("?offset=%d", offset+OrdersPerPage)
+		}
+		if offset > 0 {
+			newer := offset - OrdersPerPage
+			if newer < 0 {
+				newer = 0
+			}
+			data.Newer = fmt.Sprintf("?offset=%d", newer)
+		}
+	}
+	renderPage(w, http.StatusOK, "account", data)
+}
+
+// SearchPage redirects to the block, tx or account page of a query
+func (s *Server) SearchPage(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	switch {
+	case isHeight(q):
+		http.Redirect(w, r, "/explorer/block/"+q, http.StatusFound)
+	case txHashRegexp.MatchString(q):
+		http.Redirect(w, r, "/explorer/tx/"+strings.TrimPrefix(strings.TrimPrefix(q, "0x"), "0X"), http.StatusFound)
+	default:
+		if _, err := sdk.AccAddressFromBech32(q); err != nil {
+			renderError(w, http.StatusBadRequest, fmt.Sprintf("%q is not a block height, tx hash or account address.", q))
+			return
+		}
+		http.Redirect(w, r, "/explorer/account/"+q, http.StatusFound)
+	}
+}
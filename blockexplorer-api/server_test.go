@@ -0,0 +1,1093 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vincepg13/bp-sdk/beyond/app"
+	"github.com/vincepg13/bp-sdk/beyond/types"
+	mob "github.com/vincepg13/bp-sdk/beyond/x/mobility"
+	"github.com/vincepg13/bp-sdk/blockexplorer-api/client"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+func TestDecodeBlockTxs(t *testing.T) {
+	cdc := app.MakeCodec()
+	buyer := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	station := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	coins := sdk.Coins{sdk.NewInt64Coin("byndcoin", 25)}
+	fee := auth.NewStdFee(200000, sdk.NewInt64Coin("byndcoin", 1))
+
+	order := auth.NewStdTx([]sdk.Msg{mob.NewMsgInitOrder(buyer, station, 10, 50)}, fee, nil, "charge")
+	send := auth.NewStdTx([]sdk.Msg{bank.NewMsgSend(
+		[]bank.Input{bank.NewInput(buyer, coins)},
+		[]bank.Output{bank.NewOutput(station, coins)},
+	)}, fee, nil, "")
+	var txs []fakeTx
+	for _, tx := range []auth.StdTx{order, send} {
+		bz, err := cdc.MarshalBinaryLengthPrefixed(tx)
+		require.Nil(t, err)
+		txs = append(txs, fakeTx{tx: bz})
+	}
+	txs[0].result = tagged("action", "initOrder")
+	txs[1].result = deliverTx{Code: 10, Log: "insufficient funds", GasWanted: 200000}
+	txs = append(txs, fakeTx{tx: []byte("not a tx"), result: deliverTx{Code: 2}})
+
+	node := newFakeNode(t, fakeChain{blocks: map[int64][]fakeTx{2: txs}})
+	defer node.Close()
+
+	nodes, err := NewNodePool([]string{node.URL}, time.Second)
+	require.Nil(t, err)
+	rec := httptest.NewRecorder()
+	NewServer(nodes).Router().ServeHTTP(rec, httptest.NewRequest("GET", "/block/2", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var res struct {
+		Result struct {
+			Block struct {
+				Data struct {
+					Txs []struct {
+						Hash      string
+						Code      uint32
+						GasWanted int64
+						Log       string
+						Memo      string
+						Fee       Fee
+						FeePayer  string
+						Tags      []Tag
+						Error     string
+						Msgs      []struct {
+							Type  string
+							Value json.RawMessage
+						}
+					}
+				}
+			}
+		}
+	}
+	require.Nil(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	decoded := res.Result.Block.Data.Txs
+	require.Len(t, decoded, 3)
+
+	// initOrder with its result tags
+	require.Len(t, decoded[0].Hash, 40)
+	require.Equal(t, "charge", decoded[0].Memo)
+	require.Equal(t, int64(200000), decoded[0].Fee.Gas)
+	require.Equal(t, buyer.String(), decoded[0].FeePayer)
+	require.Equal(t, []Tag{{Key: "action", Value: "initOrder"}}, decoded[0].Tags)
+	require.Equal(t, MsgTypeInitOrder, decoded[0].Msgs[0].Type)
+	var initOrder InitOrder
+	require.Nil(t, json.Unmarshal(decoded[0].Msgs[0].Value, &initOrder))
+	require.Equal(t, InitOrder{Initiator: buyer.String(), Recipient: station.String(), AgreedPrice: 10, EstimatedCharge: 50}, initOrder)
+
+	// failed send keeps its code and log
+	require.Equal(t, uint32(10), decoded[1].Code)
+	require.Equal(t, "insufficient funds", decoded[1].Log)
+	require.Equal(t, int64(200000), decoded[1].GasWanted)
+	require.Equal(t, MsgTypeSend, decoded[1].Msgs[0].Type)
+	var sendMsg Send
+	require.Nil(t, json.Unmarshal(decoded[1].Msgs[0].Value, &sendMsg))
+	require.Equal(t, station.String(), sendMsg.Outputs[0].Address)
+	require.Equal(t, "25", sendMsg.Outputs[0].Coins[0].Amount.String())
+
+	// undecodable tx is returned with its hash
+	require.NotEmpty(t, decoded[2].Error)
+	require.Len(t, decoded[2].Hash, 40)
+}
+
+func TestAccountAndSearch(t *testing.T) {
+	addr := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	base := auth.NewBaseAccountWithAddress(addr)
+	base.Coins = sdk.Coins{sdk.NewInt64Coin("byndcoin", 100)}
+	acc := types.NewAppAccount("station", "00:11:22:33:44:55", "0.25", types.HsmInfo{}, base)
+	node := newFakeNode(t, fakeChain{latest: 42, accounts: []auth.Account{acc}})
+	defer node.Close()
+
+	nodes, err := NewNodePool([]string{node.URL}, time.Second)
+	require.Nil(t, err)
+	router := NewServer(nodes).Router()
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	rec := get("/account/" + addr.String())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var account Account
+	require.Nil(t, json.Unmarshal(rec.Body.Bytes(), &account))
+	require.Equal(t, "station", account.Name)
+	require.Equal(t, "00:11:22:33:44:55", account.MacAddress)
+	require.Equal(t, "0.25", account.Price)
+	require.Equal(t, "100", account.Coins[0].Amount.String())
+
+	other := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	require.Equal(t, http.StatusNotFound, get("/account/"+other.String()).Code)
+	require.Equal(t, http.StatusBadRequest, get("/account/nope").Code)
+
+	// search detects the kind of query
+	rec = get("/search?q=" + addr.String())
+	require.Equal(t, http.StatusOK, rec.Code)
+	var result struct {
+		Type  string
+		Value Account
+	}
+	require.Nil(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	require.Equal(t, SearchAccount, result.Type)
+	require.Equal(t, addr.String(), result.Value.Address)
+	require.Equal(t, http.StatusNotFound, get("/search?q=0x"+strings.Repeat("AB", 20)).Code)
+	require.Equal(t, http.StatusBadRequest, get("/search?q=what").Code)
+	require.Equal(t, http.StatusBadRequest, get("/search").Code)
+
+	// pagination of block headers
+	rec = get("/blocks?from=11&to=30")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "11", node.Params("blockchain").Get("minHeight"))
+	require.Equal(t, "30", node.Params("blockchain").Get("maxHeight"))
+	var list BlockList
+	require.Nil(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Equal(t, BlockList{LastHeight: 42, From: 11, To: 30, Blocks: list.Blocks}, list)
+	require.Len(t, list.Blocks, 20)
+	require.Equal(t, http.StatusOK, get("/blocks?from=11").Code)
+	require.Equal(t, http.StatusBadRequest, get("/blocks?from=1&to=30").Code)
+	require.Equal(t, http.StatusBadRequest, get("/blocks?from=30&to=11").Code)
+	require.Equal(t, http.StatusBadRequest, get("/blocks?from=x").Code)
+}
+
+func TestOrderIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "explorer")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	store, err := OpenIndexStore(filepath.Join(dir, "index.db"))
+	require.Nil(t, err)
+
+	buyer := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address()).String()
+	station := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address()).String()
+	coins := sdk.Coins{sdk.NewInt64Coin("byndcoin", 20)}
+	block := func(height int64, txs ...Tx) Result {
+		var res Result
+		res.Block_meta.Header.Height = strconv.FormatInt(height, 10)
+		res.Block_meta.Header.Time = time.Unix(1540000000+height, 0)
+		res.Block.Data.Txs = txs
+		return res
+	}
+
+	initOrder := Tx{Hash: "INIT", Msgs: []Msg{{Type: MsgTypeInitOrder, Value: InitOrder{
+		Initiator: buyer, Recipient: station, AgreedPrice: 2, EstimatedCharge: 12,
+	}}}, Tags: []Tag{{Key: "action", Value: "initOrder"}, {Key: "orderNumber", Value: "1"}}}
+	failed := Tx{Hash: "FAILED", Code: 10, Msgs: initOrder.Msgs, Tags: []Tag{}}
+	finalize := Tx{Hash: "FINAL", Msgs: []Msg{
+		{Type: MsgTypeFinalizeOrder, Value: FinalizeOrder{Initiator: buyer, Recipient: station, TotalAmount: 20, TotalCharge: 10}},
+		{Type: MsgTypeSend, Value: Send{
+			Inputs:  []Transfer{{Address: buyer, Coins: coins}},
+			Outputs: []Transfer{{Address: station, Coins: coins}},
+		}},
+	}, Tags: []Tag{{Key: "action", Value: "finalizeOrder"}, {Key: "orderNumber", Value: "1"}}}
+
+	require.Nil(t, store.ApplyBlock(block(1, initOrder, failed)))
+	// blocks are applied in order, once
+	require.NotNil(t, store.ApplyBlock(block(1)))
+	require.NotNil(t, store.ApplyBlock(block(3)))
+	require.Nil(t, store.ApplyBlock(block(2, finalize)))
+	require.Nil(t, store.Close())
+
+	// the index survives a restart
+	store, err = OpenIndexStore(filepath.Join(dir, "index.db"))
+	require.Nil(t, err)
+	defer store.Close()
+	checkpoint, err := store.Checkpoint()
+	require.Nil(t, err)
+	require.Equal(t, int64(2), checkpoint)
+
+	router := NewServer(nil).WithIndex(store).Router()
+	get := func(path string, v interface{}) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code == http.StatusOK {
+			require.Nil(t, json.Unmarshal(rec.Body.Bytes(), v))
+		}
+		return rec.Code
+	}
+
+	var orders []Order
+	require.Equal(t, http.StatusOK, get("/orders?seller="+station+"&status=finalized", &orders))
+	require.Len(t, orders, 1)
+	require.Equal(t, uint64(12), orders[0].EstimatedCharge)
+	require.Equal(t, uint64(10), *orders[0].TotalCharge)
+	require.Equal(t, "FINAL", *orders[0].FinalizeTx)
+	require.Equal(t, http.StatusOK, get("/orders?status=open", &orders))
+	require.Len(t, orders, 0)
+	require.Equal(t, http.StatusBadRequest, get("/orders?status=closed", &orders))
+	require.Equal(t, http.StatusBadRequest, get("/orders?limit=1000", &orders))
+
+	var order Order
+	require.Equal(t, http.StatusOK, get("/orders/"+buyer+"/1", &order))
+	require.Equal(t, int64(1), order.InitHeight)
+	require.Equal(t, http.StatusNotFound, get("/orders/"+buyer+"/2", &order))
+
+	var payments []Payment
+	require.Equal(t, http.StatusOK, get("/payments?address="+station, &payments))
+	require.Len(t, payments, 1)
+	require.Equal(t, int64(20), payments[0].Amount)
+	require.Equal(t, uint64(1), *payments[0].OrderNumber)
+
+	var activity IndexedAccount
+	require.Equal(t, http.StatusOK, get("/activity/"+buyer, &activity))
+	require.Equal(t, int64(2), activity.TxCount)
+	require.Equal(t, int64(1), activity.FirstHeight)
+	require.Equal(t, int64(2), activity.LastHeight)
+}
+
+func TestAnalytics(t *testing.T) {
+	dir, err := ioutil.TempDir("", "explorer")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	store, err := OpenIndexStore(filepath.Join(dir, "index.db"))
+	require.Nil(t, err)
+	defer store.Close()
+
+	buyer := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address()).String()
+	other := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address()).String()
+	station := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address()).String()
+	station2 := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address()).String()
+	if station2 < station {
+		// reports of a bucket are ordered by station
+		station, station2 = station2, station
+	}
+
+	// order applies an order initiated ten minutes before it is finalized
+	var height int64
+	order := func(buyer, seller string, number, estimated, charge uint64, paid sdk.Coin, finalized time.Time) {
+		tags := func(action string) []Tag {
+			return []Tag{{Key: "action", Value: action}, {Key: "orderNumber", Value: strconv.FormatUint(number, 10)}}
+		}
+		coins := sdk.Coins{paid}
+		for i, tx := range []Tx{
+			{Msgs: []Msg{{Type: MsgTypeInitOrder, Value: InitOrder{
+				Initiator: buyer, Recipient: seller, AgreedPrice: 2, EstimatedCharge: estimated,
+			}}}, Tags: tags("initOrder")},
+			{Msgs: []Msg{
+				{Type: MsgTypeFinalizeOrder, Value: FinalizeOrder{
+					Initiator: buyer, Recipient: seller, TotalAmount: uint64(paid.Amount.Int64()), TotalCharge: charge,
+				}},
+				{Type: MsgTypeSend, Value: Send{
+					Inputs:  []Transfer{{Address: buyer, Coins: coins}},
+					Outputs: []Transfer{{Address: seller, Coins: coins}},
+				}},
+			}, Tags: tags("finalizeOrder")},
+		} {
+			height++
+			tx.Hash = fmt.Sprintf("TX%d", height)
+			var block Result
+			block.Block_meta.Header.Height = strconv.FormatInt(height, 10)
+			block.Block_meta.Header.Time = finalized.Add(time.Duration(i-1) * 10 * time.Minute)
+			block.Block.Data.Txs = []Tx{tx}
+			require.Nil(t, store.ApplyBlock(block))
+		}
+	}
+	byndcoin := func(amount int64) sdk.Coin { return sdk.NewInt64Coin("byndcoin", amount) }
+	date := func(day, hour, min, sec int) time.Time { return time.Date(2018, 10, day, hour, min, sec, 0, time.UTC) }
+
+	// the last second of Sunday the 21st and the first of Monday the 22nd
+	// fall in different weeks
+	order(buyer, station, 1, 12, 10, byndcoin(20), date(21, 23, 59, 59))
+	// nothing was estimated and the order is paid in another denom
+	order(other, station, 1, 0, 6, sdk.NewInt64Coin("stake", 9), date(22, 0, 0, 0))
+	order(buyer, station2, 2, 4, 4, byndcoin(8), date(22, 1, 10, 0))
+	order(other, station, 2, 5, 5, byndcoin(10), date(23, 9, 5, 0))
+
+	router := NewServer(nil).WithIndex(store).Router()
+	get := func(path string, v interface{}) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code == http.StatusOK {
+			require.Nil(t, json.Unmarshal(rec.Body.Bytes(), v))
+		}
+		return rec.Code
+	}
+	report := func(station string, bucket time.Time, orders, energy, revenue, estimated int64) EnergyReport {
+		r := EnergyReport{
+			Station:         station,
+			Bucket:          bucket,
+			Orders:          orders,
+			Energy:          energy,
+			AverageSession:  float64(energy) / float64(orders),
+			Revenue:         revenue,
+			EstimatedCharge: estimated,
+		}
+		if estimated > 0 {
+			r.ChargeAccuracy = float64(energy) / float64(estimated)
+		}
+		return r
+	}
+
+	var reports []EnergyReport
+	require.Equal(t, http.StatusOK, get("/analytics/energy?bucket=month", &reports))
+	require.Equal(t, []EnergyReport{
+		report(station, date(1, 0, 0, 0), 3, 21, 30, 17),
+		report(station2, date(1, 0, 0, 0), 1, 4, 8, 4),
+	}, reports)
+
+	// weeks start on Monday
+	require.Equal(t, http.StatusOK, get("/analytics/energy?bucket=week", &reports))
+	require.Equal(t, []EnergyReport{
+		report(station, date(15, 0, 0, 0), 1, 10, 20, 12),
+		report(station, date(22, 0, 0, 0), 2, 11, 10, 5),
+		report(station2, date(22, 0, 0, 0), 1, 4, 8, 4),
+	}, reports)
+
+	// the order without estimate has no charge accuracy
+	require.Equal(t, http.StatusOK, get("/analytics/energy", &reports))
+	require.Equal(t, []EnergyReport{
+		report(station, date(21, 0, 0, 0), 1, 10, 20, 12),
+		report(station, date(22, 0, 0, 0), 1, 6, 0, 0),
+		report(station2, date(22, 0, 0, 0), 1, 4, 8, 4),
+		report(station, date(23, 0, 0, 0), 1, 5, 10, 5),
+	}, reports)
+	require.Equal(t, float64(0), reports[1].ChargeAccuracy)
+
+	require.Equal(t, http.StatusOK, get("/analytics/energy?bucket=hour&station="+station, &reports))
+	require.Equal(t, []EnergyReport{
+		report(station, date(21, 23, 0, 0), 1, 10, 20, 12),
+		report(station, date(22, 0, 0, 0), 1, 6, 0, 0),
+		report(station, date(23, 9, 0, 0), 1, 5, 10, 5),
+	}, reports)
+
+	// revenue is counted in the requested denom only
+	require.Equal(t, http.StatusOK, get("/analytics/energy?bucket=month&denom=stake", &reports))
+	require.Equal(t, []EnergyReport{
+		report(station, date(1, 0, 0, 0), 3, 21, 9, 17),
+		report(station2, date(1, 0, 0, 0), 1, 4, 0, 4),
+	}, reports)
+
+	// since is included and until excluded
+	require.Equal(t, http.StatusOK, get("/analytics/energy?bucket=month&since=2018-10-22&until=2018-10-23", &reports))
+	require.Equal(t, []EnergyReport{
+		report(station, date(1, 0, 0, 0), 1, 6, 0, 0),
+		report(station2, date(1, 0, 0, 0), 1, 4, 8, 4),
+	}, reports)
+	require.Equal(t, http.StatusOK, get("/analytics/energy?since=2018-11-01", &reports))
+	require.Len(t, reports, 0)
+	require.Equal(t, http.StatusBadRequest, get("/analytics/energy?bucket=year", &reports))
+	require.Equal(t, http.StatusBadRequest, get("/analytics/energy?since=2018-11-01&until=2018-10-01", &reports))
+
+	var buyers []BuyerReport
+	require.Equal(t, http.StatusOK, get("/analytics/top-buyers?limit=5", &buyers))
+	require.Equal(t, []BuyerReport{
+		{Buyer: buyer, Orders: 2, Energy: 14, Spent: 28},
+		{Buyer: other, Orders: 2, Energy: 11, Spent: 10},
+	}, buyers)
+	require.Equal(t, http.StatusOK, get("/analytics/top-buyers?denom=stake&since=2018-10-22", &buyers))
+	require.Equal(t, []BuyerReport{
+		{Buyer: other, Orders: 2, Energy: 11, Spent: 9},
+		{Buyer: buyer, Orders: 1, Energy: 4, Spent: 0},
+	}, buyers)
+	require.Equal(t, http.StatusOK, get("/analytics/top-buyers?limit=1&station="+station2, &buyers))
+	require.Equal(t, []BuyerReport{{Buyer: buyer, Orders: 1, Energy: 4, Spent: 8}}, buyers)
+}
+
+func TestBalances(t *testing.T) {
+	dir, err := ioutil.TempDir("", "explorer")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	store, err := OpenIndexStore(filepath.Join(dir, "index.db"))
+	require.Nil(t, err)
+	defer store.Close()
+
+	buyer := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address()).String()
+	station := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address()).String()
+	other := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address()).String()
+	byndcoin := func(amount int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("byndcoin", amount)} }
+	send := func(hash, from, to string, amount int64) Tx {
+		return Tx{Hash: hash, FeePayer: from, Fee: Fee{Amount: byndcoin(1)}, Msgs: []Msg{{Type: MsgTypeSend, Value: Send{
+			Inputs:  []Transfer{{Address: from, Coins: byndcoin(amount)}},
+			Outputs: []Transfer{{Address: to, Coins: byndcoin(amount)}},
+		}}}}
+	}
+	block := func(height int64, txs ...Tx) Result {
+		var res Result
+		res.Block_meta.Header.Height = strconv.FormatInt(height, 10)
+		res.Block_meta.Header.Time = time.Unix(1540000000+height, 0)
+		res.Block.Data.Txs = txs
+		return res
+	}
+
+	genesis := []Transfer{{Address: buyer, Coins: byndcoin(100)}, {Address: station, Coins: byndcoin(10)}}
+	// the genesis can be applied again until a block is indexed
+	require.Nil(t, store.ApplyGenesis(time.Unix(1540000000, 0), genesis))
+	require.Nil(t, store.ApplyGenesis(time.Unix(1540000000, 0), genesis))
+	// the msgs of FAILED failed after the fee was deducted, the ante handler
+	// rejected REJECTED and UNDECODED is not a tx of the app
+	failed := send("FAILED", buyer, other, 50)
+	failed.Code, failed.GasWanted = 0x1000a, 200000
+	rejected := send("REJECTED", buyer, other, 50)
+	rejected.Code = 0x10005
+	undecoded := Tx{Hash: "UNDECODED", Code: 0x10002, GasWanted: 200000, Error: "tx parse error"}
+	require.Nil(t, store.ApplyBlock(block(1, send("PAY", buyer, station, 20), failed, rejected, undecoded)))
+	require.Nil(t, store.ApplyBlock(block(2, send("FORWARD", station, other, 5))))
+	require.NotNil(t, store.ApplyGenesis(time.Unix(1540000000, 0), genesis))
+	from, err := store.BalancesFrom()
+	require.Nil(t, err)
+	require.Equal(t, int64(0), from)
+
+	router := NewServer(nil).WithIndex(store).Router()
+	get := func(path string, v interface{}) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code == http.StatusOK {
+			require.Nil(t, json.Unmarshal(rec.Body.Bytes(), v))
+		}
+		return rec.Code
+	}
+
+	var changes []BalanceChange
+	require.Equal(t, http.StatusOK, get("/balances/"+buyer+"/history?denom=byndcoin", &changes))
+	require.Len(t, changes, 4)
+	require.Equal(t, BalanceFee, changes[0].Kind)
+	require.Equal(t, int64(-1), changes[0].Delta)
+	require.Equal(t, int64(78), changes[0].Balance)
+	require.Equal(t, "FAILED", *changes[0].Tx)
+	require.Equal(t, BalanceTransfer, changes[1].Kind)
+	require.Equal(t, int64(-20), changes[1].Delta)
+	require.Equal(t, int64(79), changes[1].Balance)
+	require.Equal(t, "PAY", *changes[1].Tx)
+	require.Equal(t, BalanceFee, changes[2].Kind)
+	require.Equal(t, int64(99), changes[2].Balance)
+	require.Equal(t, BalanceGenesis, changes[3].Kind)
+	require.Nil(t, changes[3].Tx)
+	require.Equal(t, http.StatusOK, get("/balances/"+station+"/history?from=2&limit=1", &changes))
+	require.Len(t, changes, 1)
+	require.Equal(t, int64(24), changes[0].Balance)
+	require.Equal(t, http.StatusOK, get("/balances/"+station+"/history?until=2018-10-20T01:46:41Z", &changes))
+	require.Len(t, changes, 1)
+	require.Equal(t, int64(10), changes[0].Balance)
+	require.Equal(t, http.StatusBadRequest, get("/balances/station/history", &changes))
+
+	var list RichList
+	require.Equal(t, http.StatusOK, get("/richlist", &list))
+	require.Equal(t, int64(78+24+5), list.Supply)
+	require.Equal(t, []Holder{
+		{Rank: 1, Address: buyer, Amount: 78, Share: 78.0 / 107},
+		{Rank: 2, Address: station, Amount: 24, Share: 24.0 / 107},
+		{Rank: 3, Address: other, Amount: 5, Share: 5.0 / 107},
+	}, list.Holders)
+	require.Equal(t, http.StatusOK, get("/richlist?denom=byndcoin&limit=1&offset=1", &list))
+	require.Equal(t, []Holder{{Rank: 2, Address: station, Amount: 24, Share: 24.0 / 107}}, list.Holders)
+	require.Equal(t, http.StatusOK, get("/richlist?denom=other", &list))
+	require.Len(t, list.Holders, 0)
+
+	// without genesis, balances start at 0 before the first indexed block
+	later, err := OpenIndexStore(filepath.Join(dir, "later.db"))
+	require.Nil(t, err)
+	defer later.Close()
+	require.Nil(t, later.ApplyBlock(block(5)))
+	from, err = later.BalancesFrom()
+	require.Nil(t, err)
+	require.Equal(t, int64(4), from)
+}
+
+func TestFeedReconnects(t *testing.T) {
+	cdc := app.MakeCodec()
+	buyer := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	station := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	order, err := cdc.MarshalBinaryLengthPrefixed(auth.NewStdTx(
+		[]sdk.Msg{mob.NewMsgInitOrder(buyer, station, 2, 12)}, auth.NewStdFee(200000), nil, ""))
+	require.Nil(t, err)
+
+	// block 4 carries an initOrder
+	node := newFakeNode(t, fakeChain{latest: 2, blocks: map[int64][]fakeTx{
+		4: {{tx: order, result: tagged("action", "initOrder", "orderNumber", "1")}},
+	}})
+	defer node.Close()
+	pushes := make(chan int64)
+	drop := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+	node.Handle("/websocket", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.Nil(t, err)
+		defer conn.Close()
+		var subscribe map[string]interface{}
+		require.Nil(t, conn.ReadJSON(&subscribe))
+		require.Equal(t, "subscribe", subscribe["method"])
+		require.Nil(t, conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": "feed", "result": map[string]string{}}))
+		for {
+			select {
+			case h := <-pushes:
+				event := `{"jsonrpc":"2.0","id":"feed#event","result":{"query":"tm.event='NewBlock'","data":{"type":"tendermint/event/NewBlock","value":{"block":{"header":{"height":"` + strconv.FormatInt(h, 10) + `"}}}}}}`
+				require.Nil(t, conn.WriteMessage(websocket.TextMessage, []byte(event)))
+			case <-drop:
+				return
+			}
+		}
+	})
+
+	nodes, err := NewNodePool([]string{node.URL}, time.Second)
+	require.Nil(t, err)
+	server := NewServer(nodes)
+	explorer := httptest.NewServer(server.Router())
+	defer explorer.Close()
+
+	// filters are validated before the upgrade
+	res, err := http.Get(explorer.URL + "/ws?action=revoke")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(explorer.URL, "http")+"/ws?buyer="+buyer.String(), nil)
+	require.Nil(t, err)
+	defer client.Close()
+	client.SetReadDeadline(time.Now().Add(10 * time.Second))
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go server.feed.Run(stop)
+
+	// live blocks are pushed once the feed subscribed at height 2
+	for {
+		server.feed.mtx.Lock()
+		ready := server.feed.last == 2 && len(server.feed.clients) == 1
+		server.feed.mtx.Unlock()
+		if ready {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	node.SetLatest(3)
+	pushes <- 3
+	var event FeedEvent
+	require.Nil(t, client.ReadJSON(&event))
+	require.Equal(t, EventBlock, event.Type)
+	require.Equal(t, "3", event.Block.Block_meta.Header.Height)
+
+	// block 4 is committed while the feed is disconnected
+	node.SetLatest(4)
+	drop <- struct{}{}
+	require.Nil(t, client.ReadJSON(&event))
+	require.Equal(t, EventBlock, event.Type)
+	require.Equal(t, "4", event.Block.Block_meta.Header.Height)
+
+	var orderEvent struct {
+		Type  string
+		Order struct {
+			Action string
+			Buyer  string
+			Seller string
+			Number uint64
+			Height int64
+		}
+	}
+	require.Nil(t, client.ReadJSON(&orderEvent))
+	require.Equal(t, EventOrder, orderEvent.Type)
+	require.Equal(t, MsgTypeInitOrder, orderEvent.Order.Action)
+	require.Equal(t, buyer.String(), orderEvent.Order.Buyer)
+	require.Equal(t, station.String(), orderEvent.Order.Seller)
+	require.Equal(t, uint64(1), orderEvent.Order.Number)
+	require.Equal(t, int64(4), orderEvent.Order.Height)
+}
+
+func TestResponseCache(t *testing.T) {
+	node := newFakeNode(t, fakeChain{latest: 9})
+	defer node.Close()
+
+	nodes, err := NewNodePool([]string{node.URL}, time.Second)
+	require.Nil(t, err)
+	server := NewServer(nodes).WithCache(1<<20, 50*time.Millisecond)
+	router := server.Router()
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// committed blocks are fetched once
+	rec := get("/block/5", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	require.Equal(t, rec.Body.String(), get("/block/5", "").Body.String())
+	require.Equal(t, http.StatusNotModified, get("/block/5", etag).Code)
+	require.Equal(t, 1, node.Calls("block"))
+
+	// the status is cached briefly
+	get("/status", "")
+	get("/status", "")
+	require.Equal(t, 1, node.Calls("status"))
+	time.Sleep(60 * time.Millisecond)
+	get("/status", "")
+	require.Equal(t, 2, node.Calls("status"))
+
+	// uncached responses still answer conditional requests
+	rec = get("/health", "")
+	require.Equal(t, http.StatusNotModified, get("/health", rec.Header().Get("ETag")).Code)
+
+	// the cache stays within its size
+	server.WithCache(len(rec.Body.String()), time.Second)
+	router = server.Router()
+	get("/block/6", "")
+	get("/block/7", "")
+	n, _ := server.cache.Size()
+	require.True(t, n <= 1)
+}
+
+func TestGraphQL(t *testing.T) {
+	cdc := app.MakeCodec()
+	buyer := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	station := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	acc := types.NewAppAccount("station", "00:11:22:33:44:55", "0.25", types.HsmInfo{}, auth.NewBaseAccountWithAddress(station))
+	fee := auth.NewStdFee(200000, sdk.NewInt64Coin("byndcoin", 1))
+	order, err := cdc.MarshalBinaryLengthPrefixed(auth.NewStdTx([]sdk.Msg{mob.NewMsgInitOrder(buyer, station, 10, 50)}, fee, nil, ""))
+	require.Nil(t, err)
+	node := newFakeNode(t, fakeChain{
+		blocks:   map[int64][]fakeTx{1: {{tx: order, result: tagged("action", "initOrder", "orderNumber", "1")}}},
+		accounts: []auth.Account{acc},
+	})
+	defer node.Close()
+
+	nodes, err := NewNodePool([]string{node.URL}, time.Second)
+	require.Nil(t, err)
+	server := NewServer(nodes)
+	query := func(q string, v interface{}) []struct{ Message string } {
+		body, err := json.Marshal(GraphQLRequest{Query: q})
+		require.Nil(t, err)
+		rec := httptest.NewRecorder()
+		server.Router().ServeHTTP(rec, httptest.NewRequest("POST", "/graphql", strings.NewReader(string(body))))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var res struct {
+			Data   json.RawMessage
+			Errors []struct{ Message string }
+		}
+		require.Nil(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		require.Nil(t, json.Unmarshal(res.Data, v))
+		return res.Errors
+	}
+
+	// orders need the index
+	var res struct {
+		Block struct {
+			Height int
+			Txs    []struct {
+				Hash string
+				Msgs []struct {
+					InitOrder struct {
+						AgreedPrice string
+						Recipient   struct{ Name string }
+					}
+				}
+				Accounts []struct{ Address, Name string }
+				Orders   []struct {
+					Number        int
+					Buyer         string
+					SellerAccount struct{ MacAddress string }
+				}
+			}
+		}
+	}
+	q := `{ block(height: 1) { height txs { hash
+		msgs { initOrder { agreedPrice recipient { name } } }
+		accounts { address name }
+		orders { number buyer sellerAccount { macAddress } } } } }`
+	errs := query(q, &res)
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Message, errIndexDisabled.Error())
+
+	dir, err := ioutil.TempDir("", "explorer")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	store, err := OpenIndexStore(filepath.Join(dir, "index.db"))
+	require.Nil(t, err)
+	defer store.Close()
+	block, err := server.block(1)
+	require.Nil(t, err)
+	require.Nil(t, store.ApplyBlock(block))
+	server.WithIndex(store)
+
+	// the block, its accounts and orders in one query, each account is
+	// read once and the block comes from the response cache
+	node.ResetCalls()
+	require.Len(t, query(q, &res), 0)
+	require.Equal(t, 1, res.Block.Height)
+	require.Len(t, res.Block.Txs, 1)
+	tx := res.Block.Txs[0]
+	require.Equal(t, block.Block.Data.Txs[0].Hash, tx.Hash)
+	require.Equal(t, "10", tx.Msgs[0].InitOrder.AgreedPrice)
+	require.Equal(t, "station", tx.Msgs[0].InitOrder.Recipient.Name)
+	// the buyer has no account yet
+	require.Len(t, tx.Accounts, 1)
+	require.Equal(t, station.String(), tx.Accounts[0].Address)
+	require.Len(t, tx.Orders, 1)
+	require.Equal(t, 1, tx.Orders[0].Number)
+	require.Equal(t, buyer.String(), tx.Orders[0].Buyer)
+	require.Equal(t, "00:11:22:33:44:55", tx.Orders[0].SellerAccount.MacAddress)
+	require.Equal(t, 2, node.Calls("abci_query"))
+	require.Equal(t, 0, node.Calls("block"))
+	rec := httptest.NewRecorder()
+	server.Router().ServeHTTP(rec, httptest.NewRequest("GET", "/block/1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, 0, node.Calls("block"))
+
+	var orders struct {
+		Account struct {
+			Orders []struct{ Finalized bool }
+		}
+		Missing *struct{ Name string }
+	}
+	require.Len(t, query(`{ account(address: "`+station.String()+`") { orders(status: OPEN) { finalized } }
+		missing: tx(hash: "`+strings.Repeat("AB", 20)+`") { hash } }`, &orders), 0)
+	require.Len(t, orders.Account.Orders, 1)
+	require.Nil(t, orders.Missing)
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	node := newFakeNode(t, fakeChain{})
+	defer node.Close()
+	nodes, err := NewNodePool([]string{node.URL}, time.Second)
+	require.Nil(t, err)
+	router := NewServer(nodes).Router()
+	query := func(q string) (int, []struct{ Message string }) {
+		body, err := json.Marshal(GraphQLRequest{Query: q})
+		require.Nil(t, err)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("POST", "/graphql", bytes.NewReader(body)))
+		var res struct{ Errors []struct{ Message string } }
+		require.Nil(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return rec.Code, res.Errors
+	}
+
+	// the query of the README
+	code, errs := query(`{ blocks { height txs { hash
+		msgs { type initOrder { agreedPrice recipient { name price } } }
+		accounts { address name macAddress coins { denom amount } }
+		orders { number buyer finalized totalCharge } } } }`)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, errs, 0)
+
+	// nested pages multiply
+	code, _ = query(`{ blocks { txs { accounts { orders(limit: 500) { number } } } } }`)
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = query(`query { ...Heavy } fragment Heavy on Query { blocks { txs { accounts {
+		orders { number buyerAccount { coins { amount } } } } } } }`)
+	require.Equal(t, http.StatusBadRequest, code)
+
+	var aliases strings.Builder
+	for i := 1; i <= GraphQLMaxAliases+1; i++ {
+		fmt.Fprintf(&aliases, "b%d: block(height: %d) { hash } ", i, i)
+	}
+	code, _ = query("{ " + aliases.String() + "}")
+	require.Equal(t, http.StatusBadRequest, code)
+
+	// depth is checked when the query is validated
+	code, errs = query(`{ account(address: "byndaddr1") { orders(limit: 1) { buyerAccount { orders(limit: 1) {
+		buyerAccount { orders(limit: 1) { buyerAccount { orders(limit: 1) { buyerAccount { orders(limit: 1) {
+		number } } } } } } } } } } }`)
+	require.Equal(t, http.StatusOK, code)
+	require.NotEmpty(t, errs)
+	require.Contains(t, errs[0].Message, "depth")
+}
+
+// validatingTransport checks the requests and responses of the client
+// against the OpenAPI document
+type validatingTransport struct {
+	t      *testing.T
+	router *openapi3filter.Router
+}
+
+func (v validatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	route, params, err := v.router.FindRoute(req.Method, req.URL)
+	require.Nil(v.t, err, req.URL.String())
+	input := &openapi3filter.RequestValidationInput{Request: req, PathParams: params, Route: route}
+	require.Nil(v.t, openapi3filter.ValidateRequest(req.Context(), input), req.URL.String())
+
+	res, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	bz, err := ioutil.ReadAll(res.Body)
+	require.Nil(v.t, err)
+	res.Body.Close()
+	require.Nil(v.t, openapi3filter.ValidateResponse(req.Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 res.StatusCode,
+		Header:                 res.Header,
+		Body:                   ioutil.NopCloser(bytes.NewReader(bz)),
+	}), "%s: %s", req.URL, bz)
+	res.Body = ioutil.NopCloser(bytes.NewReader(bz))
+	return res, nil
+}
+
+func TestOpenAPI(t *testing.T) {
+	cdc := app.MakeCodec()
+	buyer := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	station := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	base := auth.NewBaseAccountWithAddress(station)
+	base.Coins = sdk.Coins{sdk.NewInt64Coin("byndcoin", 100)}
+	acc := types.NewAppAccount("station", "00:11:22:33:44:55", "0.25", types.HsmInfo{}, base)
+
+	coins := sdk.Coins{sdk.NewInt64Coin("byndcoin", 20)}
+	fee := auth.NewStdFee(200000, sdk.NewInt64Coin("byndcoin", 1))
+	encode := func(msgs ...sdk.Msg) []byte {
+		bz, err := cdc.MarshalBinaryLengthPrefixed(auth.NewStdTx(msgs, fee, nil, ""))
+		require.Nil(t, err)
+		return bz
+	}
+	initOrder := encode(mob.NewMsgInitOrder(buyer, station, 2, 12))
+	finalize := encode(mob.NewMsgFinalizeOrder(buyer, station, 20, 10), bank.NewMsgSend(
+		[]bank.Input{bank.NewInput(buyer, coins)},
+		[]bank.Output{bank.NewOutput(station, coins)},
+	))
+	node := newFakeNode(t, fakeChain{
+		name: "beyond",
+		blocks: map[int64][]fakeTx{
+			1: {{tx: initOrder, result: tagged("action", "initOrder", "orderNumber", "1")}},
+			2: {{tx: finalize, result: tagged("action", "finalizeOrder", "orderNumber", "1")}},
+		},
+		accounts: []auth.Account{acc},
+		genesis:  []*types.GenesisAccount{{Address: buyer, Coins: sdk.Coins{sdk.NewInt64Coin("byndcoin", 100)}}},
+	})
+	defer node.Close()
+
+	nodes, err := NewNodePool([]string{node.URL}, time.Second)
+	require.Nil(t, err)
+	server := NewServer(nodes)
+	dir, err := ioutil.TempDir("", "explorer")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	store, err := OpenIndexStore(filepath.Join(dir, "index.db"))
+	require.Nil(t, err)
+	defer store.Close()
+	_, err = NewIndexer(store, server, 0).CatchUp()
+	require.Nil(t, err)
+	explorer := httptest.NewServer(server.WithIndex(store).Router())
+	defer explorer.Close()
+
+	// the served document is the one the responses are validated against
+	res, err := http.Get(explorer.URL + "/openapi.yaml")
+	require.Nil(t, err)
+	bz, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	require.Nil(t, err)
+	require.Equal(t, openAPISpec, string(bz))
+	spec, err := openapi3.NewSwaggerLoader().LoadSwaggerFromData(bz)
+	require.Nil(t, err)
+	require.Nil(t, spec.Validate(context.Background()))
+
+	c := client.New(explorer.URL, &http.Client{
+		Transport: validatingTransport{t, openapi3filter.NewRouter().WithSwagger(spec)},
+	})
+	ctx := context.Background()
+
+	block, err := c.Block(ctx, 2)
+	require.Nil(t, err)
+	require.Len(t, block.Txs(), 1)
+	send, err := block.Txs()[0].Msgs[1].Send()
+	require.Nil(t, err)
+	require.Equal(t, station.String(), send.Outputs[0].Address)
+	require.Equal(t, "20", send.Outputs[0].Coins[0].Amount)
+
+	tx, err := c.Tx(ctx, block.Txs()[0].Hash)
+	require.Nil(t, err)
+	require.Equal(t, int64(2), tx.Height)
+	msg, err := tx.Msgs[0].FinalizeOrder()
+	require.Nil(t, err)
+	require.Equal(t, uint64(10), msg.TotalCharge)
+	_, err = tx.Msgs[0].InitOrder()
+	require.NotNil(t, err)
+
+	list, err := c.Blocks(ctx, 0, 0)
+	require.Nil(t, err)
+	require.Equal(t, int64(2), list.To)
+
+	account, err := c.Account(ctx, station.String())
+	require.Nil(t, err)
+	require.Equal(t, "00:11:22:33:44:55", account.MacAddress)
+	_, err = c.Account(ctx, buyer.String())
+	require.True(t, client.IsNotFound(err))
+
+	validators, err := c.Validators(ctx)
+	require.Nil(t, err)
+	require.Equal(t, int64(10), validators.Validators[0].VotingPower)
+	status, err := c.Status(ctx)
+	require.Nil(t, err)
+	require.Equal(t, "beyond", status.ChainID)
+	found, err := c.Search(ctx, "2")
+	require.Nil(t, err)
+	require.Equal(t, "2", found.Block.BlockMeta.Header.Height)
+	_, err = c.Health(ctx)
+	require.Nil(t, err)
+
+	orders, err := c.Orders(ctx, client.OrderFilter{Seller: station.String(), Status: "finalized"})
+	require.Nil(t, err)
+	require.Len(t, orders, 1)
+	order, err := c.Order(ctx, buyer.String(), 1)
+	require.Nil(t, err)
+	require.Equal(t, uint64(10), *order.TotalCharge)
+	payments, err := c.Payments(ctx, client.PaymentFilter{Address: station.String(), Limit: 10})
+	require.Nil(t, err)
+	require.Equal(t, uint64(1), *payments[0].OrderNumber)
+	activity, err := c.Activity(ctx, buyer.String())
+	require.Nil(t, err)
+	require.Equal(t, int64(2), activity.TxCount)
+	indexStatus, err := c.IndexStatus(ctx)
+	require.Nil(t, err)
+	require.Equal(t, int64(2), indexStatus.Checkpoint)
+	reports, err := c.EnergyReports(ctx, "month", client.AnalyticsFilter{Station: station.String()})
+	require.Nil(t, err)
+	require.Equal(t, int64(10), reports[0].Energy)
+	buyers, err := c.TopBuyers(ctx, 5, client.AnalyticsFilter{Since: time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC)})
+	require.Nil(t, err)
+	require.Equal(t, int64(20), buyers[0].Spent)
+	changes, err := c.BalanceHistory(ctx, buyer.String(), client.BalanceFilter{Denom: "byndcoin", Limit: 10})
+	require.Nil(t, err)
+	require.Len(t, changes, 4)
+	require.Equal(t, int64(78), changes[0].Balance)
+	require.Equal(t, client.BalanceGenesis, changes[3].Kind)
+	richList, err := c.RichList(ctx, "", 10, 0)
+	require.Nil(t, err)
+	require.Equal(t, int64(98), richList.Supply)
+	require.Equal(t, station.String(), richList.Holders[1].Address)
+}
+
+// TestOpenAPIExamples keeps the hand written client types in line with the
+// spec: the example of every schema is valid, decodes into its client type
+// without unknown fields and encodes back to the same JSON
+func TestOpenAPIExamples(t *testing.T) {
+	spec, err := openapi3.NewSwaggerLoader().LoadSwaggerFromData([]byte(openAPISpec))
+	require.Nil(t, err)
+
+	clientTypes := map[string]interface{}{
+		"Block":            &client.Block{},
+		"BlockMeta":        &client.BlockMeta{},
+		"Header":           &client.Header{},
+		"BlockList":        &client.BlockList{},
+		"Tx":               &client.Tx{},
+		"Msg":              &client.Msg{},
+		"InitOrderMsg":     &client.InitOrder{},
+		"FinalizeOrderMsg": &client.FinalizeOrder{},
+		"SendMsg":          &client.Send{},
+		"Transfer":         &client.Transfer{},
+		"Fee":              &client.Fee{},
+		"Coins":            &[]client.Coin{},
+		"Coin":             &client.Coin{},
+		"Tag":              &client.Tag{},
+		"Account":          &client.Account{},
+		"HsmInfo":          &client.HsmInfo{},
+		"Validator":        &client.Validator{},
+		"ValidatorList":    &client.ValidatorList{},
+		"Node":             &client.Node{},
+		"NodeList":         &[]client.Node{},
+		"Status":           &client.Status{},
+		"SearchResult":     &client.SearchResult{},
+		"Order":            &client.Order{},
+		"Payment":          &client.Payment{},
+		"Activity":         &client.Activity{},
+		"IndexStatus":      &client.IndexStatus{},
+		"BalanceChange":    &client.BalanceChange{},
+		"RichList":         &client.RichList{},
+		"Holder":           &client.Holder{},
+		"EnergyReport":     &client.EnergyReport{},
+		"BuyerReport":      &client.BuyerReport{},
+	}
+	for name, ref := range spec.Components.Schemas {
+		schema := ref.Value
+		v, ok := clientTypes[name]
+		if !ok {
+			require.Nil(t, schema.Example, "%s has an example but no client type", name)
+			continue
+		}
+		require.NotNil(t, schema.Example, "%s has no example", name)
+		require.Nil(t, schema.VisitJSON(schema.Example), name)
+
+		example, err := json.Marshal(schema.Example)
+		require.Nil(t, err)
+		dec := json.NewDecoder(bytes.NewReader(example))
+		dec.DisallowUnknownFields()
+		require.Nil(t, dec.Decode(v), name)
+
+		if name == "SearchResult" {
+			// decoded by its type and not encoded back
+			require.Equal(t, "9F0A4B1C7E2D3A5B6C8D9E0F1A2B3C4D5E6F7A8B", v.(*client.SearchResult).Tx.Hash)
+			continue
+		}
+
+		// every property of the schema has a field in the client type
+		if typ := reflect.TypeOf(v).Elem(); typ.Kind() == reflect.Struct {
+			fields := map[string]bool{}
+			for i := 0; i < typ.NumField(); i++ {
+				fields[strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]] = true
+			}
+			for prop := range schema.Properties {
+				require.True(t, fields[prop], "%s.%s has no client field", name, prop)
+			}
+		}
+		bz, err := json.Marshal(v)
+		require.Nil(t, err)
+		require.JSONEq(t, string(example), string(bz), name)
+	}
+}
+
+func TestPages(t *testing.T) {
+	cdc := app.MakeCodec()
+	buyer := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	station := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	acc := types.NewAppAccount("station", "00:11:22:33:44:55", "0.25", types.HsmInfo{}, auth.NewBaseAccountWithAddress(station))
+	fee := auth.NewStdFee(200000, sdk.NewInt64Coin("byndcoin", 1))
+	tx, err := cdc.MarshalBinaryLengthPrefixed(auth.NewStdTx([]sdk.Msg{mob.NewMsgInitOrder(buyer, station, 10, 50)}, fee, nil, "<b>memo</b>"))
+	require.Nil(t, err)
+	node := newFakeNode(t, fakeChain{blocks: map[int64][]fakeTx{1: {{tx: tx}}}, accounts: []auth.Account{acc}})
+	defer node.Close()
+
+	nodes, err := NewNodePool([]string{node.URL}, time.Second)
+	require.Nil(t, err)
+	router := NewServer(nodes).Router()
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	rec := get("/explorer/block/1")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	require.Contains(t, rec.Body.String(), "initOrder")
+	require.Contains(t, rec.Body.String(), "&lt;b&gt;memo&lt;/b&gt;")
+
+	rec = get(fmt.Sprintf("/explorer/tx/%X", tmtypes.Tx(tx).Hash()))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), `<a href="/explorer/account/`+station.String()+`">`)
+	require.Contains(t, rec.Body.String(), "<dd>50 kWh</dd>")
+
+	rec = get("/explorer/account/" + station.String())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), "00:11:22:33:44:55")
+	require.Contains(t, rec.Body.String(), "The order index is not enabled.")
+
+	rec = get("/explorer/search?q=1")
+	require.Equal(t, http.StatusFound, rec.Code)
+	require.Equal(t, "/explorer/block/1", rec.Header().Get("Location"))
+	rec = get("/explorer/block/x")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+}
@@ -0,0 +1,512 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	// registers the sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// schema of the index, every statement is idempotent
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS checkpoint (
+		id     INTEGER PRIMARY KEY CHECK (id = 0),
+		height INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS orders (
+		buyer            TEXT    NOT NULL,
+		number           INTEGER NOT NULL,
+		seller           TEXT    NOT NULL,
+		agreed_price     INTEGER NOT NULL,
+		estimated_charge INTEGER NOT NULL,
+		init_height      INTEGER NOT NULL,
+		init_time        INTEGER NOT NULL,
+		init_tx          TEXT    NOT NULL,
+		total_amount     INTEGER,
+		total_charge     INTEGER,
+		finalize_height  INTEGER,
+		finalize_time    INTEGER,
+		finalize_tx      TEXT,
+		PRIMARY KEY (buyer, number)
+	)`,
+	`CREATE INDEX IF NOT EXISTS orders_seller ON orders (seller, init_height)`,
+	`CREATE INDEX IF NOT EXISTS orders_height ON orders (init_height)`,
+	`CREATE TABLE IF NOT EXISTS payments (
+		tx           TEXT    NOT NULL,
+		msg_index    INTEGER NOT NULL,
+		seq          INTEGER NOT NULL,
+		height       INTEGER NOT NULL,
+		time         INTEGER NOT NULL,
+		sender       TEXT    NOT NULL,
+		recipient    TEXT    NOT NULL,
+		denom        TEXT    NOT NULL,
+		amount       INTEGER NOT NULL,
+		order_buyer  TEXT,
+		order_number INTEGER,
+		PRIMARY KEY (tx, msg_index, seq)
+	)`,
+	`CREATE INDEX IF NOT EXISTS payments_sender ON payments (sender, height)`,
+	`CREATE INDEX IF NOT EXISTS payments_recipient ON payments (recipient, height)`,
+	`CREATE TABLE IF NOT EXISTS accounts (
+		address      TEXT    PRIMARY KEY,
+		first_height INTEGER NOT NULL,
+		first_time   INTEGER NOT NULL,
+		last_height  INTEGER NOT NULL,
+		last_time    INTEGER NOT NULL,
+		tx_count     INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS balance_changes (
+		height  INTEGER NOT NULL,
+		seq     INTEGER NOT NULL,
+		time    INTEGER NOT NULL,
+		tx      TEXT,
+		kind    TEXT    NOT NULL,
+		address TEXT    NOT NULL,
+		denom   TEXT    NOT NULL,
+		delta   INTEGER NOT NULL,
+		balance INTEGER NOT NULL,
+		PRIMARY KEY (height, seq)
+	)`,
+	`CREATE INDEX IF NOT EXISTS balance_changes_address ON balance_changes (address, denom, height, seq)`,
+	`CREATE TABLE IF NOT EXISTS balances (
+		address TEXT    NOT NULL,
+		denom   TEXT    NOT NULL,
+		amount  INTEGER NOT NULL,
+		PRIMARY KEY (address, denom)
+	)`,
+	`CREATE INDEX IF NOT EXISTS balances_denom ON balances (denom, amount)`,
+	// balances are tracked from the genesis if 0, a store indexed before
+	// they existed tracks them from its checkpoint
+	`CREATE TABLE IF NOT EXISTS balances_from (
+		id     INTEGER PRIMARY KEY CHECK (id = 0),
+		height INTEGER NOT NULL
+	)`,
+	`INSERT OR IGNORE INTO balances_from (id, height) SELECT 0, COALESCE(MAX(height), 0) FROM checkpoint`,
+}
+
+// Order is an indexed order. The finalize fields are nil until the buyer
+// finalized it.
+type Order struct {
+	Buyer           string     `json:"buyer"`
+	Number          uint64     `json:"number"`
+	Seller          string     `json:"seller"`
+	AgreedPrice     uint64     `json:"agreedPrice"`
+	EstimatedCharge uint64     `json:"estimatedCharge"`
+	InitHeight      int64      `json:"initHeight"`
+	InitTime        time.Time  `json:"initTime"`
+	InitTx          string     `json:"initTx"`
+	TotalAmount     *uint64    `json:"totalAmount,omitempty"`
+	TotalCharge     *uint64    `json:"totalCharge,omitempty"`
+	FinalizeHeight  *int64     `json:"finalizeHeight,omitempty"`
+	FinalizeTime    *time.Time `json:"finalizeTime,omitempty"`
+	FinalizeTx      *string    `json:"finalizeTx,omitempty"`
+}
+
+// Payment is a coin transfer of a send msg. A send with several inputs is
+// stored as one payment per input without recipient and one per output
+// without sender.
+type Payment struct {
+	Tx          string    `json:"tx"`
+	Height      int64     `json:"height"`
+	Time        time.Time `json:"time"`
+	Sender      string    `json:"sender"`
+	Recipient   string    `json:"recipient"`
+	Denom       string    `json:"denom"`
+	Amount      int64     `json:"amount"`
+	OrderBuyer  *string   `json:"orderBuyer,omitempty"`
+	OrderNumber *uint64   `json:"orderNumber,omitempty"`
+}
+
+// IndexedAccount is the activity of an address seen in indexed txs
+type IndexedAccount struct {
+	Address     string    `json:"address"`
+	FirstHeight int64     `json:"firstHeight"`
+	FirstTime   time.Time `json:"firstTime"`
+	LastHeight  int64     `json:"lastHeight"`
+	LastTime    time.Time `json:"lastTime"`
+	TxCount     int64     `json:"txCount"`
+}
+
+// OrderFilter selects orders, zero fields match everything
+type OrderFilter struct {
+	Buyer     string
+	Seller    string
+	Party     string // buyer or seller
+	Tx        string // initOrder or finalizeOrder tx hash
+	Finalized *bool
+	From, To  int64 // init height range
+	Page
+}
+
+// PaymentFilter selects payments, zero fields match everything
+type PaymentFilter struct {
+	Address  string // sender or recipient
+	From, To int64  // height range
+	Page
+}
+
+// Page is the window of a list query
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// IndexStore keeps orders, payments, accounts and balances of the indexed
+// blocks in SQLite, together with the height of the last indexed block.
+type IndexStore struct {
+	db *sql.DB
+}
+
+// OpenIndexStore opens or creates the index database at path
+func OpenIndexStore(path string) (*IndexStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	// sqlite allows a single writer, queries wait for it instead of
+	// failing with "database is locked"
+	db.SetMaxOpenConns(1)
+	for _, stmt := range schema {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return &IndexStore{db: db}, nil
+}
+
+// Close closes the database
+func (s *IndexStore) Close() error {
+	return s.db.Close()
+}
+
+// Checkpoint returns the height of the last indexed block, 0 if none
+func (s *IndexStore) Checkpoint() (height int64, err error) {
+	err = s.db.QueryRow(`SELECT height FROM checkpoint WHERE id = 0`).Scan(&height)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return height, err
+}
+
+// ApplyBlock indexes the txs of a block and moves the checkpoint to it in a
+// single database transaction, so a restart never indexes a block twice.
+// Blocks have to be applied in order.
+func (s *IndexStore) ApplyBlock(block Result) (err error) {
+	height, err := strconv.ParseInt(block.Block_meta.Header.Height, 10, 64)
+	if err != nil {
+		return err
+	}
+	checkpoint, err := s.Checkpoint()
+	if err != nil {
+		return err
+	}
+	if checkpoint != 0 && height != checkpoint+1 {
+		return fmt.Errorf("block %d does not follow the checkpoint %d", height, checkpoint)
+	}
+
+	dbTx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dbTx.Rollback()
+		}
+	}()
+
+	// without genesis, balances start at 0 before the first indexed block
+	if checkpoint == 0 && height > 1 {
+		if _, err = dbTx.Exec(`UPDATE balances_from SET height = ?`, height-1); err != nil {
+			return err
+		}
+	}
+
+	blockTime := block.Block_meta.Header.Time.Unix()
+	changes := &balanceChanges{dbTx: dbTx, height: height, time: blockTime}
+	for _, tx := range block.Block.Data.Txs {
+		if tx.PaidFee() {
+			if err = changes.applyFee(tx); err != nil {
+				return fmt.Errorf("tx %s: %v", tx.Hash, err)
+			}
+		}
+		// the msgs of failed txs did not change the state
+		if tx.Code != 0 || tx.Error != "" {
+			continue
+		}
+		if err = applyTx(dbTx, tx, height, blockTime); err != nil {
+			return fmt.Errorf("tx %s: %v", tx.Hash, err)
+		}
+		if err = changes.applyTx(tx); err != nil {
+			return fmt.Errorf("tx %s: %v", tx.Hash, err)
+		}
+	}
+
+	if _, err = dbTx.Exec(`INSERT OR REPLACE INTO checkpoint (id, height) VALUES (0, ?)`, height); err != nil {
+		return err
+	}
+	return dbTx.Commit()
+}
+
+func applyTx(dbTx *sql.Tx, tx Tx, height, blockTime int64) error {
+	numbers, err := orderNumbers(tx)
+	if err != nil {
+		return err
+	}
+	next := func() (uint64, error) {
+		if len(numbers) == 0 {
+			return 0, errors.New("missing orderNumber tag")
+		}
+		n := numbers[0]
+		numbers = numbers[1:]
+		return n, nil
+	}
+
+	// the light node pays with a send in the finalizeOrder tx
+	var orderBuyer interface{}
+	var orderNumber interface{}
+	finalizes := 0
+
+	addresses := map[string]bool{}
+	for _, msg := range tx.Msgs {
+		switch v := msg.Value.(type) {
+		case InitOrder:
+			number, err := next()
+			if err != nil {
+				return err
+			}
+			_, err = dbTx.Exec(`INSERT OR REPLACE INTO orders
+				(buyer, number, seller, agreed_price, estimated_charge, init_height, init_time, init_tx)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				v.Initiator, number, v.Recipient, v.AgreedPrice, v.EstimatedCharge, height, blockTime, tx.Hash)
+			if err != nil {
+				return err
+			}
+			addresses[v.Initiator], addresses[v.Recipient] = true, true
+		case FinalizeOrder:
+			number, err := next()
+			if err != nil {
+				return err
+			}
+			_, err = dbTx.Exec(`UPDATE orders SET
+				total_amount = ?, total_charge = ?, finalize_height = ?, finalize_time = ?, finalize_tx = ?
+				WHERE buyer = ? AND number = ?`,
+				v.TotalAmount, v.TotalCharge, height, blockTime, tx.Hash, v.Initiator, number)
+			if err != nil {
+				return err
+			}
+			orderBuyer, orderNumber = v.Initiator, number
+			finalizes++
+			addresses[v.Initiator], addresses[v.Recipient] = true, true
+		}
+	}
+	if finalizes != 1 {
+		orderBuyer, orderNumber = nil, nil
+	}
+
+	for i, msg := range tx.Msgs {
+		send, ok := msg.Value.(Send)
+		if !ok {
+			continue
+		}
+		seq := 0
+		insert := func(sender, recipient string, coins sdk.Coins) error {
+			for _, coin := range coins {
+				if !coin.Amount.BigInt().IsInt64() {
+					return fmt.Errorf("amount %s does not fit the index", coin)
+				}
+				_, err := dbTx.Exec(`INSERT INTO payments
+					(tx, msg_index, seq, height, time, sender, recipient, denom, amount, order_buyer, order_number)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+					tx.Hash, i, seq, height, blockTime, sender, recipient, coin.Denom, coin.Amount.Int64(), orderBuyer, orderNumber)
+				if err != nil {
+					return err
+				}
+				seq++
+			}
+			return nil
+		}
+		if len(send.Inputs) == 1 {
+			for _, out := range send.Outputs {
+				if err := insert(send.Inputs[0].Address, out.Address, out.Coins); err != nil {
+					return err
+				}
+			}
+		} else {
+			for _, in := range send.Inputs {
+				if err := insert(in.Address, "", in.Coins); err != nil {
+					return err
+				}
+			}
+			for _, out := range send.Outputs {
+				if err := insert("", out.Address, out.Coins); err != nil {
+					return err
+				}
+			}
+		}
+		for _, in := range send.Inputs {
+			addresses[in.Address] = true
+		}
+		for _, out := range send.Outputs {
+			addresses[out.Address] = true
+		}
+	}
+
+	for addr := range addresses {
+		_, err := dbTx.Exec(`INSERT INTO accounts (address, first_height, first_time, last_height, last_time, tx_count)
+			VALUES (?, ?, ?, ?, ?, 1)
+			ON CONFLICT (address) DO UPDATE SET last_height = excluded.last_height, last_time = excluded.last_time, tx_count = tx_count + 1`,
+			addr, height, blockTime, height, blockTime)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const orderColumns = `buyer, number, seller, agreed_price, estimated_charge, init_height, init_time, init_tx,
+	total_amount, total_charge, finalize_height, finalize_time, finalize_tx`
+
+func scanOrder(row interface{ Scan(...interface{}) error }) (order Order, err error) {
+	var initTime int64
+	var finalizeTime *int64
+	err = row.Scan(&order.Buyer, &order.Number, &order.Seller, &order.AgreedPrice, &order.EstimatedCharge,
+		&order.InitHeight, &initTime, &order.InitTx,
+		&order.TotalAmount, &order.TotalCharge, &order.FinalizeHeight, &finalizeTime, &order.FinalizeTx)
+	order.InitTime = time.Unix(initTime, 0).UTC()
+	if finalizeTime != nil {
+		t := time.Unix(*finalizeTime, 0).UTC()
+		order.FinalizeTime = &t
+	}
+	return order, err
+}
+
+// Order returns an order by its buyer and number
+func (s *IndexStore) Order(buyer string, number uint64) (order Order, found bool, err error) {
+	order, err = scanOrder(s.db.QueryRow(`SELECT `+orderColumns+` FROM orders WHERE buyer = ? AND number = ?`, buyer, number))
+	if err == sql.ErrNoRows {
+		return order, false, nil
+	}
+	return order, err == nil, err
+}
+
+// where builds a WHERE clause of the conditions that apply
+type where struct {
+	conds []string
+	args  []interface{}
+}
+
+func (w *where) add(cond string, args ...interface{}) {
+	w.conds = append(w.conds, cond)
+	w.args = append(w.args, args...)
+}
+
+func (w *where) String() string {
+	if len(w.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(w.conds, " AND ")
+}
+
+// Orders returns the orders matching the filter, newest first
+func (s *IndexStore) Orders(f OrderFilter) ([]Order, error) {
+	var w where
+	if f.Buyer != "" {
+		w.add("buyer = ?", f.Buyer)
+	}
+	if f.Seller != "" {
+		w.add("seller = ?", f.Seller)
+	}
+	if f.Party != "" {
+		w.add("(buyer = ? OR seller = ?)", f.Party, f.Party)
+	}
+	if f.Tx != "" {
+		w.add("(init_tx = ? OR finalize_tx = ?)", f.Tx, f.Tx)
+	}
+	if f.Finalized != nil {
+		if *f.Finalized {
+			w.add("finalize_height IS NOT NULL")
+		} else {
+			w.add("finalize_height IS NULL")
+		}
+	}
+	if f.From > 0 {
+		w.add("init_height >= ?", f.From)
+	}
+	if f.To > 0 {
+		w.add("init_height <= ?", f.To)
+	}
+	rows, err := s.db.Query(`SELECT `+orderColumns+` FROM orders`+w.String()+
+		` ORDER BY init_height DESC, buyer, number DESC LIMIT ? OFFSET ?`, append(w.args, f.Limit, f.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []Order{}
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
+
+// Payments returns the payments matching the filter, newest first
+func (s *IndexStore) Payments(f PaymentFilter) ([]Payment, error) {
+	var w where
+	if f.Address != "" {
+		w.add("(sender = ? OR recipient = ?)", f.Address, f.Address)
+	}
+	if f.From > 0 {
+		w.add("height >= ?", f.From)
+	}
+	if f.To > 0 {
+		w.add("height <= ?", f.To)
+	}
+	rows, err := s.db.Query(`SELECT tx, height, time, sender, recipient, denom, amount, order_buyer, order_number
+		FROM payments`+w.String()+` ORDER BY height DESC, tx, msg_index, seq LIMIT ? OFFSET ?`, append(w.args, f.Limit, f.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []Payment{}
+	for rows.Next() {
+		var p Payment
+		var t int64
+		if err := rows.Scan(&p.Tx, &p.Height, &t, &p.Sender, &p.Recipient, &p.Denom, &p.Amount, &p.OrderBuyer, &p.OrderNumber); err != nil {
+			return nil, err
+		}
+		p.Time = time.Unix(t, 0).UTC()
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
+
+// Account returns the activity of an address
+func (s *IndexStore) Account(address string) (acc IndexedAccount, found bool, err error) {
+	var first, last int64
+	err = s.db.QueryRow(`SELECT address, first_height, first_time, last_height, last_time, tx_count
+		FROM accounts WHERE address = ?`, address).
+		Scan(&acc.Address, &acc.FirstHeight, &first, &acc.LastHeight, &last, &acc.TxCount)
+	if err == sql.ErrNoRows {
+		return acc, false, nil
+	}
+	acc.FirstTime, acc.LastTime = time.Unix(first, 0).UTC(), time.Unix(last, 0).UTC()
+	return acc, err == nil, err
+}
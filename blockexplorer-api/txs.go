@@ -0,0 +1,209 @@
+/* Copyright (C) beyond protocol inc. - All Rights Reserved
+ * You may use, distribute and modify this code under the
+ * terms and conditions defined in the file 'LICENSE.txt',
+ * which is part of this source code package.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	mob "github.com/vincepg13/bp-sdk/beyond/x/mobility"
+	"github.com/vincepg13/bp-sdk/beyond/x/mobility/tags"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// Msg types of the messages returned with their fields, other messages
+// are returned as encoded by the app codec with their route and type
+const (
+	MsgTypeInitOrder     = "initOrder"
+	MsgTypeFinalizeOrder = "finalizeOrder"
+	MsgTypeSend          = "send"
+)
+
+// Tx is a decoded transaction with its result
+type Tx struct {
+	Hash      string `json:"hash"`
+	Height    int64  `json:"height"`
+	Index     int    `json:"index"`
+	Code      uint32 `json:"code"`      // 0 if the tx was applied
+	GasWanted int64  `json:"gasWanted"` // 0 if the ante handler rejected the tx
+	Log       string `json:"log,omitempty"`
+	Msgs      []Msg  `json:"msgs"`
+	Fee       Fee    `json:"fee"`
+	FeePayer  string `json:"feePayer,omitempty"` // first signer, the fee is deducted from its account
+	Memo      string `json:"memo,omitempty"`
+	Tags      []Tag  `json:"tags"`
+	Error     string `json:"error,omitempty"` // set if the tx could not be decoded
+}
+
+// PaidFee returns true if the fee was deducted from the fee payer. The ante
+// handler deducts it before the msgs run and keeps it when they fail, a tx
+// it rejects paid nothing and reports no gas wanted.
+func (tx Tx) PaidFee() bool {
+	return tx.Error == "" && tx.FeePayer != "" && (tx.Code == 0 || tx.GasWanted > 0)
+}
+
+// Msg is a message of a tx
+type Msg struct {
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// Fee is the fee paid by a tx
+type Fee struct {
+	Amount sdk.Coins `json:"amount"`
+	Gas    int64     `json:"gas"`
+}
+
+// Tag is a result tag of a tx
+type Tag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// InitOrder is the value of an initOrder msg
+type InitOrder struct {
+	Initiator       string `json:"initiator"`
+	Recipient       string `json:"recipient"`
+	AgreedPrice     uint64 `json:"agreedPrice"`
+	EstimatedCharge uint64 `json:"estimatedCharge"`
+}
+
+// FinalizeOrder is the value of a finalizeOrder msg
+type FinalizeOrder struct {
+	Initiator   string `json:"initiator"`
+	Recipient   string `json:"recipient"`
+	TotalAmount uint64 `json:"totalAmount"`
+	TotalCharge uint64 `json:"totalCharge"`
+}
+
+// Send is the value of a send msg
+type Send struct {
+	Inputs  []Transfer `json:"inputs"`
+	Outputs []Transfer `json:"outputs"`
+}
+
+// Transfer is an input or output of a send msg
+type Transfer struct {
+	Address string    `json:"address"`
+	Coins   sdk.Coins `json:"coins"`
+}
+
+// deliverTx is the result of a tx as returned by the block_results RPC
+type deliverTx struct {
+	Code      uint32 `json:"code"`
+	Log       string `json:"log"`
+	GasWanted int64  `json:"gas_wanted,string"`
+	Tags      []struct {
+		Key   []byte `json:"key"`
+		Value []byte `json:"value"`
+	} `json:"tags"`
+}
+
+// TxDecoder turns raw txs into their explorer representation
+type TxDecoder struct {
+	cdc    *codec.Codec
+	decode sdk.TxDecoder
+}
+
+// NewTxDecoder returns a decoder for the txs of the app codec
+func NewTxDecoder(cdc *codec.Codec) TxDecoder {
+	return TxDecoder{cdc: cdc, decode: auth.DefaultTxDecoder(cdc)}
+}
+
+// DecodeTx decodes a tx and its result. A tx that can not be decoded is
+// still returned with its hash and result.
+func (d TxDecoder) DecodeTx(bz []byte, height int64, index int, result deliverTx) Tx {
+	tx := Tx{
+		Hash:      fmt.Sprintf("%X", tmtypes.Tx(bz).Hash()),
+		Height:    height,
+		Index:     index,
+		Code:      result.Code,
+		GasWanted: result.GasWanted,
+		Log:       result.Log,
+		Msgs:      []Msg{},
+		Tags:      []Tag{},
+	}
+	for _, tag := range result.Tags {
+		tx.Tags = append(tx.Tags, Tag{Key: string(tag.Key), Value: string(tag.Value)})
+	}
+
+	decoded, err := d.decode(bz)
+	if err != nil {
+		tx.Error = err.Error()
+		return tx
+	}
+	stdTx, ok := decoded.(auth.StdTx)
+	if !ok {
+		tx.Error = fmt.Sprintf("unexpected tx type %T", decoded)
+		return tx
+	}
+	tx.Fee = Fee{Amount: stdTx.Fee.Amount, Gas: stdTx.Fee.Gas}
+	tx.Memo = stdTx.Memo
+	if signers := stdTx.GetSigners(); len(signers) > 0 {
+		tx.FeePayer = signers[0].String()
+	}
+	for _, msg := range stdTx.GetMsgs() {
+		tx.Msgs = append(tx.Msgs, d.decodeMsg(msg))
+	}
+	return tx
+}
+
+// orderNumbers returns the order numbers tagged by the initOrder and
+// finalizeOrder msgs of a tx, in msg order
+func orderNumbers(tx Tx) (numbers []uint64, err error) {
+	for _, tag := range tx.Tags {
+		if tag.Key != tags.OrderNumber {
+			continue
+		}
+		n, err := strconv.ParseUint(tag.Value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+func (d TxDecoder) decodeMsg(msg sdk.Msg) Msg {
+	switch msg := msg.(type) {
+	case mob.MsgInitOrder:
+		return Msg{Type: MsgTypeInitOrder, Value: InitOrder{
+			Initiator:       msg.InitiatorAddress.String(),
+			Recipient:       msg.RecipientAddress.String(),
+			AgreedPrice:     msg.AgreedPrice,
+			EstimatedCharge: msg.EstimatedCharge,
+		}}
+	case mob.MsgFinalizeOrder:
+		return Msg{Type: MsgTypeFinalizeOrder, Value: FinalizeOrder{
+			Initiator:   msg.InitiatorAddress.String(),
+			Recipient:   msg.RecipientAddress.String(),
+			TotalAmount: msg.TotalAmount,
+			TotalCharge: msg.TotalCharge,
+		}}
+	case bank.MsgSend:
+		send := Send{Inputs: []Transfer{}, Outputs: []Transfer{}}
+		for _, in := range msg.Inputs {
+			send.Inputs = append(send.Inputs, Transfer{Address: in.Address.String(), Coins: in.Coins})
+		}
+		for _, out := range msg.Outputs {
+			send.Outputs = append(send.Outputs, Transfer{Address: out.Address.String(), Coins: out.Coins})
+		}
+		return Msg{Type: MsgTypeSend, Value: send}
+	default:
+		var value interface{}
+		if bz, err := d.cdc.MarshalJSON(msg); err == nil {
+			value = json.RawMessage(bz)
+		}
+		return Msg{Type: msg.Route() + "/" + msg.Type(), Value: value}
+	}
+}
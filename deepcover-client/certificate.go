@@ -0,0 +1,158 @@
+package deepcoverclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/big"
+)
+
+// Certificate pages, see the DS28C36 memory setup in dcdriver.c
+const (
+	PageCertificateR = 14
+	PageCertificateS = 15
+
+	// CertificateBodySize is the size of the data signed by the Verify Authority
+	CertificateBodySize = 90
+)
+
+// ErrInvalidCertificate is returned when the device certificate does not
+// verify against the authority key
+var ErrInvalidCertificate = errors.New("deepcover: invalid device certificate")
+
+// DeviceCertificate is the ECDH certificate stored on a secure element
+// together with the fields it certifies
+type DeviceCertificate struct {
+	PubKeyA []byte // 64 bytes, PubKeyAX followed by PubKeyAY
+	Custom  []byte // 16 bytes customization field, zeros when empty
+	RomID   []byte // 8 bytes
+	ManID   []byte // 2 bytes
+	R       []byte // 32 bytes, page 14
+	S       []byte // 32 bytes, page 15
+}
+
+// DeviceIdentity is a secure element whose certificate has been verified
+type DeviceIdentity struct {
+	RomID  []byte
+	ManID  []byte
+	PubKey *ecdsa.PublicKey
+}
+
+// Body rebuilds the 90 byte certificate body
+func (c DeviceCertificate) Body() ([]byte, error) {
+	/*
+		-------------------------------------+----------
+		Public Key X                         |	32 bytes
+		Public Key Y                         |	32 bytes
+		Customization cert field             |	16 bytes
+		ROMID                                |	 8 bytes
+		MANID                                |	 2 bytes
+		-------------------------------------+ total 90 bytes
+	*/
+	custom := c.Custom
+	if custom == nil {
+		custom = make([]byte, 16)
+	}
+	if len(c.PubKeyA) != 64 || len(custom) != 16 || len(c.RomID) != 8 || len(c.ManID) != 2 {
+		return nil, ErrInvalidCertificate
+	}
+
+	body := make([]byte, 0, CertificateBodySize)
+	body = append(body, c.PubKeyA...)
+	body = append(body, custom...)
+	body = append(body, c.RomID...)
+	body = append(body, c.ManID...)
+	return body, nil
+}
+
+// VerifyCertificate checks the certificate signature against the Verify
+// Authority public key and returns the identity of the device
+func VerifyCertificate(c DeviceCertificate, authority *ecdsa.PublicKey) (*DeviceIdentity, error) {
+	body, err := c.Body()
+	if err != nil {
+		return nil, err
+	}
+	if len(c.R) != 32 || len(c.S) != 32 || authority == nil {
+		return nil, ErrInvalidCertificate
+	}
+
+	digest := sha256.Sum256(body)
+	r := new(big.Int).SetBytes(c.R)
+	s := new(big.Int).SetBytes(c.S)
+	if !ecdsa.Verify(authority, digest[:], r, s) {
+		return nil, ErrInvalidCertificate
+	}
+
+	pub := byteToPublicKey(c.PubKeyA[:32], c.PubKeyA[32:])
+	if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, ErrInvalidCertificate
+	}
+
+	return &DeviceIdentity{
+		RomID:  append([]byte(nil), c.RomID...),
+		ManID:  append([]byte(nil), c.ManID...),
+		PubKey: pub,
+	}, nil
+}
+
+// ParseAuthorityKey parses a Verify Authority public key given as the hex
+// encoding of X followed by Y
+func ParseAuthorityKey(hexKey string) (*ecdsa.PublicKey, error) {
+	bz, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(bz) != 64 {
+		return nil, errors.New("deepcover: authority key must be 64 bytes")
+	}
+	pub := byteToPublicKey(bz[:32], bz[32:])
+	if !elliptic.P256().IsOnCurve(pub.X, pub.Y) {
+		return nil, errors.New("deepcover: authority key is not on curve P-256")
+	}
+	return pub, nil
+}
+
+// ReadCertificate reads the certificate and the certified fields from the device
+func (s *Session) ReadCertificate(ctx context.Context) (DeviceCertificate, error) {
+	var c DeviceCertificate
+
+	pubKeyA, err := s.PubKeyA(ctx)
+	if err != nil {
+		return c, err
+	}
+	romOptions, err := s.ReadPage(ctx, PageROMOptions)
+	if err != nil {
+		return c, err
+	}
+	r, err := s.ReadPage(ctx, PageCertificateR)
+	if err != nil {
+		return c, err
+	}
+	sig, err := s.ReadPage(ctx, PageCertificateS)
+	if err != nil {
+		return c, err
+	}
+
+	c.PubKeyA = pubKeyA
+	c.RomID = romOptions[romOffsetRomID : romOffsetRomID+8]
+	c.ManID = romOptions[romOffsetManID : romOffsetManID+2]
+	c.R = r
+	c.S = sig
+	return c, nil
+}
+
+// VerifyDevice reads the certificate from the device and verifies it
+// against the Verify Authority public key
+func (s *Session) VerifyDevice(ctx context.Context, authority *ecdsa.PublicKey) (*DeviceIdentity, error) {
+	c, err := s.ReadCertificate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateROMID(c.RomID); err != nil {
+		return nil, err
+	}
+	return VerifyCertificate(c, authority)
+}
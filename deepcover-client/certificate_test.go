@@ -0,0 +1,172 @@
+package deepcoverclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func publicKeyBytes(pub *ecdsa.PublicKey) []byte {
+	bz := make([]byte, 64)
+	putInt(bz[:32], pub.X)
+	putInt(bz[32:], pub.Y)
+	return bz
+}
+
+// certify signs the certificate body with the authority key
+func certify(t *testing.T, authority *ecdsa.PrivateKey, c DeviceCertificate) DeviceCertificate {
+	body, err := c.Body()
+	require.Nil(t, err)
+	digest := sha256.Sum256(body)
+	r, s, err := ecdsa.Sign(rand.Reader, authority, digest[:])
+	require.Nil(t, err)
+	sig := RawSignature(r, s)
+	c.R, c.S = sig[:32], sig[32:]
+	return c
+}
+
+func newCertificate(t *testing.T, authority *ecdsa.PrivateKey) (DeviceCertificate, *ecdsa.PrivateKey) {
+	device, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	romID, err := hex.DecodeString("4c123456789a0053")
+	require.Nil(t, err)
+	return certify(t, authority, DeviceCertificate{
+		PubKeyA: publicKeyBytes(&device.PublicKey),
+		RomID:   romID,
+		ManID:   []byte{0x12, 0x34},
+	}), device
+}
+
+func TestCertificateBody(t *testing.T) {
+	c := DeviceCertificate{
+		PubKeyA: make([]byte, 64),
+		RomID:   []byte{1, 2, 3, 4, 5, 6, 7, 8},
+		ManID:   []byte{9, 10},
+	}
+	for i := range c.PubKeyA {
+		c.PubKeyA[i] = 0xaa
+	}
+
+	body, err := c.Body()
+	require.Nil(t, err)
+	require.Len(t, body, CertificateBodySize)
+	require.Equal(t, c.PubKeyA, body[:64])
+	require.Equal(t, make([]byte, 16), body[64:80])
+	require.Equal(t, c.RomID, body[80:88])
+	require.Equal(t, c.ManID, body[88:])
+
+	c.Custom = []byte("0123456789abcdef")
+	body, err = c.Body()
+	require.Nil(t, err)
+	require.Equal(t, c.Custom, body[64:80])
+
+	for name, wrong := range map[string]DeviceCertificate{
+		"pubkey": {PubKeyA: c.PubKeyA[:63], RomID: c.RomID, ManID: c.ManID},
+		"custom": {PubKeyA: c.PubKeyA, Custom: c.Custom[:15], RomID: c.RomID, ManID: c.ManID},
+		"rom id": {PubKeyA: c.PubKeyA, RomID: c.RomID[:7], ManID: c.ManID},
+		"man id": {PubKeyA: c.PubKeyA, RomID: c.RomID, ManID: c.ManID[:1]},
+	} {
+		_, err := wrong.Body()
+		require.Equal(t, ErrInvalidCertificate, err, name)
+	}
+}
+
+func TestVerifyCertificate(t *testing.T) {
+	authority, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	c, device := newCertificate(t, authority)
+
+	id, err := VerifyCertificate(c, &authority.PublicKey)
+	require.Nil(t, err)
+	require.Equal(t, c.RomID, id.RomID)
+	require.Equal(t, c.ManID, id.ManID)
+	require.Equal(t, 0, id.PubKey.X.Cmp(device.X))
+	require.Equal(t, 0, id.PubKey.Y.Cmp(device.Y))
+
+	// the identity does not alias the certificate
+	id.RomID[0] ^= 0xff
+	require.NotEqual(t, c.RomID, id.RomID)
+
+	tamper := func(field []byte) []byte {
+		bz := append([]byte(nil), field...)
+		bz[len(bz)-1] ^= 0x01
+		return bz
+	}
+	for name, f := range map[string]func(c *DeviceCertificate){
+		"pubkey":       func(c *DeviceCertificate) { c.PubKeyA = tamper(c.PubKeyA) },
+		"custom":       func(c *DeviceCertificate) { c.Custom = tamper(make([]byte, 16)) },
+		"rom id":       func(c *DeviceCertificate) { c.RomID = tamper(c.RomID) },
+		"man id":       func(c *DeviceCertificate) { c.ManID = tamper(c.ManID) },
+		"r":            func(c *DeviceCertificate) { c.R = tamper(c.R) },
+		"s":            func(c *DeviceCertificate) { c.S = tamper(c.S) },
+		"short r":      func(c *DeviceCertificate) { c.R = c.R[:31] },
+		"long s":       func(c *DeviceCertificate) { c.S = append(append([]byte(nil), c.S...), 0) },
+		"short pubkey": func(c *DeviceCertificate) { c.PubKeyA = c.PubKeyA[:63] },
+		"long rom id":  func(c *DeviceCertificate) { c.RomID = append(append([]byte(nil), c.RomID...), 0) },
+	} {
+		wrong := c
+		f(&wrong)
+		_, err := VerifyCertificate(wrong, &authority.PublicKey)
+		require.Equal(t, ErrInvalidCertificate, err, name)
+	}
+
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	_, err = VerifyCertificate(c, &other.PublicKey)
+	require.Equal(t, ErrInvalidCertificate, err)
+	_, err = VerifyCertificate(c, nil)
+	require.Equal(t, ErrInvalidCertificate, err)
+
+	// a certified key that is not on the curve is rejected
+	offCurve := certify(t, authority, DeviceCertificate{PubKeyA: tamper(c.PubKeyA), RomID: c.RomID, ManID: c.ManID})
+	_, err = VerifyCertificate(offCurve, &authority.PublicKey)
+	require.Equal(t, ErrInvalidCertificate, err)
+}
+
+func TestParseAuthorityKey(t *testing.T) {
+	authority, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	bz := publicKeyBytes(&authority.PublicKey)
+
+	pub, err := ParseAuthorityKey(hex.EncodeToString(bz))
+	require.Nil(t, err)
+	require.Equal(t, 0, pub.X.Cmp(authority.X))
+	require.Equal(t, 0, pub.Y.Cmp(authority.Y))
+
+	_, err = ParseAuthorityKey(hex.EncodeToString(bz[:63]))
+	require.NotNil(t, err)
+	_, err = ParseAuthorityKey("not hex")
+	require.NotNil(t, err)
+	bz[63] ^= 0x01
+	_, err = ParseAuthorityKey(hex.EncodeToString(bz))
+	require.NotNil(t, err)
+}
+
+func TestVerifyDevice(t *testing.T) {
+	ctx := context.Background()
+	authority, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	b := newSoftwareBackend(t)
+	s := NewSession(b)
+
+	c, err := s.ReadCertificate(ctx)
+	require.Nil(t, err)
+	_, err = s.VerifyDevice(ctx, &authority.PublicKey)
+	require.Equal(t, ErrInvalidCertificate, err)
+
+	c = certify(t, authority, c)
+	require.Nil(t, s.WritePage(ctx, PageCertificateR, c.R))
+	require.Nil(t, s.WritePage(ctx, PageCertificateS, c.S))
+	id, err := s.VerifyDevice(ctx, &authority.PublicKey)
+	require.Nil(t, err)
+	pub, err := s.PublicKey(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 0, id.PubKey.X.Cmp(pub.X))
+	require.Equal(t, c.RomID, id.RomID)
+}
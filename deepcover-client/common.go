@@ -3,6 +3,7 @@ package deepcoverclient
 // Go packages
 import (
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -26,83 +27,135 @@ func HexString2Bytes(indata string) []byte {
 	return src
 }
 
-// VerifyDeepCoverSignature virifies the signature using public keys and calculated digest
+// VerifyDeepCoverSignature verifies the signature using public keys and calculated digest
+//
+// Deprecated: R and S are given as hexadecimal strings and S may lie in
+// either half of the curve order, as read from the chip. Use
+// VerifySignature for the R||S signatures carried on chain.
 func VerifyDeepCoverSignature(pub *ecdsa.PublicKey, digest []byte, signatureR string, signatureS string) bool {
-	var ecdsaSig ecdsaSignature
-
-	r := new(big.Int)
-	s := new(big.Int)
-
-	r.SetString(signatureR, 16)
-	s.SetString(signatureS, 16)
-
-	ecdsaSig.R = r
-	ecdsaSig.S = s
-
-	fmt.Printf("Converted signature R and S\n -->\tR: %d,\n -->\tS: %d\n\n", ecdsaSig.R, ecdsaSig.S)
-	return ecdsa.Verify(pub, digest, ecdsaSig.R, ecdsaSig.S)
+	r, okR := new(big.Int).SetString(signatureR, 16)
+	s, okS := new(big.Int).SetString(signatureS, 16)
+	if !okR || !okS || !inRange(r) || !inRange(s) {
+		return false
+	}
+	sig, err := NormalizeSignature(RawSignature(r, s))
+	if err != nil {
+		return false
+	}
+	return VerifySignature(pub, digest, sig)
 }
 
-// CalcucateMessageDigest calculates the message digest
-func CalcucateMessageDigest(message []byte, romID []byte) []byte {
-	/*
-		this function requires folowing packages:
-
-		"crypto/sha256"
-		"encoding/hex"
-
-	*/
-
-	var totalBuffer []byte
-
-	if len(romID) != 8 {
-		// define default romID of length 8
-		// this will, of course, result in the verification failure
-		// TO DO: apply different error handling mechanism
-		romID = []byte{0, 0, 0, 0, 0, 0, 0, 0}
+// CalcucateMessageDigest calculates the message digest the DS28C36 signs in
+// Compute and Read Page Authentication, given the 32 byte challenge written
+// to the buffer and the device data described by layout
+func CalcucateMessageDigest(message []byte, layout DigestLayout) ([]byte, error) {
+	if len(message) != 32 {
+		return nil, fmt.Errorf("deepcover: challenge must be 32 bytes, got %d", len(message))
+	}
+	if err := layout.Validate(); err != nil {
+		return nil, err
 	}
 
 	// Reconstruct DeepCover data fields for message digest (SHA256) calculation
 	/*
 		-------------------------------------+----------
 		ROMID=romID []byte    				 |	 8 bytes
-		PAGE0=FFFF........................FF | 	32 bytes
+		PAGE DATA=xxx.....................xx | 	32 bytes <-content of the signed page
 		BUFFER=xxx........................xx | 	32 bytes <-hash of transaction bytes in blockchain
-		PAGE=00 							 |	 1 byte
-		MANID=0000 							 |	 2 bytes
+		PAGE=xx 							 |	 1 byte
+		MANID=xxxx 							 |	 2 bytes
 		-------------------------------------+ total 75bytes
 	*/
+	h := sha256.New()
+	h.Write(layout.RomID)
+	h.Write(layout.PageData)
+	h.Write(message)
+	h.Write([]byte{byte(layout.Page)})
+	h.Write(layout.ManID)
 
-	// hardcoded values from DeepCover eeprom memory
-	page0HexString := "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF"
-	pageHexString := "00"
-	mainIDHexString := "0000"
-
-	page0, err := hex.DecodeString(page0HexString)
-	printError(err)
-	page, err := hex.DecodeString(pageHexString)
-	printError(err)
-	mainID, err := hex.DecodeString(mainIDHexString)
-	printError(err)
-
-	// totalBuffer = romID + page0 + message + page + mainID
-	totalBuffer = append(totalBuffer, romID...)
-	totalBuffer = append(totalBuffer, page0...)
-	totalBuffer = append(totalBuffer, message...)
-	totalBuffer = append(totalBuffer, page...)
-	totalBuffer = append(totalBuffer, mainID...)
+	return h.Sum(nil), nil
+}
 
-	h := sha256.New()
-	h.Write(totalBuffer)
-	var sha256cs = h.Sum(nil)
+// ValidateROMID checks the length, CRC-8 and DS28C36 family code of a ROM ID
+func ValidateROMID(romID []byte) error {
+	if len(romID) != 8 {
+		return ErrInvalidROMID
+	}
+	// See Application Note 27
+	var crc8 byte
+	for _, b := range romID {
+		crc8 ^= b
+		for i := 0; i < 8; i++ {
+			if crc8&1 != 0 {
+				crc8 = (crc8 >> 1) ^ 0x8c
+			} else {
+				crc8 >>= 1
+			}
+		}
+	}
+	if crc8 != 0 || romID[0]&0x7F != 0x4C {
+		return ErrInvalidROMID
+	}
+	return nil
+}
+
+func hexToPrivateKey(privKeyHex string) *ecdsa.PrivateKey {
+	bytes, err := hex.DecodeString(privKeyHex)
+	print(err)
 
-	return sha256cs
+	return byteToPrivateKey(bytes)
 }
 
-func printError(err error) {
-	if err != nil {
-		fmt.Println("Error: ", err.Error())
-	} else {
-		//fmt.Println("No error")
+func byteToPrivateKey(privKey []byte) *ecdsa.PrivateKey {
+	k := new(big.Int)
+	k.SetBytes(privKey)
+
+	priv := new(ecdsa.PrivateKey)
+	curve := elliptic.P256()
+	priv.PublicKey.Curve = curve
+	priv.D = k
+	priv.PublicKey.X, priv.PublicKey.Y = curve.ScalarBaseMult(k.Bytes())
+	fmt.Printf("Calculated PubKey from PrivKey-->X: %d, Y: %d", priv.PublicKey.X, priv.PublicKey.Y)
+	fmt.Printf("\n\n")
+
+	return priv
+}
+
+// PublicKeyFromBytes returns the P-256 public key given as X followed by Y
+func PublicKeyFromBytes(pubKeyA []byte) (*ecdsa.PublicKey, error) {
+	if len(pubKeyA) != 64 {
+		return nil, fmt.Errorf("deepcover: public key must be 64 bytes, got %d", len(pubKeyA))
 	}
+	pub := byteToPublicKey(pubKeyA[:32], pubKeyA[32:])
+	if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, fmt.Errorf("deepcover: public key is not on curve P-256")
+	}
+	return pub, nil
+}
+
+func hexToPublicKey(pubKeyXpart string, pubKeyYpart string) *ecdsa.PublicKey {
+	xBytes, _ := hex.DecodeString(pubKeyXpart)
+	yBytes, _ := hex.DecodeString(pubKeyYpart)
+
+	return byteToPublicKey(xBytes, yBytes)
+}
+
+func byteToPublicKey(pubKeyXpart []byte, pubKeyYpart []byte) *ecdsa.PublicKey {
+	x := new(big.Int)
+	x.SetBytes(pubKeyXpart)
+
+	y := new(big.Int)
+	y.SetBytes(pubKeyYpart)
+
+	pub := new(ecdsa.PublicKey)
+	pub.X = x
+	pub.Y = y
+
+	pub.Curve = elliptic.P256()
+
+	return pub
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
 }
@@ -469,6 +469,41 @@ int writePageData(int page, unsigned char * data) {
 
 }
 
+//---------------------------------------------------------------------------
+//Set page protection, protection bits can not be cleared again
+int setPageProtection(int page, int protection) {
+	if (page >=0 && page < 32) {
+	    if (driver_initialized == 0) initDriver();
+
+	    /*
+	    •	<Start, device address write>
+	    •	TX: Set Page Protection Command
+	    •	TX: Length (SMBus) [always 2]
+	    •	TX: Parameter (page)
+	    •	TX: Protection
+	    •	<Stop>
+	    •	<Delay>
+	    •	<Start, device address read>
+	    •	RX: Length (SMBus) [always 1]
+	    •	RX: Result byte
+	    •	<Stop>
+	    */
+
+	    write_buff[0] = CMD_SET_PAGE_PROT;
+	    write_buff[1] = 2;
+	    write_buff[2] = page;
+	    write_buff[3] = protection;
+	    int out_count = write (i2c_fd, write_buff, 4);
+
+	    usleep(12500); //EEPROM write
+
+		int redoutCount = read(i2c_fd, read_buff, 2); //len + res_code
+		return read_buff[1]; //Result byte
+	} else {
+		return RESULT_FAIL_PARAMETETER;
+	}
+}
+
 //---------------------------------------------------------------------------
 //Read buffer data from device
 unsigned char * getBufferData(int len, int skip_header) {
@@ -565,5 +600,33 @@ unsigned char * computeReadPageAuthentication(unsigned char * data32, int skip_h
     }
 }
 
+//---------------------------------------------------------------------------
+// ECDSA key pair generation
+//---------------------------------------------------------------------------
+int generateECDSAKeyPair(int key, int lock) {
+
+    if (driver_initialized == 0) initDriver();
+
+    /*
+    •	<Start, device address write>
+    •	TX: Generate ECC Key Pair command
+    •	TX: Length (SMBus)
+    •	TX: Parameter (key A, B or C, bit 7 write protects the key pages)
+    •	<Stop>
+    •	<Delay>
+    •	<Start, device address read>
+    •	RX: Length (SMBus)
+    •	RX: Result byte
+    •	<Stop>
+    */
+
+    write_buff[0] = CMD_GEN_ECDSA_KEY;
+    write_buff[1] = 1;
+    write_buff[2] = (key & 0x03) | (lock ? 0x80 : 0x00);
+    int out_count = write (i2c_fd, write_buff, 3);
 
+    usleep(200000); //critical value, key generation is slow
 
+    int redoutCount = read(i2c_fd, read_buff, 2); //len + res_code
+    return read_buff[1]; //Result byte
+}
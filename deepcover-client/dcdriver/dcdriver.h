@@ -20,10 +20,12 @@ unsigned char * getBufferData(int len, int skip_header);
 int writePageData(int page, unsigned char * data);
 int writeBufferData(int len, unsigned char * data);
 int getPageProtection(int page);
+int setPageProtection(int page, int protection); //return result byte
 
 //high-level functions
 unsigned char * getDeepCoverID (int verbose); //return 8 bytes
 unsigned char * computeReadPageAuthentication(unsigned char * data32, int skip_header); //return 66 bytes (len+rcode+sigs32+sigr32) with header
+int generateECDSAKeyPair(int key, int lock); //key 0=A 1=B 2=C, return result byte
 
 //helper
 char * hexStr2(unsigned char * data, int len);
@@ -0,0 +1,6 @@
+//go:build linux && arm && cgo
+// +build linux,arm,cgo
+
+// dcdriver is compiled together with the package so the Go build always
+// matches dcdriver.h, libdcdriver.a is kept for C programs
+#include "dcdriver/dcdriver.c"
@@ -1,3 +1,6 @@
+//go:build linux && arm && cgo
+// +build linux,arm,cgo
+
 package deepcoverclient
 
 /*
@@ -10,11 +13,12 @@ package deepcoverclient
 
 	full command example
 	CC=/opt/beyond/rpi-toolchain/arm-bcm2708/gcc-linaro-arm-linux-gnueabihf-raspbian-x64/bin/arm-linux-gnueabihf-gcc CGO_ENABLED=1 GOARCH=arm GOARM=7 GOOS=linux go build -v -x main.go
+
+	dcdriver.c is compiled with the package, see dcdriver_linux_arm.c
 */
 
 /*
 #cgo CFLAGS: -I/opt/beyond/rpi-toolchain/arm-bcm2708/arm-linux-gnueabihf/arm-linux-gnueabihf/sysroot/usr/include/
-#cgo LDFLAGS: -L/home/developer/go/src/github.com/vincepg13/bp-sdk/beyond/vendor/github.com/vincepg13/bp-sdk/deepcover-client/dcdriver/ -ldcdriver
 
 #include "dcdriver/dcdriver.h"
 #include <stdlib.h>
@@ -23,16 +27,21 @@ import "C"
 
 import (
 	"crypto/ecdsa"
-	"crypto/elliptic"
 	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
-	"math/big"
+	"sync"
 	"unsafe"
 )
 
+// busMu serialises every call into dcdriver. The driver keeps its replies in
+// static buffers and the I2C bus is shared, so the returned memory is only
+// valid until the next call.
+var busMu sync.Mutex
+
 // GoGetDcID gets the DeepCover ID (romID)
 func GoGetDcID(pg int) []byte {
+	busMu.Lock()
+	defer busMu.Unlock()
 	src := C.GoBytes(unsafe.Pointer(C.getDeepCoverID(C.int(pg))), 8)
 	fmt.Println(" DeepCoverID: ", Bytes2HexString(src))
 	return src
@@ -45,6 +54,8 @@ func GetDcID() []byte {
 
 // GoGetPageData gets the specified by index page data
 func GoGetPageData(pg int, skiphdr int) []byte {
+	busMu.Lock()
+	defer busMu.Unlock()
 	src := C.GoBytes(unsafe.Pointer(C.getPageData(C.int(pg), C.int(skiphdr))), 32)
 	return src
 }
@@ -70,6 +81,8 @@ func GetPubKeyA() []byte {
 
 // GoCalcSignature calculates the signature of the input data using DeepCover chip
 func GoCalcSignature(indata []byte) []byte {
+	busMu.Lock()
+	defer busMu.Unlock()
 	cdata := C.CBytes(indata)
 	cptrInData := (*C.uchar)(cdata)
 	//C.computeReadPageAuthentication(cptrInData, C.int(1))
@@ -89,58 +102,107 @@ func SignData(indata []byte) ([]byte, error) {
 	return retVal, nil
 }
 
-// GetPubKey returns publick key
-func GetPubKey() *ecdsa.PublicKey {
-	pubKey := byteToPublicKey(GetPubKeyAX(), GetPubKeyAY())
+// hardwareBackend talks to the DS28C36 through dcdriver on the I2C bus
+type hardwareBackend struct{}
 
-	return pubKey
+// NewHardwareBackend returns a Backend for the secure element on the I2C bus
+func NewHardwareBackend() Backend {
+	return hardwareBackend{}
 }
 
-func hexToPrivateKey(privKeyHex string) *ecdsa.PrivateKey {
-	bytes, err := hex.DecodeString(privKeyHex)
-	print(err)
-
-	return byteToPrivateKey(bytes)
+func (hardwareBackend) ReadPage(page int) ([]byte, error) {
+	busMu.Lock()
+	defer busMu.Unlock()
+	// reply: length, result byte, 32 bytes of page data
+	reply := C.GoBytes(unsafe.Pointer(C.getPageData(C.int(page), C.int(0))), 2+PageSize)
+	if err := resultError(reply[1]); err != nil {
+		return nil, err
+	}
+	return reply[2:], nil
 }
 
-func byteToPrivateKey(privKey []byte) *ecdsa.PrivateKey {
-	k := new(big.Int)
-	k.SetBytes(privKey)
+// ComputeSignature only supports page 0, computeReadPageAuthentication in
+// dcdriver always authenticates it
+func (hardwareBackend) ComputeSignature(page int, challenge []byte) ([]byte, error) {
+	if page != 0 {
+		return nil, ErrParameter
+	}
+	busMu.Lock()
+	defer busMu.Unlock()
+	// writeBufferData copies len+1 bytes, pad the challenge accordingly
+	cdata := C.CBytes(append(append([]byte(nil), challenge...), 0))
+	defer C.free(cdata)
+	// reply: length, result byte, S and R of 32 bytes each, see dcdriver.h
+	reply := C.GoBytes(unsafe.Pointer(C.computeReadPageAuthentication((*C.uchar)(cdata), C.int(0))), 2+64)
+	if err := resultError(reply[1]); err != nil {
+		return nil, err
+	}
+	return append(append([]byte(nil), reply[34:66]...), reply[2:34]...), nil
+}
 
-	priv := new(ecdsa.PrivateKey)
-	curve := elliptic.P256()
-	priv.PublicKey.Curve = curve
-	priv.D = k
-	priv.PublicKey.X, priv.PublicKey.Y = curve.ScalarBaseMult(k.Bytes())
-	fmt.Printf("Calculated PubKey from PrivKey-->X: %d, Y: %d", priv.PublicKey.X, priv.PublicKey.Y)
-	fmt.Printf("\n\n")
+var _ Provisioner = hardwareBackend{}
 
-	return priv
+func (hardwareBackend) GenerateKeyA(lock bool) error {
+	busMu.Lock()
+	defer busMu.Unlock()
+	var lk C.int
+	if lock {
+		lk = 1
+	}
+	return resultError(byte(C.generateECDSAKeyPair(C.int(0), lk)))
 }
 
-func hexToPublicKey(pubKeyXpart string, pubKeyYpart string) *ecdsa.PublicKey {
-	xBytes, _ := hex.DecodeString(pubKeyXpart)
-	yBytes, _ := hex.DecodeString(pubKeyYpart)
-
-	return byteToPublicKey(xBytes, yBytes)
+func (hardwareBackend) WritePage(page int, data []byte) error {
+	busMu.Lock()
+	defer busMu.Unlock()
+	// writePageData copies 33 bytes, pad the page accordingly
+	cdata := C.CBytes(append(append([]byte(nil), data...), 0))
+	defer C.free(cdata)
+	return resultError(byte(C.writePageData(C.int(page), (*C.uchar)(cdata))))
 }
 
-func byteToPublicKey(pubKeyXpart []byte, pubKeyYpart []byte) *ecdsa.PublicKey {
-	x := new(big.Int)
-	x.SetBytes(pubKeyXpart)
+func (hardwareBackend) SetPageProtection(page int, protection byte) error {
+	busMu.Lock()
+	defer busMu.Unlock()
+	return resultError(byte(C.setPageProtection(C.int(page), C.int(protection))))
+}
 
-	y := new(big.Int)
-	y.SetBytes(pubKeyYpart)
+func (hardwareBackend) PageProtection(page int) (byte, error) {
+	busMu.Lock()
+	defer busMu.Unlock()
+	return byte(C.getPageProtection(C.int(page))), nil
+}
 
-	pub := new(ecdsa.PublicKey)
-	pub.X = x
-	pub.Y = y
+var _ RandomSource = hardwareBackend{}
+
+func (hardwareBackend) ReadRNG(n int) ([]byte, error) {
+	busMu.Lock()
+	defer busMu.Unlock()
+	// reply: length, n random bytes
+	reply := C.GoBytes(unsafe.Pointer(C.getRNGdata(C.int(n), C.int(0))), C.int(1+n))
+	if int(reply[0]) != n {
+		return nil, ErrCommunication
+	}
+	return reply[1:], nil
+}
 
-	pub.Curve = elliptic.P256()
+var (
+	defaultSessionOnce sync.Once
+	defaultSession     *Session
+)
 
-	return pub
+// DefaultSession returns the process wide session for the secure element
+// on the I2C bus
+func DefaultSession() *Session {
+	defaultSessionOnce.Do(func() {
+		defaultSession = NewSession(NewHardwareBackend())
+	})
+	return defaultSession
 }
 
-type ecdsaSignature struct {
-	R, S *big.Int
+// GetPubKey returns publick key
+func GetPubKey() *ecdsa.PublicKey {
+	pubKey := byteToPublicKey(GetPubKeyAX(), GetPubKeyAY())
+
+	return pubKey
 }
@@ -0,0 +1,64 @@
+package deepcoverclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// ErasedPage is the content of a memory page that was never written
+var ErasedPage = bytes.Repeat([]byte{0xFF}, PageSize)
+
+// DigestLayout holds the device data the DS28C36 hashes together with the
+// challenge when it signs, see CalcucateMessageDigest
+type DigestLayout struct {
+	RomID    []byte // 8 bytes
+	ManID    []byte // 2 bytes
+	Page     int    // page whose content is signed
+	PageData []byte // 32 bytes, content of Page
+}
+
+// DefaultDigestLayout is the layout of a device signing with an erased
+// page 0, as the chips shipped by dcdriver are provisioned
+func DefaultDigestLayout(romID []byte, manID []byte) DigestLayout {
+	return DigestLayout{RomID: romID, ManID: manID, Page: 0, PageData: ErasedPage}
+}
+
+// Validate checks the length of every field and the page number
+func (l DigestLayout) Validate() error {
+	if len(l.RomID) != 8 {
+		return ErrInvalidROMID
+	}
+	if len(l.ManID) != 2 {
+		return fmt.Errorf("deepcover: manufacturer ID must be 2 bytes, got %d", len(l.ManID))
+	}
+	if l.Page < 0 || l.Page > 31 {
+		return fmt.Errorf("deepcover: invalid page %d", l.Page)
+	}
+	if len(l.PageData) != PageSize {
+		return fmt.Errorf("deepcover: page data must be %d bytes, got %d", PageSize, len(l.PageData))
+	}
+	return nil
+}
+
+// DigestLayout reads the layout of the device signing with the given page
+func (s *Session) DigestLayout(ctx context.Context, page int) (DigestLayout, error) {
+	rom, err := s.ReadPage(ctx, PageROMOptions)
+	if err != nil {
+		return DigestLayout{}, err
+	}
+	romID := rom[romOffsetRomID : romOffsetRomID+8]
+	if err := ValidateROMID(romID); err != nil {
+		return DigestLayout{}, err
+	}
+	data, err := s.ReadPage(ctx, page)
+	if err != nil {
+		return DigestLayout{}, err
+	}
+	return DigestLayout{
+		RomID:    romID,
+		ManID:    rom[romOffsetManID : romOffsetManID+2],
+		Page:     page,
+		PageData: data,
+	}, nil
+}
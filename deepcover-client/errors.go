@@ -0,0 +1,55 @@
+package deepcoverclient
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DS28C36 result bytes, see dcdriver.c
+const (
+	resultSuccess         = 0xAA
+	resultFailProtection  = 0x55
+	resultFailParameter   = 0x77
+	resultFailSequence    = 0x33
+	resultFailVerify      = 0x00
+	resultFailECDSA       = 0x22
+	resultFailCommunicate = 0x11
+)
+
+// Errors reported by the secure element or the session manager
+var (
+	ErrCommunication   = errors.New("deepcover: communication failure")
+	ErrProtection      = errors.New("deepcover: page protection violation")
+	ErrParameter       = errors.New("deepcover: invalid parameter")
+	ErrInvalidSequence = errors.New("deepcover: invalid command sequence")
+	ErrVerify          = errors.New("deepcover: verification failed")
+	ErrECDSA           = errors.New("deepcover: ECDSA operation failed")
+	ErrInvalidROMID    = errors.New("deepcover: invalid ROM ID")
+)
+
+// resultError maps a DS28C36 result byte to an error, nil on success
+func resultError(code byte) error {
+	switch code {
+	case resultSuccess:
+		return nil
+	case resultFailProtection:
+		return ErrProtection
+	case resultFailParameter:
+		return ErrParameter
+	case resultFailSequence:
+		return ErrInvalidSequence
+	case resultFailVerify:
+		return ErrVerify
+	case resultFailECDSA:
+		return ErrECDSA
+	case resultFailCommunicate:
+		return ErrCommunication
+	default:
+		return fmt.Errorf("deepcover: unexpected result byte 0x%02X", code)
+	}
+}
+
+// IsTransient reports whether the error is a bus failure worth retrying
+func IsTransient(err error) bool {
+	return err == ErrCommunication
+}
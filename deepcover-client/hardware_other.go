@@ -0,0 +1,32 @@
+//go:build !linux || !arm || !cgo
+// +build !linux !arm !cgo
+
+package deepcoverclient
+
+import "errors"
+
+// ErrNoDevice is returned on platforms without the dcdriver I2C driver
+var ErrNoDevice = errors.New("deepcover: no secure element on this platform")
+
+// noDevice is the hardware backend on platforms dcdriver is not built for.
+// libdcdriver is compiled for the Raspberry Pi only, see deepcover_client.go
+type noDevice struct{}
+
+// NewHardwareBackend returns a Backend for the secure element on the I2C bus
+func NewHardwareBackend() Backend {
+	return noDevice{}
+}
+
+func (noDevice) ReadPage(page int) ([]byte, error)                           { return nil, ErrNoDevice }
+func (noDevice) ComputeSignature(page int, challenge []byte) ([]byte, error) { return nil, ErrNoDevice }
+func (noDevice) GenerateKeyA(lock bool) error                                { return ErrNoDevice }
+func (noDevice) WritePage(page int, data []byte) error                       { return ErrNoDevice }
+func (noDevice) SetPageProtection(page int, protection byte) error           { return ErrNoDevice }
+func (noDevice) PageProtection(page int) (byte, error)                       { return 0, ErrNoDevice }
+func (noDevice) ReadRNG(n int) ([]byte, error)                               { return nil, ErrNoDevice }
+
+// DefaultSession returns the process wide session for the secure element
+// on the I2C bus
+func DefaultSession() *Session {
+	return NewSession(NewHardwareBackend())
+}
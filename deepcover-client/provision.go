@@ -0,0 +1,109 @@
+package deepcoverclient
+
+import (
+	"context"
+	"errors"
+)
+
+// Page protection bits, see getPageProtection in dcdriver.c
+const (
+	ProtRP   = 0x01 // read protection
+	ProtWP   = 0x02 // write protection
+	ProtEM   = 0x04 // EPROM emulation mode
+	ProtAPH  = 0x08 // authenticated write protection HMAC
+	ProtEPH  = 0x10 // encryption and authenticated write protection HMAC
+	ProtAUTH = 0x20 // designated authority public key
+	ProtECH  = 0x40 // encrypted read and write with the ECDH shared key
+	ProtECW  = 0x80 // authenticated write protection ECDSA
+)
+
+// PagePrivKeyA holds Private Key A, it is read protected
+const PagePrivKeyA = 22
+
+// ErrNotSupported is returned when the backend of a session can not
+// provision the secure element
+var ErrNotSupported = errors.New("deepcover: operation not supported by the backend")
+
+// Provisioner is implemented by backends able to set up a blank secure
+// element
+type Provisioner interface {
+	// GenerateKeyA generates Key A on the device, write protecting the key
+	// pages when lock is set
+	GenerateKeyA(lock bool) error
+	// WritePage stores 32 bytes in the given memory page
+	WritePage(page int, data []byte) error
+	// SetPageProtection adds the given protection bits to a page, they can
+	// not be removed again
+	SetPageProtection(page int, protection byte) error
+	// PageProtection returns the protection bits of a page
+	PageProtection(page int) (byte, error)
+}
+
+func (s *Session) provisioner() (Provisioner, error) {
+	p, ok := s.backend.(Provisioner)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return p, nil
+}
+
+// GenerateKeyA generates Key A on the device
+func (s *Session) GenerateKeyA(ctx context.Context, lock bool) error {
+	p, err := s.provisioner()
+	if err != nil {
+		return err
+	}
+	_, err = s.do(ctx, false, func() ([]byte, error) {
+		return nil, p.GenerateKeyA(lock)
+	})
+	return err
+}
+
+// WritePage stores 32 bytes in the given memory page
+func (s *Session) WritePage(ctx context.Context, page int, data []byte) error {
+	if page < 0 || page > 31 || len(data) != PageSize {
+		return ErrParameter
+	}
+	p, err := s.provisioner()
+	if err != nil {
+		return err
+	}
+	_, err = s.do(ctx, false, func() ([]byte, error) {
+		return nil, p.WritePage(page, data)
+	})
+	return err
+}
+
+// SetPageProtection adds the given protection bits to a page
+func (s *Session) SetPageProtection(ctx context.Context, page int, protection byte) error {
+	if page < 0 || page > 31 {
+		return ErrParameter
+	}
+	p, err := s.provisioner()
+	if err != nil {
+		return err
+	}
+	_, err = s.do(ctx, false, func() ([]byte, error) {
+		return nil, p.SetPageProtection(page, protection)
+	})
+	return err
+}
+
+// PageProtection returns the protection bits of a page
+func (s *Session) PageProtection(ctx context.Context, page int) (byte, error) {
+	if page < 0 || page > 31 {
+		return 0, ErrParameter
+	}
+	p, err := s.provisioner()
+	if err != nil {
+		return 0, err
+	}
+	prot, err := s.do(ctx, true, func() ([]byte, error) {
+		prot, err := p.PageProtection(page)
+		return []byte{prot}, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return prot[0], nil
+}
@@ -0,0 +1,30 @@
+package deepcoverclient
+
+import "context"
+
+// MaxRandomBytes is the most random bytes the DS28C36 returns per request
+const MaxRandomBytes = 64
+
+// RandomSource is implemented by backends with access to the hardware RNG
+type RandomSource interface {
+	// ReadRNG returns n bytes from the random number generator
+	ReadRNG(n int) ([]byte, error)
+}
+
+// RandomBytes returns n bytes from the hardware RNG of the device
+func (s *Session) RandomBytes(ctx context.Context, n int) ([]byte, error) {
+	if n <= 0 || n > MaxRandomBytes {
+		return nil, ErrParameter
+	}
+	rng, ok := s.backend.(RandomSource)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return s.do(ctx, true, func() ([]byte, error) {
+		data, err := rng.ReadRNG(n)
+		if err == nil && len(data) != n {
+			err = ErrCommunication
+		}
+		return data, err
+	})
+}
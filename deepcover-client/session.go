@@ -0,0 +1,210 @@
+package deepcoverclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"time"
+)
+
+// Memory pages used by the session manager, see the DS28C36 memory setup
+// in dcdriver.c
+const (
+	PagePubKeyAX   = 16
+	PagePubKeyAY   = 17
+	PageROMOptions = 28
+
+	PageSize = 32
+
+	// offsets into the ROM options page
+	romOffsetManID = 22
+	romOffsetRomID = 24
+)
+
+// Backend gives raw access to a single DS28C36 secure element. Backends
+// are not required to be safe for concurrent use, the Session serialises
+// all calls made through it.
+type Backend interface {
+	// ReadPage returns the 32 bytes stored in the given memory page
+	ReadPage(page int) ([]byte, error)
+	// ComputeSignature signs a 32 byte challenge together with the content
+	// of the given page with Private Key A and returns the 64 byte
+	// signature (R followed by S)
+	ComputeSignature(page int, challenge []byte) ([]byte, error)
+}
+
+// Session queues requests to a secure element so that several goroutines
+// can share one device. Every result is copied out before the next request
+// is let onto the bus and requests honour context deadlines while queued.
+// Reads and signatures failing with a transient communication error are
+// retried, writes to the device never are.
+type Session struct {
+	backend    Backend
+	slot       chan struct{}
+	retries    int
+	retryDelay time.Duration
+}
+
+// SessionOption configures a Session
+type SessionOption func(*Session)
+
+// WithRetries sets how many times a read or signature failing with a
+// transient error is retried and how long to wait between attempts
+func WithRetries(retries int, delay time.Duration) SessionOption {
+	return func(s *Session) {
+		s.retries = retries
+		s.retryDelay = delay
+	}
+}
+
+// NewSession returns a session manager for the given backend
+func NewSession(backend Backend, opts ...SessionOption) *Session {
+	s := &Session{
+		backend:    backend,
+		slot:       make(chan struct{}, 1),
+		retries:    3,
+		retryDelay: 10 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+type result struct {
+	data []byte
+	err  error
+}
+
+// do waits for the bus, runs fn and returns a private copy of its result.
+// Transient failures are only retried if retry is set, fn must then be safe
+// to repeat. A request abandoned because of the context keeps the bus
+// until the device has answered, so the next caller never reads a reply
+// meant for someone else.
+func (s *Session) do(ctx context.Context, retry bool, fn func() ([]byte, error)) ([]byte, error) {
+	select {
+	case s.slot <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		defer func() { <-s.slot }()
+
+		var res result
+		for attempt := 0; ; attempt++ {
+			res.data, res.err = fn()
+			if res.err == nil || !retry || !IsTransient(res.err) || attempt >= s.retries {
+				break
+			}
+			select {
+			case <-time.After(s.retryDelay):
+			case <-ctx.Done():
+				done <- result{err: ctx.Err()}
+				return
+			}
+		}
+		if res.err == nil {
+			res.data = append([]byte(nil), res.data...)
+		}
+		done <- res
+	}()
+
+	select {
+	case res := <-done:
+		return res.data, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// ReadPage returns the content of the given memory page
+func (s *Session) ReadPage(ctx context.Context, page int) ([]byte, error) {
+	if page < 0 || page > 31 {
+		return nil, ErrParameter
+	}
+	return s.do(ctx, true, func() ([]byte, error) {
+		data, err := s.backend.ReadPage(page)
+		if err == nil && len(data) != PageSize {
+			err = ErrCommunication
+		}
+		return data, err
+	})
+}
+
+// RomID returns the 8 byte ROM ID after validating its CRC and family code
+func (s *Session) RomID(ctx context.Context) ([]byte, error) {
+	page, err := s.ReadPage(ctx, PageROMOptions)
+	if err != nil {
+		return nil, err
+	}
+	romID := page[romOffsetRomID : romOffsetRomID+8]
+	if err := ValidateROMID(romID); err != nil {
+		return nil, err
+	}
+	return romID, nil
+}
+
+// PubKeyA returns PubKeyAX followed by PubKeyAY
+func (s *Session) PubKeyA(ctx context.Context) ([]byte, error) {
+	x, err := s.ReadPage(ctx, PagePubKeyAX)
+	if err != nil {
+		return nil, err
+	}
+	y, err := s.ReadPage(ctx, PagePubKeyAY)
+	if err != nil {
+		return nil, err
+	}
+	return append(x, y...), nil
+}
+
+// PublicKey returns PubKeyA as an ECDSA public key
+func (s *Session) PublicKey(ctx context.Context) (*ecdsa.PublicKey, error) {
+	pub, err := s.PubKeyA(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return byteToPublicKey(pub[:PageSize], pub[PageSize:]), nil
+}
+
+// ComputeSignature signs a 32 byte challenge on the device with page 0
+func (s *Session) ComputeSignature(ctx context.Context, challenge []byte) ([]byte, error) {
+	return s.ComputePageSignature(ctx, 0, challenge)
+}
+
+// ComputePageSignature signs a 32 byte challenge on the device together
+// with the content of the given page. The signature is returned as raw
+// R||S in low-S form.
+func (s *Session) ComputePageSignature(ctx context.Context, page int, challenge []byte) ([]byte, error) {
+	if len(challenge) != 32 || page < 0 || page > 31 {
+		return nil, ErrParameter
+	}
+	return s.do(ctx, true, func() ([]byte, error) {
+		sig, err := s.backend.ComputeSignature(page, challenge)
+		if err != nil {
+			return nil, err
+		}
+		if len(sig) != SignatureSize {
+			return nil, ErrCommunication
+		}
+		// the chip does not normalise S, see IsLowS
+		if sig, err = NormalizeSignature(sig); err != nil {
+			return nil, ErrECDSA
+		}
+		return sig, nil
+	})
+}
+
+// SignData compresses the input data to SHA256 and signs it on the device
+// with page 0
+func (s *Session) SignData(ctx context.Context, indata []byte) ([]byte, error) {
+	return s.SignPageData(ctx, 0, indata)
+}
+
+// SignPageData compresses the input data to SHA256 and signs it on the
+// device with the given page
+func (s *Session) SignPageData(ctx context.Context, page int, indata []byte) ([]byte, error) {
+	sha256cs := sha256.Sum256(indata)
+	return s.ComputePageSignature(ctx, page, sha256cs[:])
+}
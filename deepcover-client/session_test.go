@@ -0,0 +1,256 @@
+package deepcoverclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newSoftwareBackend(t *testing.T) *SoftwareBackend {
+	romID, err := hex.DecodeString("4c123456789a0053")
+	require.Nil(t, err)
+	b, err := NewSoftwareBackend(nil, romID, []byte{0x12, 0x34})
+	require.Nil(t, err)
+	return b
+}
+
+// flakyBackend fails the first calls of each operation with a transient
+// error and counts the attempts
+type flakyBackend struct {
+	*SoftwareBackend
+	fails    int
+	attempts map[string]int
+	inFlight int32
+	maxSeen  int32
+}
+
+func newFlakyBackend(t *testing.T, fails int) *flakyBackend {
+	return &flakyBackend{SoftwareBackend: newSoftwareBackend(t), fails: fails, attempts: map[string]int{}}
+}
+
+func (b *flakyBackend) call(op string) error {
+	n := atomic.AddInt32(&b.inFlight, 1)
+	defer atomic.AddInt32(&b.inFlight, -1)
+	if n > atomic.LoadInt32(&b.maxSeen) {
+		atomic.StoreInt32(&b.maxSeen, n)
+	}
+	time.Sleep(time.Millisecond)
+
+	b.attempts[op]++
+	if b.attempts[op] <= b.fails {
+		return ErrCommunication
+	}
+	return nil
+}
+
+func (b *flakyBackend) ReadPage(page int) ([]byte, error) {
+	if err := b.call("read"); err != nil {
+		return nil, err
+	}
+	return b.SoftwareBackend.ReadPage(page)
+}
+
+func (b *flakyBackend) ComputeSignature(page int, challenge []byte) ([]byte, error) {
+	if err := b.call("sign"); err != nil {
+		return nil, err
+	}
+	return b.SoftwareBackend.ComputeSignature(page, challenge)
+}
+
+func (b *flakyBackend) GenerateKeyA(lock bool) error {
+	if err := b.call("generate"); err != nil {
+		return err
+	}
+	return b.SoftwareBackend.GenerateKeyA(lock)
+}
+
+func (b *flakyBackend) WritePage(page int, data []byte) error {
+	if err := b.call("write"); err != nil {
+		return err
+	}
+	return b.SoftwareBackend.WritePage(page, data)
+}
+
+func (b *flakyBackend) SetPageProtection(page int, protection byte) error {
+	if err := b.call("protect"); err != nil {
+		return err
+	}
+	return b.SoftwareBackend.SetPageProtection(page, protection)
+}
+
+func TestSessionSignature(t *testing.T) {
+	ctx := context.Background()
+	s := NewSession(newSoftwareBackend(t))
+	challenge := make([]byte, 32)
+
+	layout, err := s.DigestLayout(ctx, 0)
+	require.Nil(t, err)
+	digest, err := CalcucateMessageDigest(challenge, layout)
+	require.Nil(t, err)
+	pub, err := s.PublicKey(ctx)
+	require.Nil(t, err)
+
+	// the software backend does not normalise S, the session does
+	for i := 0; i < 16; i++ {
+		sig, err := s.ComputeSignature(ctx, challenge)
+		require.Nil(t, err)
+		require.True(t, IsLowS(sig))
+		require.True(t, ecdsa.Verify(pub, digest, new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])))
+	}
+
+	_, err = s.ComputePageSignature(ctx, 0, challenge[:31])
+	require.Equal(t, ErrParameter, err)
+	_, err = s.ComputePageSignature(ctx, 32, challenge)
+	require.Equal(t, ErrParameter, err)
+}
+
+func TestSessionSerialization(t *testing.T) {
+	b := newFlakyBackend(t, 0)
+	s := NewSession(b)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			if i%2 == 0 {
+				_, err = s.ReadPage(context.Background(), PagePubKeyAX)
+			} else {
+				_, err = s.SignData(context.Background(), []byte{byte(i)})
+			}
+			require.Nil(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), b.maxSeen)
+	require.Equal(t, 8, b.attempts["read"])
+	require.Equal(t, 8, b.attempts["sign"])
+}
+
+// blockingBackend holds the bus until it is released
+type blockingBackend struct {
+	*SoftwareBackend
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingBackend) ReadPage(page int) ([]byte, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return b.SoftwareBackend.ReadPage(page)
+}
+
+func TestSessionContextWhileQueued(t *testing.T) {
+	b := &blockingBackend{
+		SoftwareBackend: newSoftwareBackend(t),
+		started:         make(chan struct{}, 1),
+		release:         make(chan struct{}),
+	}
+	s := NewSession(b)
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := s.ReadPage(context.Background(), PagePubKeyAX)
+		first <- err
+	}()
+	<-b.started
+
+	// the bus is busy, the second request gives up at its deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := s.ReadPage(ctx, PagePubKeyAY)
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	// a cancelled request does not wait for the bus
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = s.ReadPage(ctx, PagePubKeyAY)
+	require.Equal(t, context.Canceled, err)
+
+	close(b.release)
+	require.Nil(t, <-first)
+	data, err := s.ReadPage(context.Background(), PagePubKeyAY)
+	require.Nil(t, err)
+	require.Len(t, data, PageSize)
+}
+
+func TestSessionRetries(t *testing.T) {
+	ctx := context.Background()
+
+	// reads and signatures are retried
+	b := newFlakyBackend(t, 2)
+	s := NewSession(b, WithRetries(2, 0))
+	_, err := s.ReadPage(ctx, PagePubKeyAX)
+	require.Nil(t, err)
+	require.Equal(t, 3, b.attempts["read"])
+	_, err = s.ComputeSignature(ctx, make([]byte, 32))
+	require.Nil(t, err)
+	require.Equal(t, 3, b.attempts["sign"])
+
+	b = newFlakyBackend(t, 3)
+	s = NewSession(b, WithRetries(2, 0))
+	_, err = s.ReadPage(ctx, PagePubKeyAX)
+	require.Equal(t, ErrCommunication, err)
+	require.Equal(t, 3, b.attempts["read"])
+
+	// writes to the device are attempted once
+	b = newFlakyBackend(t, 1)
+	s = NewSession(b, WithRetries(2, 0))
+	require.Equal(t, ErrCommunication, s.GenerateKeyA(ctx, false))
+	require.Equal(t, 1, b.attempts["generate"])
+	require.Equal(t, ErrCommunication, s.WritePage(ctx, 0, make([]byte, PageSize)))
+	require.Equal(t, 1, b.attempts["write"])
+	require.Equal(t, ErrCommunication, s.SetPageProtection(ctx, 0, ProtWP))
+	require.Equal(t, 1, b.attempts["protect"])
+
+	// errors reported by the device are not retried
+	b = newFlakyBackend(t, 0)
+	s = NewSession(b, WithRetries(2, 0))
+	require.Nil(t, s.SetPageProtection(ctx, 1, ProtRP))
+	_, err = s.ReadPage(ctx, 1)
+	require.Equal(t, ErrProtection, err)
+	require.Equal(t, 1, b.attempts["read"])
+}
+
+// sharedBackend returns its internal buffer, like a driver reusing a static
+// reply buffer
+type sharedBackend struct {
+	buf []byte
+}
+
+func (b *sharedBackend) ReadPage(page int) ([]byte, error) {
+	for i := range b.buf {
+		b.buf[i] = byte(page)
+	}
+	return b.buf, nil
+}
+
+func (b *sharedBackend) ComputeSignature(page int, challenge []byte) ([]byte, error) {
+	return nil, ErrNotSupported
+}
+
+func TestSessionCopiesResult(t *testing.T) {
+	ctx := context.Background()
+	b := &sharedBackend{buf: make([]byte, PageSize)}
+	s := NewSession(b)
+
+	x, err := s.ReadPage(ctx, PagePubKeyAX)
+	require.Nil(t, err)
+	y, err := s.ReadPage(ctx, PagePubKeyAY)
+	require.Nil(t, err)
+	b.buf[0] = 0xff
+
+	require.Equal(t, byte(PagePubKeyAX), x[0])
+	require.Equal(t, byte(PagePubKeyAY), y[0])
+	x[1] = 0xff
+	require.Equal(t, byte(PagePubKeyAY), b.buf[1])
+}
@@ -0,0 +1,112 @@
+package deepcoverclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+)
+
+// SignatureSize is the size of the signature output by the DS28C36, R
+// followed by S
+const SignatureSize = 64
+
+// ErrInvalidSignature is returned for a signature that is malformed, out of
+// range or not in low-S form
+var ErrInvalidSignature = errors.New("deepcover: invalid signature")
+
+var (
+	curveOrder     = elliptic.P256().Params().N
+	curveHalfOrder = new(big.Int).Rsh(curveOrder, 1)
+)
+
+// ParseSignature splits a raw R||S signature, both values must lie in
+// [1, N-1]
+func ParseSignature(sig []byte) (r, s *big.Int, err error) {
+	if len(sig) != SignatureSize {
+		return nil, nil, ErrInvalidSignature
+	}
+	r = new(big.Int).SetBytes(sig[:32])
+	s = new(big.Int).SetBytes(sig[32:])
+	if !inRange(r) || !inRange(s) {
+		return nil, nil, ErrInvalidSignature
+	}
+	return r, s, nil
+}
+
+// RawSignature encodes r and s as the 64 byte R||S signature
+func RawSignature(r, s *big.Int) []byte {
+	sig := make([]byte, SignatureSize)
+	putInt(sig[:32], r)
+	putInt(sig[32:], s)
+	return sig
+}
+
+// IsLowS reports whether S is at most N/2. Only low-S signatures are
+// accepted on chain, (r, N-s) is an equally valid signature of the same
+// digest.
+func IsLowS(sig []byte) bool {
+	_, s, err := ParseSignature(sig)
+	return err == nil && s.Cmp(curveHalfOrder) <= 0
+}
+
+// NormalizeSignature returns the low-S form of a raw R||S signature
+func NormalizeSignature(sig []byte) ([]byte, error) {
+	r, s, err := ParseSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+	if s.Cmp(curveHalfOrder) > 0 {
+		s.Sub(curveOrder, s)
+	}
+	return RawSignature(r, s), nil
+}
+
+// SignatureToDER encodes a raw R||S signature as ASN.1 DER, the format of
+// OpenSSL and crypto/x509
+func SignatureToDER(sig []byte) ([]byte, error) {
+	r, s, err := ParseSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(ecdsaSignature{R: r, S: s})
+}
+
+// SignatureFromDER decodes an ASN.1 DER signature to raw R||S
+func SignatureFromDER(der []byte) ([]byte, error) {
+	var sig ecdsaSignature
+	rest, err := asn1.Unmarshal(der, &sig)
+	if err != nil || len(rest) != 0 || !inRange(sig.R) || !inRange(sig.S) {
+		return nil, ErrInvalidSignature
+	}
+	return RawSignature(sig.R, sig.S), nil
+}
+
+// TxSignature converts the chip output, raw R||S, or an ASN.1 DER signature
+// to the bytes carried in tendermint transactions and attestations: 64
+// bytes R||S with low S
+func TxSignature(sig []byte) ([]byte, error) {
+	if len(sig) != SignatureSize {
+		var err error
+		if sig, err = SignatureFromDER(sig); err != nil {
+			return nil, err
+		}
+	}
+	return NormalizeSignature(sig)
+}
+
+// VerifySignature verifies a raw R||S signature over the message digest.
+// Signatures out of range or not in low-S form are rejected, so a signature
+// can not be replayed in its malleated form.
+func VerifySignature(pub *ecdsa.PublicKey, digest []byte, sig []byte) bool {
+	r, s, err := ParseSignature(sig)
+	if err != nil || s.Cmp(curveHalfOrder) > 0 {
+		return false
+	}
+	return ecdsa.Verify(pub, digest, r, s)
+}
+
+func inRange(x *big.Int) bool {
+	return x != nil && x.Sign() > 0 && x.Cmp(curveOrder) < 0
+}
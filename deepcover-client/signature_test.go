@@ -0,0 +1,152 @@
+package deepcoverclient
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// signHighS signs digest and returns the signature in low-S and high-S form
+func signHighS(t *testing.T, key *ecdsa.PrivateKey, digest []byte) (lowS, highS []byte) {
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+	require.Nil(t, err)
+	if s.Cmp(curveHalfOrder) > 0 {
+		s.Sub(curveOrder, s)
+	}
+	return RawSignature(r, s), RawSignature(r, new(big.Int).Sub(curveOrder, s))
+}
+
+func TestParseSignature(t *testing.T) {
+	one := big.NewInt(1)
+	maxValue := new(big.Int).Sub(curveOrder, one)
+
+	r, s, err := ParseSignature(RawSignature(one, maxValue))
+	require.Nil(t, err)
+	require.Equal(t, one, r)
+	require.Equal(t, maxValue, s)
+
+	for name, sig := range map[string][]byte{
+		"r = 0": RawSignature(big.NewInt(0), one),
+		"s = 0": RawSignature(one, big.NewInt(0)),
+		"r = N": RawSignature(curveOrder, one),
+		"s = N": RawSignature(one, curveOrder),
+		"r > N": append(bytes.Repeat([]byte{0xff}, 32), RawSignature(one, one)[32:]...),
+		"short": RawSignature(one, one)[:63],
+		"long":  append(RawSignature(one, one), 0),
+		"empty": nil,
+		"s > N": append(RawSignature(one, one)[:32], bytes.Repeat([]byte{0xff}, 32)...),
+	} {
+		_, _, err := ParseSignature(sig)
+		require.Equal(t, ErrInvalidSignature, err, name)
+		_, err = NormalizeSignature(sig)
+		require.Equal(t, ErrInvalidSignature, err, name)
+		require.False(t, IsLowS(sig), name)
+	}
+}
+
+func TestNormalizeSignature(t *testing.T) {
+	one := big.NewInt(1)
+	half := new(big.Int).Set(curveHalfOrder)
+	aboveHalf := new(big.Int).Add(curveHalfOrder, one)
+
+	// N/2 is the largest low S
+	require.True(t, IsLowS(RawSignature(one, half)))
+	require.False(t, IsLowS(RawSignature(one, aboveHalf)))
+
+	sig, err := NormalizeSignature(RawSignature(one, half))
+	require.Nil(t, err)
+	require.Equal(t, RawSignature(one, half), sig)
+
+	sig, err = NormalizeSignature(RawSignature(one, aboveHalf))
+	require.Nil(t, err)
+	require.Equal(t, RawSignature(one, new(big.Int).Sub(curveOrder, aboveHalf)), sig)
+	require.True(t, IsLowS(sig))
+}
+
+func TestVerifySignature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	digest := sha256.Sum256([]byte("challenge"))
+	lowS, highS := signHighS(t, key, digest[:])
+
+	require.True(t, VerifySignature(&key.PublicKey, digest[:], lowS))
+	// the malleated form is a valid ECDSA signature but rejected
+	r, s, err := ParseSignature(highS)
+	require.Nil(t, err)
+	require.True(t, ecdsa.Verify(&key.PublicKey, digest[:], r, s))
+	require.False(t, VerifySignature(&key.PublicKey, digest[:], highS))
+
+	normalized, err := NormalizeSignature(highS)
+	require.Nil(t, err)
+	require.Equal(t, lowS, normalized)
+
+	other := sha256.Sum256([]byte("other"))
+	require.False(t, VerifySignature(&key.PublicKey, other[:], lowS))
+	require.False(t, VerifySignature(&key.PublicKey, digest[:], lowS[:63]))
+
+	// the deprecated hex API accepts either form, as read from the chip
+	for _, sig := range [][]byte{lowS, highS} {
+		r, s, err := ParseSignature(sig)
+		require.Nil(t, err)
+		require.True(t, VerifyDeepCoverSignature(&key.PublicKey, digest[:], fmt.Sprintf("%x", r), fmt.Sprintf("%x", s)))
+	}
+	require.False(t, VerifyDeepCoverSignature(&key.PublicKey, digest[:], "0", fmt.Sprintf("%x", s)))
+	require.False(t, VerifyDeepCoverSignature(&key.PublicKey, digest[:], "not hex", fmt.Sprintf("%x", s)))
+}
+
+func TestSignatureDER(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	digest := sha256.Sum256([]byte("challenge"))
+
+	for i := 0; i < 8; i++ {
+		lowS, highS := signHighS(t, key, digest[:])
+		for _, sig := range [][]byte{lowS, highS} {
+			// R||S -> DER -> R||S
+			der, err := SignatureToDER(sig)
+			require.Nil(t, err)
+			var decoded ecdsaSignature
+			_, err = asn1.Unmarshal(der, &decoded)
+			require.Nil(t, err)
+			r, s, err := ParseSignature(sig)
+			require.Nil(t, err)
+			require.Equal(t, r, decoded.R)
+			require.Equal(t, s, decoded.S)
+			raw, err := SignatureFromDER(der)
+			require.Nil(t, err)
+			require.Equal(t, sig, raw)
+
+			// the chip output and its DER encoding give the same
+			// transaction signature
+			txSig, err := TxSignature(sig)
+			require.Nil(t, err)
+			require.Equal(t, lowS, txSig)
+			txSig, err = TxSignature(der)
+			require.Nil(t, err)
+			require.Equal(t, lowS, txSig)
+			require.True(t, VerifySignature(&key.PublicKey, digest[:], txSig))
+
+			_, err = SignatureFromDER(append(der, 0))
+			require.Equal(t, ErrInvalidSignature, err)
+			_, err = TxSignature(append(der, 0))
+			require.Equal(t, ErrInvalidSignature, err)
+		}
+	}
+
+	outOfRange, err := asn1.Marshal(ecdsaSignature{R: big.NewInt(0), S: big.NewInt(1)})
+	require.Nil(t, err)
+	for _, der := range [][]byte{outOfRange, {0x30, 0x00}, []byte("not DER"), nil} {
+		_, err := SignatureFromDER(der)
+		require.Equal(t, ErrInvalidSignature, err)
+	}
+	_, err = SignatureToDER(make([]byte, SignatureSize))
+	require.Equal(t, ErrInvalidSignature, err)
+}
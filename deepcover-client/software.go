@@ -0,0 +1,159 @@
+package deepcoverclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+)
+
+// SoftwareBackend emulates a DS28C36 in memory. It is meant for tests and
+// simulators only, Private Key A lives in process memory.
+type SoftwareBackend struct {
+	priv       *ecdsa.PrivateKey
+	romID      []byte
+	pages      [32][]byte
+	protection [32]byte
+}
+
+var (
+	_ Backend      = (*SoftwareBackend)(nil)
+	_ Provisioner  = (*SoftwareBackend)(nil)
+	_ RandomSource = (*SoftwareBackend)(nil)
+)
+
+// NewSoftwareBackend returns an emulated secure element with the given
+// Private Key A, ROM ID and manufacturer ID. A fresh key is generated when
+// priv is nil.
+func NewSoftwareBackend(priv *ecdsa.PrivateKey, romID []byte, manID []byte) (*SoftwareBackend, error) {
+	if err := ValidateROMID(romID); err != nil {
+		return nil, err
+	}
+	if len(manID) != 2 {
+		return nil, ErrParameter
+	}
+	if priv == nil {
+		var err error
+		if priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+			return nil, err
+		}
+	}
+
+	b := &SoftwareBackend{romID: append([]byte(nil), romID...)}
+	for i := range b.pages {
+		b.pages[i] = append([]byte(nil), ErasedPage...)
+	}
+	b.setKeyA(priv)
+	b.protection[PagePrivKeyA] = ProtRP
+	b.pages[PageROMOptions] = make([]byte, PageSize)
+	copy(b.pages[PageROMOptions][romOffsetManID:], manID)
+	copy(b.pages[PageROMOptions][romOffsetRomID:], romID)
+	return b, nil
+}
+
+func (b *SoftwareBackend) setKeyA(priv *ecdsa.PrivateKey) {
+	b.priv = priv
+	b.pages[PagePubKeyAX] = make([]byte, PageSize)
+	b.pages[PagePubKeyAY] = make([]byte, PageSize)
+	putInt(b.pages[PagePubKeyAX], priv.X)
+	putInt(b.pages[PagePubKeyAY], priv.Y)
+}
+
+// ReadPage returns a copy of the given memory page
+func (b *SoftwareBackend) ReadPage(page int) ([]byte, error) {
+	if page < 0 || page >= len(b.pages) {
+		return nil, ErrParameter
+	}
+	if b.protection[page]&ProtRP != 0 {
+		return nil, ErrProtection
+	}
+	return append([]byte(nil), b.pages[page]...), nil
+}
+
+// WritePage replaces the content of the given memory page
+func (b *SoftwareBackend) WritePage(page int, data []byte) error {
+	if page < 0 || page >= len(b.pages) || len(data) != PageSize {
+		return ErrParameter
+	}
+	if b.protection[page]&ProtWP != 0 {
+		return ErrProtection
+	}
+	b.pages[page] = append([]byte(nil), data...)
+	return nil
+}
+
+// GenerateKeyA replaces Key A with a fresh key pair
+func (b *SoftwareBackend) GenerateKeyA(lock bool) error {
+	for _, page := range []int{PagePubKeyAX, PagePubKeyAY, PagePrivKeyA} {
+		if b.protection[page]&ProtWP != 0 {
+			return ErrProtection
+		}
+	}
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return ErrECDSA
+	}
+	b.setKeyA(priv)
+	if lock {
+		b.protection[PagePubKeyAX] |= ProtWP
+		b.protection[PagePubKeyAY] |= ProtWP
+		b.protection[PagePrivKeyA] |= ProtWP
+	}
+	return nil
+}
+
+// SetPageProtection adds protection bits to a page
+func (b *SoftwareBackend) SetPageProtection(page int, protection byte) error {
+	if page < 0 || page >= len(b.pages) {
+		return ErrParameter
+	}
+	b.protection[page] |= protection
+	return nil
+}
+
+// PageProtection returns the protection bits of a page
+func (b *SoftwareBackend) PageProtection(page int) (byte, error) {
+	if page < 0 || page >= len(b.pages) {
+		return 0, ErrParameter
+	}
+	return b.protection[page], nil
+}
+
+// ComputeSignature signs the challenge the way computeReadPageAuthentication
+// does, over the DeepCover message digest of the given page
+func (b *SoftwareBackend) ComputeSignature(page int, challenge []byte) ([]byte, error) {
+	if page < 0 || page >= len(b.pages) {
+		return nil, ErrParameter
+	}
+	rom := b.pages[PageROMOptions]
+	digest, err := CalcucateMessageDigest(challenge, DigestLayout{
+		RomID:    b.romID,
+		ManID:    rom[romOffsetManID : romOffsetManID+2],
+		Page:     page,
+		PageData: b.pages[page],
+	})
+	if err != nil {
+		return nil, ErrParameter
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, b.priv, digest)
+	if err != nil {
+		return nil, ErrECDSA
+	}
+	// like the chip, S is not normalised
+	return RawSignature(r, s), nil
+}
+
+// ReadRNG returns n bytes from crypto/rand
+func (b *SoftwareBackend) ReadRNG(n int) ([]byte, error) {
+	data := make([]byte, n)
+	if _, err := rand.Read(data); err != nil {
+		return nil, ErrCommunication
+	}
+	return data, nil
+}
+
+// putInt writes x big endian, left padded to the length of dst
+func putInt(dst []byte, x *big.Int) {
+	b := x.Bytes()
+	copy(dst[len(dst)-len(b):], b)
+}